@@ -13,12 +13,14 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
 	"math/rand"
 	"net/http"
 	"os"
 	"os/exec"
+	"os/signal"
 	"reflect"
 	"strings"
 	"time"
@@ -50,6 +52,8 @@ var (
 	tenantID string
 	password string
 
+	messagingType string
+
 	policyID string
 
 	configConnectorFile       string
@@ -64,6 +68,16 @@ var (
 	configLdtFileBackup string
 
 	ldt bool
+
+	connectTimeout time.Duration
+
+	// ctx is canceled on interrupt, which aborts the pending requests
+	ctx context.Context
+
+	// controlService executes a systemctl command, e.g. restart, for a service
+	controlService = func(command string, service string) ([]byte, error) {
+		return exec.Command(systemctl, command, service).Output()
+	}
 )
 
 type c2eConfiguration struct {
@@ -83,6 +97,9 @@ func main() {
 	flag.StringVar(&deviceID, "deviceId", "", "Test device unique identifier, defaults to randomly generated")
 	flag.StringVar(&tenantID, "tenantId", "", "Device registry tenant unique identifier")
 	flag.StringVar(&password, "password", "123456", "Test device password")
+	flag.StringVar(&messagingType, "messagingType", "",
+		"Hono messaging type of the tenant, either amqp or kafka. "+
+			"If set, the tenant is created with it unless it already exists. Tenants are not deleted on cleanup")
 
 	flag.StringVar(&policyID, "policyId", "", "Test device's policy unique identifier")
 
@@ -108,11 +125,19 @@ func main() {
 		"Path to Local Digital Twins configuration file backup. "+
 			"If set to the empty string, backing up the Local Digital Twins configuration file will be skipped")
 
+	flag.DurationVar(&connectTimeout, "connectTimeout", 30*time.Second,
+		"Time to wait for the restarted service to report the configured device id. "+
+			"If set to zero, waiting will be skipped")
+
 	clean := flag.Bool("clean", false, "Clean up test resources")
 	flag.BoolVar(&ldt, "ldt", false, "Create local-digital-twins resources")
 
 	flag.Parse()
 
+	var stop context.CancelFunc
+	ctx, stop = signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
 	envOpts := env.Options{RequiredIfNoDef: true}
 	err := env.Parse(&cfg, envOpts)
 	if err == nil {
@@ -133,14 +158,15 @@ func main() {
 		}
 		assertFlag(tenantID, "tenant id")
 		assertFlag(policyID, "policy id")
+		assertMessagingType(messagingType)
 	} else if deviceID == "" || tenantID == "" {
-		mqttClient, err := util.NewMQTTClient(&cfg)
+		mqttClient, err := util.NewMQTTClient(ctx, &cfg)
 		if err != nil {
 			fmt.Printf("unable to open local MQTT connection to %s, error: %v\n", cfg.LocalBroker, err)
 			os.Exit(1)
 		}
 		defer mqttClient.Disconnect(uint(cfg.MQTTQuiesceMS))
-		thingConfiguration, err := util.GetThingConfiguration(&cfg, mqttClient)
+		thingConfiguration, err := util.GetThingConfiguration(ctx, &cfg, mqttClient)
 		if err != nil {
 			fmt.Printf("unable to get thing configuration from the local MQTT %s, error: %v\n", cfg.LocalBroker, err)
 			os.Exit(1)
@@ -150,9 +176,7 @@ func main() {
 	}
 
 	authID = strings.ReplaceAll(deviceID, ":", "_")
-	registryAPI := strings.TrimSuffix(c2eCfg.DeviceRegistryAPIAddress, "/") + "/v1"
-
-	resources := util.CreateDeviceResources(deviceID, tenantID, policyID, password, registryAPI,
+	resources := util.CreateDeviceResources(deviceID, tenantID, policyID, password, getRegistryAPI(),
 		c2eCfg.DeviceRegistryAPIUsername, c2eCfg.DeviceRegistryAPIPassword, &cfg)
 
 	var ok bool
@@ -206,8 +230,23 @@ func assertFlag(value string, name string) {
 	}
 }
 
+func assertMessagingType(value string) {
+	if !isValidMessagingType(value) {
+		fmt.Printf("'messaging type' must be either %s or %s, but is %s\n",
+			util.HonoMessagingAMQP, util.HonoMessagingKafka, value)
+		flag.Usage()
+		os.Exit(1)
+	}
+}
+
+// isValidMessagingType returns true for the supported messaging types and for the empty one,
+// which keeps the tenant unprovisioned
+func isValidMessagingType(value string) bool {
+	return value == "" || value == util.HonoMessagingAMQP || value == util.HonoMessagingKafka
+}
+
 func isDeviceIDPresentInRegistry(deviceResource *util.Resource) bool {
-	_, err := util.SendDeviceRegistryRequest(nil, http.MethodGet,
+	_, err := util.SendDeviceRegistryRequest(ctx, nil, http.MethodGet,
 		deviceResource.URL, c2eCfg.DeviceRegistryAPIUsername, c2eCfg.DeviceRegistryAPIPassword)
 	return err == nil
 }
@@ -229,6 +268,10 @@ func performSetUp(resources []*util.Resource) bool {
 
 	fmt.Println("performing setup...")
 
+	if !provisionTenant() {
+		return false
+	}
+
 	if configFile != "" && configFileBackup != "" {
 		fmt.Printf("saving a backup of the %s configuration file...\n", serviceName)
 		if err := util.CopyFile(configFile, configFileBackup); err != nil {
@@ -240,7 +283,7 @@ func performSetUp(resources []*util.Resource) bool {
 	}
 
 	for i, r := range resources {
-		if b, err := util.SendDeviceRegistryRequest(([]byte)(r.Body), r.Method, r.URL, r.User, r.Pass); err != nil {
+		if b, err := util.SendDeviceRegistryRequest(ctx, ([]byte)(r.Body), r.Method, r.URL, r.User, r.Pass); err != nil {
 			fmt.Printf("unable to create device at %s, error: %v\n", r.URL, err)
 
 			if b != nil {
@@ -249,7 +292,7 @@ func performSetUp(resources []*util.Resource) bool {
 			}
 
 			if i > 0 {
-				if err = util.DeleteResources(&cfg, resources[:i], deviceID, getTenantURL(),
+				if err = util.DeleteResources(ctx, &cfg, resources[:i], deviceID, getTenantURL(),
 					c2eCfg.DeviceRegistryAPIUsername, c2eCfg.DeviceRegistryAPIPassword); err != nil {
 					fmt.Printf(deleteResourcesTemplate, indent, err)
 				}
@@ -270,7 +313,7 @@ func performSetUp(resources []*util.Resource) bool {
 		}
 		if err != nil {
 			fmt.Printf("unable to write configuration file, error: %v\n", err)
-			if err = util.DeleteResources(&cfg, resources, deviceID, getTenantURL(),
+			if err = util.DeleteResources(ctx, &cfg, resources, deviceID, getTenantURL(),
 				c2eCfg.DeviceRegistryAPIUsername, c2eCfg.DeviceRegistryAPIPassword); err != nil {
 				fmt.Printf(deleteResourcesTemplate, indent, err)
 			}
@@ -281,16 +324,21 @@ func performSetUp(resources []*util.Resource) bool {
 		fmt.Printf("%s configuration file '%s' written\n", indent, configFile)
 
 		if serviceName != suiteConnectorService {
-			stdout, err := exec.Command(systemctl, stop, suiteConnectorService).Output()
+			stdout, err := controlService(stop, suiteConnectorService)
 			if stdout != nil {
 				fmt.Println(string(stdout))
 			}
 			if err != nil {
 				fmt.Printf("error stopping %s: %v", suiteConnectorService, err)
-				return false
+				ok = false
 			}
 		}
-		ok = restartService(serviceName)
+		ok = ok && restartService(serviceName) && waitForDeviceID(deviceID)
+		if !ok {
+			// The resources are created and the configuration file is overwritten, restore the previous state
+			fmt.Println("rolling back setup...")
+			performCleanUp(resources)
+		}
 	}
 
 	if ok {
@@ -313,7 +361,7 @@ func performCleanUp(resources []*util.Resource) bool {
 				configFileBackup, configFile, err)
 		} else {
 			if serviceName != suiteConnectorService {
-				stdout, err := exec.Command(systemctl, stop, serviceName).Output()
+				stdout, err := controlService(stop, serviceName)
 				if stdout != nil {
 					fmt.Println(string(stdout))
 				}
@@ -331,7 +379,7 @@ func performCleanUp(resources []*util.Resource) bool {
 	}
 	// Delete devices and things
 	fmt.Printf("performing cleanup on device id: %s\n", deviceID)
-	if err := util.DeleteResources(&cfg, resources, deviceID, getTenantURL(),
+	if err := util.DeleteResources(ctx, &cfg, resources, deviceID, getTenantURL(),
 		c2eCfg.DeviceRegistryAPIUsername, c2eCfg.DeviceRegistryAPIPassword); err != nil {
 		fmt.Printf(deleteResourcesTemplate, indent, err)
 		ok = false
@@ -339,6 +387,31 @@ func performCleanUp(resources []*util.Resource) bool {
 	return ok
 }
 
+// provisionTenant creates the tenant with the messaging type, if set. Existing tenants are not modified,
+// so the setup fails for an existing tenant with another explicit messaging type.
+func provisionTenant() bool {
+	if messagingType == "" {
+		return true
+	}
+	actual, created, err := util.EnsureTenant(ctx, getRegistryAPI(), tenantID, messagingType,
+		c2eCfg.DeviceRegistryAPIUsername, c2eCfg.DeviceRegistryAPIPassword)
+	if err != nil {
+		fmt.Printf("unable to create tenant %s, error: %v\n", tenantID, err)
+		return false
+	}
+	if created {
+		fmt.Printf("%s tenant '%s' with messaging type %s created\n", indent, tenantID, messagingType)
+	} else if actual == "" {
+		fmt.Printf("%s tenant '%s' already exists with the default messaging type of Hono, it is not modified\n",
+			indent, tenantID)
+	} else if actual != messagingType {
+		fmt.Printf("tenant %s already exists with messaging type %s instead of %s, it is not modified\n",
+			tenantID, actual, messagingType)
+		return false
+	}
+	return true
+}
+
 func deleteFile(path string) bool {
 	if err := os.Remove(path); err != nil {
 		fmt.Printf("unable to delete file %s, error: %v", path, err)
@@ -347,6 +420,10 @@ func deleteFile(path string) bool {
 	return true
 }
 
+func getRegistryAPI() string {
+	return strings.TrimSuffix(c2eCfg.DeviceRegistryAPIAddress, "/") + "/v1"
+}
+
 func getTenantURL() string {
 	return fmt.Sprintf(
 		"%s/v1/devices/%s/", strings.TrimSuffix(c2eCfg.DeviceRegistryAPIAddress, "/"), tenantID)
@@ -390,9 +467,31 @@ func writeConfigLdtFile(path string) error {
 	return util.WriteConfigFile(path, cfg)
 }
 
+func waitForDeviceID(expectedDeviceID string) bool {
+	if connectTimeout <= 0 {
+		return true
+	}
+	fmt.Printf("waiting for device id %s to be reported...", expectedDeviceID)
+	mqttClient, err := util.NewMQTTClient(ctx, &cfg)
+	if err != nil {
+		fmt.Printf("unable to open local MQTT connection to %s, error: %v\n", cfg.LocalBroker, err)
+		return false
+	}
+	defer mqttClient.Disconnect(uint(cfg.MQTTQuiesceMS))
+
+	waitCtx, cancel := context.WithTimeout(ctx, connectTimeout)
+	defer cancel()
+	if _, err := util.WaitForThingConfiguration(waitCtx, &cfg, mqttClient, expectedDeviceID); err != nil {
+		fmt.Printf("error waiting for device id: %v\n", err)
+		return false
+	}
+	fmt.Println("... done")
+	return true
+}
+
 func restartService(service string) bool {
 	fmt.Printf("restarting %s...", service)
-	stdout, err := exec.Command(systemctl, restart, service).Output()
+	stdout, err := controlService(restart, service)
 	if stdout != nil {
 		fmt.Println(string(stdout))
 	}
@@ -0,0 +1,263 @@
+// Copyright (c) 2026 Contributors to the Eclipse Foundation
+//
+// See the NOTICE file(s) distributed with this work for additional
+// information regarding copyright ownership.
+//
+// This program and the accompanying materials are made available under the
+// terms of the Eclipse Public License 2.0 which is available at
+// https://www.eclipse.org/legal/epl-2.0, or the Apache License, Version 2.0
+// which is available at https://www.apache.org/licenses/LICENSE-2.0.
+//
+// SPDX-License-Identifier: EPL-2.0 OR Apache-2.0
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/eclipse-kanto/kanto/integration/util"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testUser           = "test"
+	testPass           = "test"
+	testTenantID       = "test-tenant"
+	testDeviceID       = "test:device"
+	testPolicyID       = "test:policy"
+	testInitialConfig  = `{"deviceId":"initial"}`
+	testCredentialsURL = "/v1/credentials/"
+)
+
+// testEnvironment is a hermetic setup environment, which uses a fake device registry, a fake Ditto
+// and records the service commands instead of executing them. The service commands can be made to fail a number of times.
+type testEnvironment struct {
+	registry  *util.FakeDeviceRegistry
+	ditto     *util.FakeDitto
+	resources []*util.Resource
+	commands  []string
+	failures  map[string]int
+}
+
+func newTestEnvironment(t *testing.T) *testEnvironment {
+	env := &testEnvironment{
+		registry: util.StartFakeDeviceRegistry(testUser, testPass),
+		ditto:    util.StartFakeDitto(testUser, testPass),
+		failures: map[string]int{},
+	}
+	t.Cleanup(env.registry.Close)
+	t.Cleanup(env.ditto.Close)
+
+	dir := t.TempDir()
+	configConnectorFile = filepath.Join(dir, "config.json")
+	configConnectorFileBackup = filepath.Join(dir, "configBackup.json")
+	require.NoError(t, os.WriteFile(configConnectorFile, []byte(testInitialConfig), 0644))
+
+	cfg = util.TestConfiguration{
+		DigitalTwinAPIAddress:  env.ditto.URL(),
+		DigitalTwinAPIUsername: testUser,
+		DigitalTwinAPIPassword: testPass,
+	}
+	c2eCfg = c2eConfiguration{
+		DeviceRegistryAPIAddress:  env.registry.URL(),
+		DeviceRegistryAPIUsername: testUser,
+		DeviceRegistryAPIPassword: testPass,
+	}
+	deviceID = testDeviceID
+	tenantID = testTenantID
+	policyID = testPolicyID
+	password = testPass
+	messagingType = ""
+	ldt = false
+	connectTimeout = 0
+	ctx = context.Background()
+
+	originalControlService := controlService
+	controlService = func(command string, service string) ([]byte, error) {
+		env.commands = append(env.commands, command+" "+service)
+		if env.failures[command+" "+service] > 0 {
+			env.failures[command+" "+service]--
+			return nil, errors.New("service command failed")
+		}
+		return nil, nil
+	}
+	t.Cleanup(func() { controlService = originalControlService })
+
+	env.resources = util.CreateDeviceResources(deviceID, tenantID, policyID, password, getRegistryAPI(),
+		c2eCfg.DeviceRegistryAPIUsername, c2eCfg.DeviceRegistryAPIPassword, &cfg)
+	return env
+}
+
+// requireRolledBack asserts that neither the device nor its thing exist and the configuration file is not modified
+func (env *testEnvironment) requireRolledBack(t *testing.T) {
+	_, ok := env.registry.GetDevice(testTenantID, testDeviceID)
+	require.False(t, ok, "device not deleted")
+	_, ok = env.ditto.GetThing(testDeviceID)
+	require.False(t, ok, "thing not deleted")
+
+	config, err := os.ReadFile(configConnectorFile)
+	require.NoError(t, err)
+	require.Equal(t, testInitialConfig, string(config))
+	require.NoFileExists(t, configConnectorFileBackup)
+}
+
+func TestSetUp(t *testing.T) {
+	env := newTestEnvironment(t)
+
+	require.True(t, performSetUp(env.resources))
+
+	device, ok := env.registry.GetDevice(testTenantID, testDeviceID)
+	require.True(t, ok)
+	require.Equal(t, []string{"auto-provisioning-enabled"}, device.Authorities)
+	_, ok = env.registry.GetCredentials(testTenantID, testDeviceID)
+	require.True(t, ok)
+	thing, ok := env.ditto.GetThing(testDeviceID)
+	require.True(t, ok)
+	require.Equal(t, testPolicyID, thing["policyId"])
+
+	data, err := os.ReadFile(configConnectorFile)
+	require.NoError(t, err)
+	config := &util.ConnectorConfiguration{}
+	require.NoError(t, json.Unmarshal(data, config))
+	require.Equal(t, testDeviceID, config.DeviceID)
+	require.FileExists(t, configConnectorFileBackup)
+	require.Equal(t, []string{restart + " " + suiteConnectorService}, env.commands)
+
+	require.True(t, performCleanUp(env.resources))
+	env.requireRolledBack(t)
+}
+
+func TestSetUpRollsBackOnCredentialsFailure(t *testing.T) {
+	env := newTestEnvironment(t)
+	env.registry.InjectFault(http.MethodPut, testCredentialsURL, http.StatusInternalServerError, 1)
+
+	require.False(t, performSetUp(env.resources))
+
+	env.requireRolledBack(t)
+	require.Contains(t, env.registry.Requests(), http.MethodDelete+" /v1/devices/"+testTenantID+"/"+testDeviceID)
+	require.Empty(t, env.commands)
+}
+
+func TestSetUpRollsBackOnRestartFailure(t *testing.T) {
+	env := newTestEnvironment(t)
+	env.failures[restart+" "+suiteConnectorService] = 1
+
+	require.False(t, performSetUp(env.resources))
+
+	env.requireRolledBack(t)
+	require.Equal(t, []string{
+		restart + " " + suiteConnectorService,
+		restart + " " + suiteConnectorService,
+	}, env.commands)
+}
+
+func TestSetUpCreatesTenant(t *testing.T) {
+	env := newTestEnvironment(t)
+	messagingType = "kafka"
+
+	require.True(t, performSetUp(env.resources))
+
+	tenant, ok := env.registry.GetTenant(testTenantID)
+	require.True(t, ok)
+	require.Equal(t, map[string]interface{}{"messaging-type": "kafka"}, tenant.(map[string]interface{})["ext"])
+}
+
+func TestSetUpFailsOnTenantLookupError(t *testing.T) {
+	env := newTestEnvironment(t)
+	messagingType = "kafka"
+	env.registry.InjectFault(http.MethodGet, "/v1/tenants/", http.StatusInternalServerError, 1)
+
+	require.False(t, performSetUp(env.resources))
+
+	_, ok := env.registry.GetTenant(testTenantID)
+	require.False(t, ok, "tenant created although its lookup failed")
+	require.NotContains(t, env.registry.Requests(), http.MethodPost+" /v1/tenants/"+testTenantID)
+	env.requireRolledBack(t)
+}
+
+func TestSetUpFailsOnTenantMessagingTypeMismatch(t *testing.T) {
+	env := newTestEnvironment(t)
+	_, created, err := util.EnsureTenant(ctx, getRegistryAPI(), testTenantID, "amqp", testUser, testPass)
+	require.NoError(t, err)
+	require.True(t, created)
+	messagingType = "kafka"
+
+	require.False(t, performSetUp(env.resources))
+
+	env.requireRolledBack(t)
+	require.Empty(t, env.commands)
+}
+
+func TestIsValidMessagingType(t *testing.T) {
+	for _, value := range []string{"", util.HonoMessagingAMQP, util.HonoMessagingKafka} {
+		require.True(t, isValidMessagingType(value), value)
+	}
+	for _, value := range []string{"AMQP", "mqtt", " kafka"} {
+		require.False(t, isValidMessagingType(value), value)
+	}
+}
+
+func TestSetUpWithoutMessagingType(t *testing.T) {
+	env := newTestEnvironment(t)
+
+	require.True(t, performSetUp(env.resources))
+
+	for _, request := range env.registry.Requests() {
+		require.NotContains(t, request, "/v1/tenants/", "tenant accessed without a messaging type")
+	}
+}
+
+func TestSetUpKeepsExistingTenant(t *testing.T) {
+	tests := []struct {
+		name     string
+		existing string
+		ext      interface{}
+	}{
+		{
+			name:     "same messaging type",
+			existing: `{"ext":{"messaging-type":"kafka"}}`,
+			ext:      map[string]interface{}{"messaging-type": "kafka"},
+		},
+		{
+			name:     "default messaging type",
+			existing: `{}`,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			env := newTestEnvironment(t)
+			_, err := util.SendDeviceRegistryRequest(ctx, []byte(test.existing), http.MethodPost,
+				getRegistryAPI()+"/tenants/"+testTenantID, testUser, testPass)
+			require.NoError(t, err)
+			messagingType = util.HonoMessagingKafka
+
+			require.True(t, performSetUp(env.resources))
+
+			tenant, ok := env.registry.GetTenant(testTenantID)
+			require.True(t, ok)
+			require.Equal(t, test.ext, tenant.(map[string]interface{})["ext"])
+			require.Equal(t, 1, countRequests(env.registry, http.MethodPost+" /v1/tenants/"+testTenantID))
+
+			// Tenants are not deleted on cleanup
+			require.True(t, performCleanUp(env.resources))
+			_, ok = env.registry.GetTenant(testTenantID)
+			require.True(t, ok, "tenant deleted on cleanup")
+		})
+	}
+}
+
+func countRequests(registry *util.FakeDeviceRegistry, request string) int {
+	count := 0
+	for _, received := range registry.Requests() {
+		if received == request {
+			count++
+		}
+	}
+	return count
+}
@@ -0,0 +1,284 @@
+// Copyright (c) 2026 Contributors to the Eclipse Foundation
+//
+// See the NOTICE file(s) distributed with this work for additional
+// information regarding copyright ownership.
+//
+// This program and the accompanying materials are made available under the
+// terms of the Eclipse Public License 2.0 which is available at
+// https://www.eclipse.org/legal/epl-2.0, or the Apache License, Version 2.0
+// which is available at https://www.apache.org/licenses/LICENSE-2.0.
+//
+// SPDX-License-Identifier: EPL-2.0 OR Apache-2.0
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/eclipse-kanto/kanto/integration/util"
+	"github.com/google/uuid"
+)
+
+const (
+	helloScriptURL    = "https://github.com/eclipse-kanto/kanto/raw/main/quickstart/install_hello.sh"
+	helloScriptSHA256 = "db954c633393c1402f145a60fd58d312f5af96ce49422fcfd6ce42a3c4cceeca"
+	helloScriptSize   = 544
+)
+
+// liveMessage is a live message to be sent to a feature of a thing
+type liveMessage struct {
+	// ThingSuffix is appended to the device ID to get the ID of the thing, which has the feature
+	ThingSuffix string
+	// ThingID overrides the thing ID derived from the device ID
+	ThingID string
+
+	Feature string
+	Action  string
+	Value   interface{}
+
+	// NoResponse marks messages, which are sent without requiring a response
+	NoResponse bool
+}
+
+// command defines the flags of a command and builds its live message once the flags are parsed
+type command struct {
+	description string
+	define      func(flags *flag.FlagSet) func() (*liveMessage, error)
+}
+
+var commands = map[string]*command{
+	"run": {
+		description: "Create and start a container",
+		define: func(flags *flag.FlagSet) func() (*liveMessage, error) {
+			imageRef := flags.String("img", "", "Container image reference, e.g. docker.io/library/hello-world:latest")
+			return func() (*liveMessage, error) {
+				if *imageRef == "" {
+					return nil, errors.New("container image reference is not specified")
+				}
+				return &liveMessage{
+					ThingSuffix: util.ContainersThingSuffix,
+					Feature:     util.ContainerFactoryFeatureID,
+					Action:      "create",
+					Value:       &util.ContainerCreateRequest{ImageRef: *imageRef, Start: true},
+				}, nil
+			}
+		},
+	},
+	"rm": {
+		description: "Remove a container",
+		define: func(flags *flag.FlagSet) func() (*liveMessage, error) {
+			id := flags.String("id", "", "Container ID")
+			return func() (*liveMessage, error) {
+				if *id == "" {
+					return nil, errors.New("container ID is not specified")
+				}
+				return &liveMessage{
+					ThingSuffix: util.ContainersThingSuffix,
+					Feature:     util.GetContainerFeatureID(*id),
+					Action:      "remove",
+					Value:       true,
+				}, nil
+			}
+		},
+	},
+	"install": {
+		description: "Install a software module, defaults to the hello world example",
+		define: func(flags *flag.FlagSet) func() (*liveMessage, error) {
+			name := flags.String("name", "install-hello", "Software module name")
+			version := flags.String("version", "1.0.0", "Software module version")
+			url := flags.String("url", helloScriptURL, "Artifact download URL")
+			sha256 := flags.String("sha256", helloScriptSHA256, "Artifact SHA256 checksum")
+			filename := flags.String("filename", "install.sh", "Artifact file name")
+			size := flags.Int("size", helloScriptSize, "Artifact size in bytes")
+			return func() (*liveMessage, error) {
+				module := util.NewSoftwareModuleAction(*name, *version, *url, *sha256, *size)
+				module.Artifacts[0].Filename = *filename
+				return &liveMessage{
+					Feature: util.SoftwareUpdatableFeatureID,
+					Action:  "install",
+					Value: &util.SoftwareUpdateAction{
+						CorrelationID:   uuid.New().String(),
+						SoftwareModules: []*util.SoftwareModuleAction{module},
+					},
+				}, nil
+			}
+		},
+	},
+	"apply": {
+		description: "Apply a desired state with the update manager",
+		define: func(flags *flag.FlagSet) func() (*liveMessage, error) {
+			file := flags.String("file", "", "Path to a desired state JSON file")
+			activityID := flags.String("activityId", "", "Activity ID, defaults to randomly generated")
+			return func() (*liveMessage, error) {
+				if *file == "" {
+					return nil, errors.New("desired state file is not specified")
+				}
+				data, err := os.ReadFile(*file)
+				if err != nil {
+					return nil, err
+				}
+				desiredState, err := util.ParseDesiredState(data)
+				if err != nil {
+					return nil, fmt.Errorf("%s: %v", *file, err)
+				}
+				return &liveMessage{
+					Feature: util.UpdateManagerFeatureID,
+					Action:  "apply",
+					Value:   map[string]interface{}{"activityId": idOrRandom(*activityID), "desiredState": desiredState},
+				}, nil
+			}
+		},
+	},
+	"refresh": {
+		description: "Refresh the current state of the update manager",
+		define: func(flags *flag.FlagSet) func() (*liveMessage, error) {
+			activityID := flags.String("activityId", "", "Activity ID, defaults to randomly generated")
+			return func() (*liveMessage, error) {
+				return &liveMessage{
+					Feature: util.UpdateManagerFeatureID,
+					Action:  "refresh",
+					Value:   map[string]interface{}{"activityId": idOrRandom(*activityID)},
+				}, nil
+			}
+		},
+	},
+	"upload-trigger": {
+		description: "Trigger a file upload",
+		define: func(flags *flag.FlagSet) func() (*liveMessage, error) {
+			correlationID := flags.String("correlationId", "", "Upload correlation ID, defaults to randomly generated")
+			return func() (*liveMessage, error) {
+				return &liveMessage{
+					Feature: util.AutoUploadableFeatureID,
+					Action:  "trigger",
+					Value:   &util.UploadAction{CorrelationID: idOrRandom(*correlationID)},
+				}, nil
+			}
+		},
+	},
+	"upload-start": {
+		description: "Start a requested file upload",
+		define: func(flags *flag.FlagSet) func() (*liveMessage, error) {
+			correlationID := flags.String("correlationId", "", "Correlation ID of the upload request event")
+			url := flags.String("url", "", "HTTPS upload URL")
+			return func() (*liveMessage, error) {
+				if *correlationID == "" || *url == "" {
+					return nil, errors.New("correlation ID and upload URL must be specified")
+				}
+				return &liveMessage{
+					Feature: util.AutoUploadableFeatureID,
+					Action:  "start",
+					Value: &util.UploadAction{
+						CorrelationID: *correlationID,
+						Options:       (&util.UploadOptions{URL: *url}).ToMap(),
+					},
+				}, nil
+			}
+		},
+	},
+	"backup": {
+		description: "Back up the device data",
+		define: func(flags *flag.FlagSet) func() (*liveMessage, error) {
+			correlationID := flags.String("correlationId", "", "Backup correlation ID, defaults to randomly generated")
+			return func() (*liveMessage, error) {
+				return &liveMessage{
+					Feature: util.BackupAndRestoreFeatureID,
+					Action:  "backup",
+					Value:   &util.UploadAction{CorrelationID: idOrRandom(*correlationID)},
+				}, nil
+			}
+		},
+	},
+	"restore": {
+		description: "Restore the device data",
+		define: func(flags *flag.FlagSet) func() (*liveMessage, error) {
+			correlationID := flags.String("correlationId", "", "Restore correlation ID, defaults to randomly generated")
+			url := flags.String("url", "", "HTTPS download URL of the backup")
+			return func() (*liveMessage, error) {
+				if *url == "" {
+					return nil, errors.New("backup download URL is not specified")
+				}
+				return &liveMessage{
+					Feature: util.BackupAndRestoreFeatureID,
+					Action:  "restore",
+					Value: &util.UploadAction{
+						CorrelationID: idOrRandom(*correlationID),
+						Options:       (&util.BackupAndRestoreOptions{UploadOptions: util.UploadOptions{URL: *url}}).ToMap(),
+					},
+				}, nil
+			}
+		},
+	},
+	"metrics": {
+		description: "Request the system metrics, the metrics data is printed as it arrives",
+		define: func(flags *flag.FlagSet) func() (*liveMessage, error) {
+			frequency := flags.Duration("frequency", 2*time.Second, "Metrics reporting frequency, zero stops the reporting")
+			ids := flags.String("ids", "", "Comma separated metric IDs to report, e.g. cpu.*,memory.used, defaults to all")
+			originator := flags.String("originator", "", "Originator of the metrics to report, defaults to all")
+			return func() (*liveMessage, error) {
+				var filters []*util.MetricsFilter
+				if *ids != "" || *originator != "" {
+					var metricIDs []string
+					if *ids != "" {
+						metricIDs = strings.Split(*ids, ",")
+					}
+					filters = append(filters, util.NewMetricsFilter(*originator, metricIDs...))
+				}
+				return &liveMessage{
+					Feature: util.MetricsFeatureID,
+					Action:  "request",
+					Value:   util.NewMetricsRequest(*frequency, filters...),
+				}, nil
+			}
+		},
+	},
+	"send": {
+		description: "Send a live message to any feature",
+		define: func(flags *flag.FlagSet) func() (*liveMessage, error) {
+			thingID := flags.String("thing", "", "Thing ID, defaults to the device ID")
+			feature := flags.String("feature", "", "Feature ID")
+			action := flags.String("action", "", "Message subject, e.g. the operation name")
+			value := flags.String("value", "", "JSON message payload")
+			noResponse := flags.Bool("noResponse", false, "Send the message without requiring a response")
+			return func() (*liveMessage, error) {
+				if *feature == "" || *action == "" {
+					return nil, errors.New("feature and action must be specified")
+				}
+				msg := &liveMessage{
+					ThingID:    *thingID,
+					Feature:    *feature,
+					Action:     *action,
+					NoResponse: *noResponse,
+				}
+				if *value != "" {
+					if err := json.Unmarshal([]byte(*value), &msg.Value); err != nil {
+						return nil, fmt.Errorf("invalid JSON value: %v", err)
+					}
+				}
+				return msg, nil
+			}
+		},
+	},
+}
+
+func idOrRandom(id string) string {
+	if id == "" {
+		return uuid.New().String()
+	}
+	return id
+}
+
+func commandNames() []string {
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
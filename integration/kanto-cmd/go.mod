@@ -0,0 +1,34 @@
+module github.com/eclipse-kanto/kanto/integration/kanto-cmd
+
+go 1.21
+
+require (
+	github.com/caarlos0/env/v6 v6.10.1
+	github.com/eclipse-kanto/kanto/integration/util v0.0.0-20240201094116-d9d28a339764
+	github.com/eclipse/ditto-clients-golang v0.0.0-20220225085802-cf3b306280d3
+	github.com/google/uuid v1.3.0
+	github.com/stretchr/testify v1.8.4
+	golang.org/x/net v0.25.0
+)
+
+require (
+	github.com/Azure/go-amqp v1.3.0 // indirect
+	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/eclipse/paho.golang v0.21.0 // indirect
+	github.com/eclipse/paho.mqtt.golang v1.4.1 // indirect
+	github.com/gorilla/websocket v1.5.1 // indirect
+	github.com/klauspost/compress v1.15.15 // indirect
+	github.com/mochi-mqtt/server/v2 v2.6.6 // indirect
+	github.com/pierrec/lz4/v4 v4.1.15 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	github.com/rs/xid v1.4.0 // indirect
+	github.com/segmentio/kafka-go v0.4.47 // indirect
+	github.com/xdg-go/pbkdf2 v1.0.0 // indirect
+	github.com/xdg-go/scram v1.1.2 // indirect
+	github.com/xdg-go/stringprep v1.0.4 // indirect
+	golang.org/x/sync v0.7.0 // indirect
+	golang.org/x/text v0.15.0 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
+
+replace github.com/eclipse-kanto/kanto/integration/util => ../util
@@ -0,0 +1,239 @@
+// Copyright (c) 2026 Contributors to the Eclipse Foundation
+//
+// See the NOTICE file(s) distributed with this work for additional
+// information regarding copyright ownership.
+//
+// This program and the accompanying materials are made available under the
+// terms of the Eclipse Public License 2.0 which is available at
+// https://www.eclipse.org/legal/epl-2.0, or the Apache License, Version 2.0
+// which is available at https://www.apache.org/licenses/LICENSE-2.0.
+//
+// SPDX-License-Identifier: EPL-2.0 OR Apache-2.0
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"time"
+
+	env "github.com/caarlos0/env/v6"
+	"github.com/eclipse-kanto/kanto/integration/util"
+	"github.com/eclipse/ditto-clients-golang/protocol"
+	"github.com/google/uuid"
+	"golang.org/x/net/websocket"
+)
+
+const (
+	eventsFilterTemplate = `eq(thingId,"%s")`
+)
+
+var (
+	cfg util.TestConfiguration
+
+	deviceID string
+	timeout  time.Duration
+	follow   time.Duration
+)
+
+// outgoingMessage is a Ditto protocol live message, which topic and path are built by the util helpers
+type outgoingMessage struct {
+	Topic   string                 `json:"topic"`
+	Headers map[string]interface{} `json:"headers"`
+	Path    string                 `json:"path"`
+	Value   interface{}            `json:"value,omitempty"`
+}
+
+func main() {
+	flag.StringVar(&deviceID, "d", os.Getenv("DEVICE_ID"), "Device ID, defaults to the DEVICE_ID environment variable")
+	flag.DurationVar(&timeout, "timeout", 30*time.Second, "Time to wait for the response")
+	flag.DurationVar(&follow, "follow", 10*time.Second,
+		"Time to print the follow-up events and messages after the response. "+
+			"If set to zero, the command completes with the response")
+
+	flag.Usage = printUsage
+	flag.Parse()
+
+	if flag.NArg() == 0 {
+		printUsage()
+		os.Exit(1)
+	}
+	cmd, ok := commands[flag.Arg(0)]
+	if !ok {
+		fmt.Printf("unsupported command %s\n", flag.Arg(0))
+		printUsage()
+		os.Exit(1)
+	}
+
+	cmdFlags := flag.NewFlagSet(flag.Arg(0), flag.ExitOnError)
+	build := cmd.define(cmdFlags)
+	cmdFlags.Parse(flag.Args()[1:])
+
+	msg, err := build()
+	if err != nil {
+		fmt.Println(err)
+		cmdFlags.Usage()
+		os.Exit(1)
+	}
+	if deviceID == "" && msg.ThingID == "" {
+		fmt.Println("device id is not specified")
+		os.Exit(1)
+	}
+
+	if err := env.Parse(&cfg, env.Options{RequiredIfNoDef: true}); err != nil {
+		fmt.Printf("failed to process environment variables: %v\n", err)
+		os.Exit(1)
+	}
+	cfg.WSEventTimeoutMS = int(timeout / time.Millisecond)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	if err := execute(ctx, msg); err != nil {
+		fmt.Printf("[error] %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func printUsage() {
+	out := flag.CommandLine.Output()
+	fmt.Fprintf(out, "Usage: %s [flags] <command> [command flags]\n\nCommands:\n", os.Args[0])
+	for _, name := range commandNames() {
+		fmt.Fprintf(out, "  %-16s%s\n", name, commands[name].description)
+	}
+	fmt.Fprintln(out, "\nFlags:")
+	flag.PrintDefaults()
+	fmt.Fprintln(out, "\nThe Ditto connection is configured with the DIGITAL_TWIN_API_ADDRESS, "+
+		"DIGITAL_TWIN_API_USERNAME and DIGITAL_TWIN_API_PASSWORD environment variables.")
+}
+
+func execute(ctx context.Context, msg *liveMessage) error {
+	thingID := msg.ThingID
+	if thingID == "" {
+		thingID = deviceID + msg.ThingSuffix
+	}
+
+	ws, err := util.NewDigitalTwinWSConnection(ctx, &cfg)
+	if err != nil {
+		return fmt.Errorf("unable to connect to Ditto: %v", err)
+	}
+	defer ws.Close()
+
+	filter := fmt.Sprintf(eventsFilterTemplate, thingID)
+	if err := util.SubscribeForWSMessages(ctx, &cfg, ws, util.StartSendEvents, filter); err != nil {
+		return fmt.Errorf("unable to subscribe for events: %v", err)
+	}
+	if err := util.SubscribeForWSMessages(ctx, &cfg, ws, util.StartSendMessages, filter); err != nil {
+		return fmt.Errorf("unable to subscribe for messages: %v", err)
+	}
+
+	correlationID := uuid.New().String()
+	outgoing := &outgoingMessage{
+		Topic: util.GetLiveMessageTopic(thingID, protocol.TopicAction(msg.Action)),
+		Headers: map[string]interface{}{
+			protocol.HeaderContentType:      "application/json",
+			protocol.HeaderCorrelationID:    correlationID,
+			protocol.HeaderResponseRequired: !msg.NoResponse,
+			protocol.HeaderTimeout:          fmt.Sprintf("%dms", timeout.Milliseconds()),
+		},
+		Path:  util.GetFeatureInboxMessagePath(msg.Feature, msg.Action),
+		Value: msg.Value,
+	}
+	fmt.Println("[sending]")
+	printJSON(outgoing)
+	if err := websocket.JSON.Send(ws, outgoing); err != nil {
+		return fmt.Errorf("unable to send the message: %v", err)
+	}
+
+	if msg.NoResponse {
+		return receive(ctx, ws, "", time.Now().Add(follow))
+	}
+	return receive(ctx, ws, correlationID, time.Now().Add(timeout))
+}
+
+// receive prints the received events and messages until the deadline or the context is done.
+// If a response is expected, the deadline is moved to the follow-up time once the response
+// with the correlation ID is received.
+func receive(ctx context.Context, ws *websocket.Conn, correlationID string, deadline time.Time) error {
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			// Interrupt the pending read
+			ws.SetReadDeadline(time.Now())
+		case <-done:
+		}
+	}()
+
+	var responseErr error
+	for {
+		readDeadline := deadline
+		if ctxDeadline, ok := ctx.Deadline(); ok && ctxDeadline.Before(readDeadline) {
+			readDeadline = ctxDeadline
+		}
+		if err := ws.SetReadDeadline(readDeadline); err != nil {
+			return err
+		}
+
+		// The context is checked after setting the read deadline, which would otherwise overwrite
+		// the deadline set by a cancel between the reads
+		err := ctx.Err()
+		var payload []byte
+		if err == nil {
+			err = websocket.Message.Receive(ws, &payload)
+			if err != nil && ctx.Err() != nil {
+				err = ctx.Err()
+			}
+		}
+		if err != nil {
+			if correlationID != "" {
+				return fmt.Errorf("response not received: %v", err)
+			}
+			return responseErr
+		}
+
+		envelope := &protocol.Envelope{}
+		if err := json.Unmarshal(payload, envelope); err != nil || envelope.Topic == nil {
+			continue
+		}
+
+		if correlationID != "" && envelope.Status != 0 && envelope.Headers != nil &&
+			envelope.Headers.CorrelationID() == correlationID {
+			fmt.Printf("[response] %d\n", envelope.Status)
+			printJSON(envelope)
+			if envelope.Status < 200 || envelope.Status > 299 {
+				responseErr = fmt.Errorf("unexpected response status %d", envelope.Status)
+			}
+			if follow <= 0 {
+				return responseErr
+			}
+			correlationID = ""
+			deadline = time.Now().Add(follow)
+			continue
+		}
+
+		switch envelope.Topic.Channel {
+		case protocol.ChannelTwin:
+			fmt.Printf("[event] %s %s\n", envelope.Topic.Action, envelope.Path)
+		case protocol.ChannelLive:
+			fmt.Printf("[message] %s %s\n", envelope.Topic.Action, envelope.Path)
+		default:
+			continue
+		}
+		printJSON(envelope.Value)
+	}
+}
+
+func printJSON(value interface{}) {
+	data, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		fmt.Printf("unable to format the payload: %v\n", err)
+		return
+	}
+	fmt.Println(string(data))
+}
@@ -0,0 +1,82 @@
+// Copyright (c) 2026 Contributors to the Eclipse Foundation
+//
+// See the NOTICE file(s) distributed with this work for additional
+// information regarding copyright ownership.
+//
+// This program and the accompanying materials are made available under the
+// terms of the Eclipse Public License 2.0 which is available at
+// https://www.eclipse.org/legal/epl-2.0, or the Apache License, Version 2.0
+// which is available at https://www.apache.org/licenses/LICENSE-2.0.
+//
+// SPDX-License-Identifier: EPL-2.0 OR Apache-2.0
+
+package main
+
+import (
+	"context"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/websocket"
+)
+
+const testDeadline = time.Minute
+
+// dialSilentServer connects to a WebSocket server, which never sends anything
+func dialSilentServer(t *testing.T) *websocket.Conn {
+	server := httptest.NewServer(websocket.Handler(func(ws *websocket.Conn) {
+		io.Copy(io.Discard, ws)
+	}))
+	t.Cleanup(server.Close)
+
+	ws, err := websocket.Dial("ws"+strings.TrimPrefix(server.URL, "http"), "", server.URL)
+	require.NoError(t, err)
+	t.Cleanup(func() { ws.Close() })
+	return ws
+}
+
+func TestReceiveCanceled(t *testing.T) {
+	ws := dialSilentServer(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(100*time.Millisecond, cancel)
+
+	start := time.Now()
+	err := receive(ctx, ws, "correlation", time.Now().Add(testDeadline))
+	require.EqualError(t, err, "response not received: context canceled")
+	require.Less(t, time.Since(start), testDeadline/2)
+}
+
+func TestReceiveCanceledBeforeRead(t *testing.T) {
+	ws := dialSilentServer(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err := receive(ctx, ws, "correlation", time.Now().Add(testDeadline))
+	require.EqualError(t, err, "response not received: context canceled")
+	// Without a response to wait for, the cancel completes the follow-up
+	require.NoError(t, receive(ctx, ws, "", time.Now().Add(testDeadline)))
+}
+
+func TestReceiveBoundedByContextDeadline(t *testing.T) {
+	ws := dialSilentServer(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	err := receive(ctx, ws, "correlation", time.Now().Add(testDeadline))
+	require.Error(t, err)
+	require.Less(t, time.Since(start), testDeadline/2)
+}
+
+func TestReceiveBoundedByDeadline(t *testing.T) {
+	ws := dialSilentServer(t)
+
+	err := receive(context.Background(), ws, "correlation", time.Now().Add(100*time.Millisecond))
+	require.ErrorContains(t, err, "response not received")
+	// The next receive is not interrupted by the previous one
+	require.NoError(t, receive(context.Background(), ws, "", time.Now().Add(100*time.Millisecond)))
+}
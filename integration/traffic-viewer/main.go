@@ -0,0 +1,230 @@
+// Copyright (c) 2026 Contributors to the Eclipse Foundation
+//
+// See the NOTICE file(s) distributed with this work for additional
+// information regarding copyright ownership.
+//
+// This program and the accompanying materials are made available under the
+// terms of the Eclipse Public License 2.0 which is available at
+// https://www.eclipse.org/legal/epl-2.0, or the Apache License, Version 2.0
+// which is available at https://www.apache.org/licenses/LICENSE-2.0.
+//
+// SPDX-License-Identifier: EPL-2.0 OR Apache-2.0
+
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/eclipse-kanto/kanto/integration/util"
+)
+
+const (
+	maxLineSize = 16 * 1024 * 1024
+
+	arrowOut = "->"
+	arrowIn  = "<-"
+)
+
+var (
+	showPayload bool
+	showHeaders bool
+	protocols   string
+)
+
+type timelineEntry struct {
+	*util.TrafficEntry
+	source string
+}
+
+func main() {
+	flag.BoolVar(&showPayload, "payload", false, "Show the payloads of the recorded traffic")
+	flag.BoolVar(&showHeaders, "headers", false, "Show the headers of the recorded HTTP traffic")
+	flag.StringVar(&protocols, "protocol", "",
+		"Comma separated list of protocols to show, e.g. mqtt,http,ws. Defaults to all protocols")
+
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [flags] <traffic file or directory>...\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(1)
+	}
+
+	files, err := collectFiles(flag.Args())
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	var entries []*timelineEntry
+	for _, file := range files {
+		fileEntries, err := readEntries(file)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		entries = append(entries, fileEntries...)
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Time.Before(entries[j].Time)
+	})
+	render(entries, len(files) > 1)
+}
+
+func collectFiles(args []string) ([]string, error) {
+	var files []string
+	for _, arg := range args {
+		info, err := os.Stat(arg)
+		if err != nil {
+			return nil, err
+		}
+		if !info.IsDir() {
+			files = append(files, arg)
+			continue
+		}
+		matches, err := filepath.Glob(filepath.Join(arg, "*"+util.TrafficFileExtension))
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, matches...)
+	}
+	return files, nil
+}
+
+func readEntries(file string) ([]*timelineEntry, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	source := strings.TrimSuffix(filepath.Base(file), util.TrafficFileExtension)
+	filter := protocolFilter()
+
+	var entries []*timelineEntry
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxLineSize)
+	for line := 1; scanner.Scan(); line++ {
+		if len(strings.TrimSpace(scanner.Text())) == 0 {
+			continue
+		}
+		entry := &util.TrafficEntry{}
+		if err := json.Unmarshal(scanner.Bytes(), entry); err != nil {
+			return nil, fmt.Errorf("%s:%d: invalid traffic entry: %v", file, line, err)
+		}
+		if filter == nil || filter[entry.Protocol] {
+			entries = append(entries, &timelineEntry{TrafficEntry: entry, source: source})
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("unable to read %s: %v", file, err)
+	}
+	return entries, nil
+}
+
+func protocolFilter() map[string]bool {
+	if protocols == "" {
+		return nil
+	}
+	filter := map[string]bool{}
+	for _, protocol := range strings.Split(protocols, ",") {
+		filter[strings.TrimSpace(protocol)] = true
+	}
+	return filter
+}
+
+func render(entries []*timelineEntry, withSource bool) {
+	if len(entries) == 0 {
+		fmt.Println("no traffic recorded")
+		return
+	}
+
+	start := entries[0].Time
+	fmt.Printf("timeline started at %s\n", start.Format(time.RFC3339Nano))
+	for _, entry := range entries {
+		line := fmt.Sprintf("%+10.3fs %-4s %s %s",
+			entry.Time.Sub(start).Seconds(), entry.Protocol, arrow(entry.Direction), summary(entry.TrafficEntry))
+		if withSource {
+			line = fmt.Sprintf("%s [%s]", line, entry.source)
+		}
+		fmt.Println(line)
+
+		if showHeaders {
+			renderHeaders(entry.Headers)
+		}
+		if showPayload && len(entry.Payload) > 0 {
+			fmt.Printf("%12s%s\n", "", string(entry.Payload))
+		}
+	}
+}
+
+func arrow(direction string) string {
+	if direction == util.TrafficIn {
+		return arrowIn
+	}
+	return arrowOut
+}
+
+func summary(entry *util.TrafficEntry) string {
+	var text string
+	switch entry.Protocol {
+	case util.TrafficMQTT:
+		text = entry.Topic
+	case util.TrafficHTTP:
+		text = fmt.Sprintf("%s %s", entry.Method, entry.URL)
+		if entry.Status != 0 {
+			text = fmt.Sprintf("%s %d", text, entry.Status)
+		}
+	case util.TrafficWS:
+		text = wsSummary(entry.Payload)
+	}
+	if entry.Error != "" {
+		text = fmt.Sprintf("%s error: %s", text, entry.Error)
+	}
+	return text
+}
+
+// wsSummary shows the topic and path of Ditto protocol messages or the text of the control messages
+func wsSummary(payload json.RawMessage) string {
+	envelope := struct {
+		Topic  string `json:"topic"`
+		Path   string `json:"path"`
+		Status int    `json:"status"`
+	}{}
+	if err := json.Unmarshal(payload, &envelope); err == nil && envelope.Topic != "" {
+		text := fmt.Sprintf("%s %s", envelope.Topic, envelope.Path)
+		if envelope.Status != 0 {
+			text = fmt.Sprintf("%s %d", text, envelope.Status)
+		}
+		return text
+	}
+
+	var text string
+	if err := json.Unmarshal(payload, &text); err == nil {
+		return strings.TrimSpace(text)
+	}
+	return ""
+}
+
+func renderHeaders(headers map[string]string) {
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Printf("%12s%s: %s\n", "", name, headers[name])
+	}
+}
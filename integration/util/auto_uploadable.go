@@ -0,0 +1,126 @@
+// Copyright (c) 2026 Contributors to the Eclipse Foundation
+//
+// See the NOTICE file(s) distributed with this work for additional
+// information regarding copyright ownership.
+//
+// This program and the accompanying materials are made available under the
+// terms of the Eclipse Public License 2.0 which is available at
+// https://www.eclipse.org/legal/epl-2.0, or the Apache License, Version 2.0
+// which is available at https://www.apache.org/licenses/LICENSE-2.0.
+//
+// SPDX-License-Identifier: EPL-2.0 OR Apache-2.0
+
+package util
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// AutoUploadableFeatureID is the ID of the AutoUploadable feature
+const AutoUploadableFeatureID = "AutoUploadable"
+
+// UploadCancelAction is the parameter of the cancel operation
+type UploadCancelAction struct {
+	CorrelationID string `json:"correlationId"`
+	StatusCode    string `json:"statusCode,omitempty"`
+	Message       string `json:"message,omitempty"`
+}
+
+// AutoUploadableClient executes the AutoUploadable operations and follows the progress of the uploads
+type AutoUploadableClient struct {
+	*FeatureClient
+}
+
+// NewAutoUploadableClient creates a client for the AutoUploadable feature of the thing.
+// The live messages of the thing are subscribed as well, so the upload requests of the feature are answered.
+func NewAutoUploadableClient(ctx context.Context, cfg *TestConfiguration,
+	thingID string) (*AutoUploadableClient, error) {
+	client, err := NewFeatureClient(ctx, cfg, thingID, AutoUploadableFeatureID)
+	if err != nil {
+		return nil, err
+	}
+	if err := client.subscribeForMessages(ctx); err != nil {
+		client.Close()
+		return nil, err
+	}
+	return &AutoUploadableClient{FeatureClient: client}, nil
+}
+
+// Trigger triggers an upload and waits for the requested uploads to finish. Each upload requested by the device
+// is started with the upload options. The upload progress is reported to the optional callback.
+// A random correlation ID is used if empty. An error is returned along with the final upload status
+// if any of the uploads does not succeed.
+func (client *AutoUploadableClient) Trigger(ctx context.Context, correlationID string, options *UploadOptions,
+	progress func(*UploadStatus)) (*UploadStatus, error) {
+	action := &UploadAction{CorrelationID: correlationIDOrRandom(correlationID), Options: options.ToMap()}
+	return client.trigger(ctx, action, fixedUploadOptions(options), progress)
+}
+
+// TriggerToReceiver triggers an upload, starts each upload requested by the device to the receiver and waits for
+// the uploads to finish. The files received for the upload requests are returned, ordered by correlation ID.
+// An error is returned if any of the uploads does not succeed or is not received.
+func (client *AutoUploadableClient) TriggerToReceiver(ctx context.Context, correlationID string,
+	receiver *UploadReceiver, progress func(*UploadStatus)) ([]*ReceivedUpload, error) {
+	var requested []string
+	options := func(request *UploadRequest) *UploadOptions {
+		requested = append(requested, request.CorrelationID)
+		return receiver.UploadOptions(request.CorrelationID)
+	}
+	action := &UploadAction{CorrelationID: correlationIDOrRandom(correlationID)}
+	if _, err := client.trigger(ctx, action, options, progress); err != nil {
+		return nil, err
+	}
+	if len(requested) == 0 {
+		return nil, errors.New("upload finished without being requested")
+	}
+
+	sort.Strings(requested)
+	var uploads []*ReceivedUpload
+	for _, name := range requested {
+		upload, ok := receiver.Upload(name)
+		if !ok {
+			return nil, fmt.Errorf("upload %s succeeded, but no file is received", name)
+		}
+		uploads = append(uploads, upload)
+	}
+	return uploads, nil
+}
+
+// Start starts a requested upload with the options without waiting for it to finish
+func (client *AutoUploadableClient) Start(ctx context.Context, correlationID string, options *UploadOptions) error {
+	if correlationID == "" {
+		return errors.New("correlation ID of the upload request is not specified")
+	}
+	_, err := client.ExecuteOperation(ctx, uploadOperationStart,
+		&UploadAction{CorrelationID: correlationID, Options: options.ToMap()})
+	return err
+}
+
+// Cancel cancels a requested or running upload
+func (client *AutoUploadableClient) Cancel(ctx context.Context, action *UploadCancelAction) error {
+	if action.CorrelationID == "" {
+		return errors.New("correlation ID of the upload to cancel is not specified")
+	}
+	_, err := client.ExecuteOperation(ctx, uploadOperationCancel, action)
+	return err
+}
+
+// LastUpload retrieves the status of the last upload
+func (client *AutoUploadableClient) LastUpload(ctx context.Context) (*UploadStatus, error) {
+	status := &UploadStatus{}
+	if err := client.GetProperty(ctx, uploadPropertyLastUpload, status); err != nil {
+		return nil, err
+	}
+	return status, nil
+}
+
+func (client *AutoUploadableClient) trigger(ctx context.Context, action *UploadAction,
+	options func(*UploadRequest) *UploadOptions, progress func(*UploadStatus)) (*UploadStatus, error) {
+	if _, err := client.ExecuteOperation(ctx, uploadOperationTrigger, action); err != nil {
+		return nil, err
+	}
+	return awaitUpload(ctx, client.FeatureClient, action.CorrelationID, options, progress, nil)
+}
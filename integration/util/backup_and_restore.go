@@ -0,0 +1,208 @@
+// Copyright (c) 2026 Contributors to the Eclipse Foundation
+//
+// See the NOTICE file(s) distributed with this work for additional
+// information regarding copyright ownership.
+//
+// This program and the accompanying materials are made available under the
+// terms of the Eclipse Public License 2.0 which is available at
+// https://www.eclipse.org/legal/epl-2.0, or the Apache License, Version 2.0
+// which is available at https://www.apache.org/licenses/LICENSE-2.0.
+//
+// SPDX-License-Identifier: EPL-2.0 OR Apache-2.0
+
+package util
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/eclipse/ditto-clients-golang/protocol"
+)
+
+// BackupAndRestoreState is the state of a backup or restore operation
+type BackupAndRestoreState string
+
+const (
+	// BackupAndRestoreFeatureID is the ID of the BackupAndRestore feature
+	BackupAndRestoreFeatureID = "BackupAndRestore"
+
+	// BackupStarted is reported when the backup of the data is started.
+	BackupStarted BackupAndRestoreState = "BACKUP_STARTED"
+	// BackupFinished is reported when the data is backed up, the backup is uploaded afterwards.
+	BackupFinished BackupAndRestoreState = "BACKUP_FINISHED"
+	// BackupFailed is reported when the backup of the data fails.
+	BackupFailed BackupAndRestoreState = "BACKUP_FAILED"
+	// RestoreStarted is reported when the restore of the data is started.
+	RestoreStarted BackupAndRestoreState = "RESTORE_STARTED"
+	// RestoreFinished is reported when the data is restored.
+	RestoreFinished BackupAndRestoreState = "RESTORE_FINISHED"
+	// RestoreFailed is reported when the restore of the data fails.
+	RestoreFailed BackupAndRestoreState = "RESTORE_FAILED"
+
+	backupOperationBackup  = "backup"
+	backupOperationRestore = "restore"
+
+	backupOptionDir = "backup.dir"
+
+	backupPropertyLastOperation = "lastOperation"
+)
+
+// IsFinal returns true if no more progress is reported for an operation in the state
+func (state BackupAndRestoreState) IsFinal() bool {
+	return state == BackupFinished || state == BackupFailed || state == RestoreFinished || state == RestoreFailed
+}
+
+// BackupAndRestoreOptions are the options of the backup and restore operations.
+// The upload options are used to upload the backup and to download it on restore.
+type BackupAndRestoreOptions struct {
+	UploadOptions
+	// Dir is the local directory to be backed up or restored, the configured one is used by the device if empty
+	Dir string
+}
+
+// ToMap returns the options in the format of the backup and restore operations
+func (options *BackupAndRestoreOptions) ToMap() map[string]string {
+	if options == nil {
+		return map[string]string{}
+	}
+	result := options.UploadOptions.ToMap()
+	if options.Dir != "" {
+		result[backupOptionDir] = options.Dir
+	}
+	return result
+}
+
+// BackupAndRestoreStatus is the progress of a backup or restore operation, reported by the lastOperation property
+type BackupAndRestoreStatus struct {
+	CorrelationID string                `json:"correlationId"`
+	State         BackupAndRestoreState `json:"state"`
+	Progress      int                   `json:"progress,omitempty"`
+	Message       string                `json:"message,omitempty"`
+	StartTime     string                `json:"startTime,omitempty"`
+	EndTime       string                `json:"endTime,omitempty"`
+}
+
+// BackupAndRestoreClient executes the BackupAndRestore operations and follows their progress
+type BackupAndRestoreClient struct {
+	*FeatureClient
+}
+
+// NewBackupAndRestoreClient creates a client for the BackupAndRestore feature of the thing.
+// The live messages of the thing are subscribed as well, so the upload requests of the feature are answered.
+func NewBackupAndRestoreClient(ctx context.Context, cfg *TestConfiguration,
+	thingID string) (*BackupAndRestoreClient, error) {
+	client, err := NewFeatureClient(ctx, cfg, thingID, BackupAndRestoreFeatureID)
+	if err != nil {
+		return nil, err
+	}
+	if err := client.subscribeForMessages(ctx); err != nil {
+		client.Close()
+		return nil, err
+	}
+	return &BackupAndRestoreClient{FeatureClient: client}, nil
+}
+
+// Backup backs up the data and waits for the backup to be uploaded. If the device requests the upload,
+// it is started with the upload options. The upload progress is reported to the optional callback.
+// A random correlation ID is used if empty. An error is returned along with the final upload status
+// if the backup fails or the upload does not succeed.
+func (client *BackupAndRestoreClient) Backup(ctx context.Context, correlationID string,
+	options *BackupAndRestoreOptions, progress func(*UploadStatus)) (*UploadStatus, error) {
+	correlationID = correlationIDOrRandom(correlationID)
+	action := &UploadAction{CorrelationID: correlationID, Options: options.ToMap()}
+	if _, err := client.ExecuteOperation(ctx, backupOperationBackup, action); err != nil {
+		return nil, err
+	}
+
+	var uploadOptions *UploadOptions
+	if options != nil {
+		uploadOptions = &options.UploadOptions
+	}
+	return awaitUpload(ctx, client.FeatureClient, correlationID, fixedUploadOptions(uploadOptions), progress,
+		func(envelope *protocol.Envelope) error {
+			status, ok := client.operationStatus(envelope, correlationID)
+			if ok && status.State == BackupFailed {
+				return fmt.Errorf("backup %s failed: %s", correlationID, status.Message)
+			}
+			return nil
+		})
+}
+
+// Trigger triggers the upload of the last backup and waits for the upload to finish.
+// The upload requested by the device is started with the upload options.
+func (client *BackupAndRestoreClient) Trigger(ctx context.Context, correlationID string,
+	options *UploadOptions, progress func(*UploadStatus)) (*UploadStatus, error) {
+	correlationID = correlationIDOrRandom(correlationID)
+	action := &UploadAction{CorrelationID: correlationID, Options: options.ToMap()}
+	if _, err := client.ExecuteOperation(ctx, uploadOperationTrigger, action); err != nil {
+		return nil, err
+	}
+	return awaitUpload(ctx, client.FeatureClient, correlationID, fixedUploadOptions(options), progress, nil)
+}
+
+// Restore downloads a backup with the options and restores the data. The progress is reported
+// to the optional callback. An error is returned along with the final status if the restore fails.
+func (client *BackupAndRestoreClient) Restore(ctx context.Context, correlationID string,
+	options *BackupAndRestoreOptions, progress func(*BackupAndRestoreStatus)) (*BackupAndRestoreStatus, error) {
+	if options == nil {
+		return nil, errors.New("restore options are not specified")
+	}
+	correlationID = correlationIDOrRandom(correlationID)
+	action := &UploadAction{CorrelationID: correlationID, Options: options.ToMap()}
+	if _, err := client.ExecuteOperation(ctx, backupOperationRestore, action); err != nil {
+		return nil, err
+	}
+
+	var last *BackupAndRestoreStatus
+	err := WatchWSMessages(ctx, client.ws, func(envelope *protocol.Envelope) (bool, error) {
+		status, ok := client.operationStatus(envelope, correlationID)
+		if !ok {
+			return false, nil
+		}
+		last = status
+		if progress != nil {
+			progress(status)
+		}
+		return status.State.IsFinal(), nil
+	})
+	if err != nil {
+		return last, fmt.Errorf("restore %s not finished: %v", correlationID, err)
+	}
+	if last.State != RestoreFinished {
+		return last, fmt.Errorf("restore %s finished with state %s: %s", correlationID, last.State, last.Message)
+	}
+	return last, nil
+}
+
+// LastOperation retrieves the status of the last backup or restore operation
+func (client *BackupAndRestoreClient) LastOperation(ctx context.Context) (*BackupAndRestoreStatus, error) {
+	status := &BackupAndRestoreStatus{}
+	if err := client.GetProperty(ctx, backupPropertyLastOperation, status); err != nil {
+		return nil, err
+	}
+	return status, nil
+}
+
+// LastUpload retrieves the status of the last backup upload
+func (client *BackupAndRestoreClient) LastUpload(ctx context.Context) (*UploadStatus, error) {
+	status := &UploadStatus{}
+	if err := client.GetProperty(ctx, uploadPropertyLastUpload, status); err != nil {
+		return nil, err
+	}
+	return status, nil
+}
+
+// operationStatus extracts the status of the operation with the correlation ID from a twin event
+func (client *BackupAndRestoreClient) operationStatus(envelope *protocol.Envelope,
+	correlationID string) (*BackupAndRestoreStatus, bool) {
+	value, ok := client.propertyFromEvent(envelope, backupPropertyLastOperation)
+	if !ok {
+		return nil, false
+	}
+	status := &BackupAndRestoreStatus{}
+	if err := Convert(value, status); err != nil || status.CorrelationID != correlationID {
+		return nil, false
+	}
+	return status, true
+}
@@ -0,0 +1,159 @@
+// Copyright (c) 2026 Contributors to the Eclipse Foundation
+//
+// See the NOTICE file(s) distributed with this work for additional
+// information regarding copyright ownership.
+//
+// This program and the accompanying materials are made available under the
+// terms of the Eclipse Public License 2.0 which is available at
+// https://www.eclipse.org/legal/epl-2.0, or the Apache License, Version 2.0
+// which is available at https://www.apache.org/licenses/LICENSE-2.0.
+//
+// SPDX-License-Identifier: EPL-2.0 OR Apache-2.0
+
+package util
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+
+	mochi "github.com/mochi-mqtt/server/v2"
+	"github.com/mochi-mqtt/server/v2/hooks/auth"
+	"github.com/mochi-mqtt/server/v2/listeners"
+	"github.com/mochi-mqtt/server/v2/packets"
+)
+
+const localBrokerListenerID = "local-broker"
+
+// LocalBroker is an in-process MQTT broker, which allows running tests without a real local broker.
+// It accepts all clients and supports both MQTT 3.1.1 and MQTT 5.
+type LocalBroker struct {
+	server   *mochi.Server
+	listener *listeners.TCP
+
+	subscriptionID int32
+}
+
+// StartLocalBroker starts an in-process MQTT broker listening on a random port of the loopback interface
+func StartLocalBroker() (*LocalBroker, error) {
+	server := mochi.New(&mochi.Options{
+		InlineClient: true,
+		Logger:       slog.New(slog.NewTextHandler(io.Discard, nil)),
+	})
+	if err := server.AddHook(new(auth.AllowHook), nil); err != nil {
+		return nil, err
+	}
+
+	listener := listeners.NewTCP(listeners.Config{ID: localBrokerListenerID, Address: "127.0.0.1:0"})
+	if err := server.AddListener(listener); err != nil {
+		return nil, err
+	}
+	if err := server.Serve(); err != nil {
+		server.Close()
+		return nil, err
+	}
+	return &LocalBroker{server: server, listener: listener}, nil
+}
+
+// URL returns the broker address to be used as local broker in the test configuration
+func (broker *LocalBroker) URL() string {
+	return "tcp://" + broker.listener.Address()
+}
+
+// Publish publishes a message to all subscribers of the topic on behalf of the broker itself
+func (broker *LocalBroker) Publish(topic string, payload []byte, qos byte) error {
+	return broker.server.Publish(topic, payload, false, qos)
+}
+
+// Subscribe registers a handler for all messages published on topics matching the filter.
+// The handler is called synchronously by the broker and must not block.
+// The returned function removes the subscription.
+func (broker *LocalBroker) Subscribe(filter string, handler func(topic string, payload []byte)) (func(), error) {
+	id := int(atomic.AddInt32(&broker.subscriptionID, 1))
+	err := broker.server.Subscribe(filter, id, func(cl *mochi.Client, sub packets.Subscription, pk packets.Packet) {
+		handler(pk.TopicName, pk.Payload)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return func() {
+		broker.server.Unsubscribe(filter, id)
+	}, nil
+}
+
+// Close stops the broker and disconnects all clients
+func (broker *LocalBroker) Close() error {
+	return broker.server.Close()
+}
+
+// EdgeThingResponder answers thing configuration requests on behalf of the suite connector
+type EdgeThingResponder struct {
+	broker *LocalBroker
+
+	mutex    sync.Mutex
+	thingCfg *ThingConfiguration
+
+	unsubscribe func()
+}
+
+// StartEdgeThingResponder starts answering the thing configuration requests with the given configuration.
+// MQTT 5 requests are answered on their response topic with their correlation data.
+func (broker *LocalBroker) StartEdgeThingResponder(thingCfg *ThingConfiguration) (*EdgeThingResponder, error) {
+	responder := &EdgeThingResponder{broker: broker, thingCfg: thingCfg}
+
+	id := int(atomic.AddInt32(&broker.subscriptionID, 1))
+	err := broker.server.Subscribe(topicThingCfgRequest, id,
+		func(cl *mochi.Client, sub packets.Subscription, pk packets.Packet) {
+			responder.respond(pk.Properties.ResponseTopic, pk.Properties.CorrelationData)
+		})
+	if err != nil {
+		return nil, err
+	}
+	responder.unsubscribe = func() {
+		broker.server.Unsubscribe(topicThingCfgRequest, id)
+	}
+	return responder, nil
+}
+
+// SetThingConfiguration changes the thing configuration and publishes it as if the connector has reconnected
+func (responder *EdgeThingResponder) SetThingConfiguration(thingCfg *ThingConfiguration) error {
+	responder.mutex.Lock()
+	responder.thingCfg = thingCfg
+	responder.mutex.Unlock()
+
+	return responder.respond("", nil)
+}
+
+// Stop stops answering the thing configuration requests
+func (responder *EdgeThingResponder) Stop() {
+	responder.unsubscribe()
+}
+
+func (responder *EdgeThingResponder) respond(responseTopic string, correlationData []byte) error {
+	responder.mutex.Lock()
+	payload, err := json.Marshal(responder.thingCfg)
+	responder.mutex.Unlock()
+	if err != nil {
+		return err
+	}
+
+	if responseTopic == "" {
+		responseTopic = topicThingCfgResponse
+	}
+
+	server := responder.broker.server
+	inlineClient, ok := server.Clients.Get(mochi.InlineClientId)
+	if !ok {
+		return errors.New("inline client of the local broker not available")
+	}
+	return server.InjectPacket(inlineClient, packets.Packet{
+		FixedHeader: packets.FixedHeader{Type: packets.Publish, Qos: 1},
+		TopicName:   responseTopic,
+		Payload:     payload,
+		PacketID:    1,
+		Properties:  packets.Properties{CorrelationData: correlationData},
+	})
+}
@@ -0,0 +1,105 @@
+// Copyright (c) 2026 Contributors to the Eclipse Foundation
+//
+// See the NOTICE file(s) distributed with this work for additional
+// information regarding copyright ownership.
+//
+// This program and the accompanying materials are made available under the
+// terms of the Eclipse Public License 2.0 which is available at
+// https://www.eclipse.org/legal/epl-2.0, or the Apache License, Version 2.0
+// which is available at https://www.apache.org/licenses/LICENSE-2.0.
+//
+// SPDX-License-Identifier: EPL-2.0 OR Apache-2.0
+
+package util
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/eclipse/ditto-clients-golang"
+	"github.com/eclipse/ditto-clients-golang/protocol"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLocalBrokerThingConfiguration(t *testing.T) {
+	broker, cfg := startTestBroker(t)
+	expected := &ThingConfiguration{DeviceID: "test:device", TenantID: "test", PolicyID: "test:policy"}
+	responder, err := broker.StartEdgeThingResponder(expected)
+	require.NoError(t, err)
+	defer responder.Stop()
+
+	ctx, cancel := NewTestContext(t)
+	defer cancel()
+
+	client, err := NewMQTTClient(ctx, cfg)
+	require.NoError(t, err)
+	defer client.Disconnect(uint(cfg.MQTTQuiesceMS))
+
+	thingCfg, err := GetThingConfiguration(ctx, cfg, client)
+	require.NoError(t, err)
+	require.Equal(t, expected, thingCfg)
+
+	client5, err := NewMQTT5Client(ctx, cfg)
+	require.NoError(t, err)
+	defer DisconnectMQTT5Client(client5)
+
+	thingCfg, err = GetThingConfigurationMQTT5(ctx, cfg, client5)
+	require.NoError(t, err)
+	require.Equal(t, expected, thingCfg)
+}
+
+func TestLocalBrokerDittoClient(t *testing.T) {
+	broker, cfg := startTestBroker(t)
+
+	ctx, cancel := NewTestContext(t)
+	defer cancel()
+
+	client, err := NewMQTTClient(ctx, cfg)
+	require.NoError(t, err)
+	defer client.Disconnect(uint(cfg.MQTTQuiesceMS))
+
+	dittoClient, err := ditto.NewClientMQTT(client, ditto.NewConfiguration())
+	require.NoError(t, err)
+	require.NoError(t, dittoClient.Connect())
+	defer dittoClient.Disconnect()
+
+	commands := make(chan *protocol.Envelope, 1)
+	dittoClient.Subscribe(func(requestID string, message *protocol.Envelope) {
+		commands <- message
+	})
+
+	events := make(chan *protocol.Envelope, 1)
+	unsubscribe, err := broker.Subscribe(GetEventTopic("", ""), func(topic string, payload []byte) {
+		envelope := &protocol.Envelope{}
+		if json.Unmarshal(payload, envelope) == nil {
+			events <- envelope
+		}
+	})
+	require.NoError(t, err)
+	defer unsubscribe()
+
+	topic := (&protocol.Topic{}).WithNamespace("test").WithEntityName("device").WithGroup(protocol.GroupThings).
+		WithChannel(protocol.ChannelTwin).WithCriterion(protocol.CriterionCommands).WithAction(protocol.ActionModify)
+	event := (&protocol.Envelope{}).WithTopic(topic).WithPath("/attributes/test").WithValue("sent")
+	require.NoError(t, dittoClient.Send(event))
+	select {
+	case received := <-events:
+		require.Equal(t, "/attributes/test", received.Path)
+		require.Equal(t, "sent", received.Value)
+	case <-time.After(3 * time.Second):
+		require.Fail(t, "event of the Ditto client not received by the broker")
+	}
+
+	command := (&protocol.Envelope{}).WithTopic(topic).WithPath("/attributes/test").WithValue("received")
+	payload, err := json.Marshal(command)
+	require.NoError(t, err)
+	// The Ditto client only subscribes for the commands of the connected device itself
+	require.NoError(t, broker.Publish(GetCommandRequestTopic("", "request-1", "modify"), payload, 1))
+	select {
+	case received := <-commands:
+		require.Equal(t, "received", received.Value)
+	case <-time.After(3 * time.Second):
+		require.Fail(t, "command not received by the Ditto client")
+	}
+}
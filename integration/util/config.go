@@ -13,12 +13,23 @@
 package util
 
 import (
+	"fmt"
+	"math"
 	"time"
 )
 
 // TestConfiguration is a common integration test configuration
 type TestConfiguration struct {
 	LocalBroker              string `env:"LOCAL_BROKER" envDefault:"tcp://localhost:1883"`
+	LocalBrokerUsername      string `env:"LOCAL_BROKER_USERNAME" envDefault:""`
+	LocalBrokerPassword      string `env:"LOCAL_BROKER_PASSWORD" envDefault:""`
+	LocalBrokerCACert        string `env:"LOCAL_BROKER_CA_CERT" envDefault:""`
+	LocalBrokerCert          string `env:"LOCAL_BROKER_CERT" envDefault:""`
+	LocalBrokerKey           string `env:"LOCAL_BROKER_KEY" envDefault:""`
+	MQTTVersion              string `env:"MQTT_VERSION" envDefault:"3.1.1"`
+	MQTTClientID             string `env:"MQTT_CLIENT_ID" envDefault:""`
+	MQTTCleanSession         bool   `env:"MQTT_CLEAN_SESSION" envDefault:"true"`
+	MQTTKeepAliveMS          int    `env:"MQTT_KEEP_ALIVE_MS" envDefault:"20000"`
 	MQTTQuiesceMS            int    `env:"MQTT_QUIESCE_MS" envDefault:"500"`
 	MQTTAcknowledgeTimeoutMS int    `env:"MQTT_ACKNOWLEDGE_TIMEOUT_MS" envDefault:"3000"`
 	MQTTConnectMS            int    `env:"MQTT_CONNECT_TIMEOUT_MS" envDefault:"30000"`
@@ -27,7 +38,69 @@ type TestConfiguration struct {
 	DigitalTwinAPIUsername string `env:"DIGITAL_TWIN_API_USERNAME" envDefault:"ditto"`
 	DigitalTwinAPIPassword string `env:"DIGITAL_TWIN_API_PASSWORD" envDefault:"ditto"`
 
+	// LocalDigitalTwins selects the local digital twins of the device instead of Ditto for the twin access of the suite
+	LocalDigitalTwins bool `env:"LOCAL_DIGITAL_TWINS" envDefault:"false"`
+
 	WSEventTimeoutMS int `env:"WS_EVENT_TIMEOUT_MS" envDefault:"30000"`
+
+	// TrafficRecordDir enables recording of the MQTT, HTTP and WebSocket traffic to JSONL files in the directory
+	TrafficRecordDir string `env:"TRAFFIC_RECORD_DIR" envDefault:""`
+
+	SuiteConnectorLogFile string `env:"SUITE_CONNECTOR_LOG_FILE" envDefault:"/var/log/suite-connector/suite-connector.log"`
+
+	// TestReportDir enables writing a JSON report with the environment fingerprint of each suite to the directory
+	TestReportDir string `env:"TEST_REPORT_DIR" envDefault:""`
+	// TestReportJUnit enables writing a JUnit XML report next to the JSON report
+	TestReportJUnit bool `env:"TEST_REPORT_JUNIT" envDefault:"false"`
+}
+
+// Validate checks the values of the configuration, which are not checked when parsing the environment
+func (cfg *TestConfiguration) Validate() error {
+	if cfg.MQTTVersion != MQTTVersion311 && cfg.MQTTVersion != MQTTVersion5 {
+		return fmt.Errorf("unsupported MQTT version %s", cfg.MQTTVersion)
+	}
+	if _, err := cfg.mqttKeepAlive(); err != nil {
+		return err
+	}
+	return nil
+}
+
+// mqttKeepAlive returns the MQTT keep alive in seconds, rounded up to the next second.
+// MQTT carries it as a two-byte number of seconds, where zero turns the keep alive off.
+func (cfg *TestConfiguration) mqttKeepAlive() (uint16, error) {
+	keepAlive := MillisToDuration(cfg.MQTTKeepAliveMS)
+	if keepAlive < 0 || (keepAlive > 0 && keepAlive < time.Second) || keepAlive > math.MaxUint16*time.Second {
+		return 0, fmt.Errorf("MQTT keep alive %v must be 0 or between 1s and %v",
+			keepAlive, math.MaxUint16*time.Second)
+	}
+	return uint16((keepAlive + time.Second - 1) / time.Second), nil
+}
+
+// DeviceRegistryConfiguration is the device registry configuration needed to create additional devices
+type DeviceRegistryConfiguration struct {
+	DeviceRegistryAPIAddress  string `env:"DEVICE_REGISTRY_API_ADDRESS"`
+	DeviceRegistryAPIUsername string `env:"DEVICE_REGISTRY_API_USERNAME" envDefault:"ditto"`
+	DeviceRegistryAPIPassword string `env:"DEVICE_REGISTRY_API_PASSWORD" envDefault:"ditto"`
+}
+
+// HonoConfiguration is the Hono northbound messaging configuration needed to consume device messages
+// and to send commands without Ditto
+type HonoConfiguration struct {
+	// HonoMessagingType selects the messaging network of the tenant, either amqp or kafka
+	HonoMessagingType string `env:"HONO_MESSAGING_TYPE" envDefault:"amqp"`
+
+	HonoAMQPAddress  string `env:"HONO_AMQP_ADDRESS" envDefault:""`
+	HonoAMQPUsername string `env:"HONO_AMQP_USERNAME" envDefault:"consumer@HONO"`
+	HonoAMQPPassword string `env:"HONO_AMQP_PASSWORD" envDefault:"verysecret"`
+	HonoAMQPCACert   string `env:"HONO_AMQP_CA_CERT" envDefault:""`
+
+	// HonoKafkaBrokers is a comma separated list of bootstrap servers
+	HonoKafkaBrokers       string `env:"HONO_KAFKA_BROKERS" envDefault:""`
+	HonoKafkaUsername      string `env:"HONO_KAFKA_USERNAME" envDefault:""`
+	HonoKafkaPassword      string `env:"HONO_KAFKA_PASSWORD" envDefault:""`
+	HonoKafkaSASLMechanism string `env:"HONO_KAFKA_SASL_MECHANISM" envDefault:"SCRAM-SHA-512"`
+	HonoKafkaTLS           bool   `env:"HONO_KAFKA_TLS" envDefault:"false"`
+	HonoKafkaCACert        string `env:"HONO_KAFKA_CA_CERT" envDefault:""`
 }
 
 // MillisToDuration converts milliseconds to Duration
@@ -0,0 +1,58 @@
+// Copyright (c) 2026 Contributors to the Eclipse Foundation
+//
+// See the NOTICE file(s) distributed with this work for additional
+// information regarding copyright ownership.
+//
+// This program and the accompanying materials are made available under the
+// terms of the Eclipse Public License 2.0 which is available at
+// https://www.eclipse.org/legal/epl-2.0, or the Apache License, Version 2.0
+// which is available at https://www.apache.org/licenses/LICENSE-2.0.
+//
+// SPDX-License-Identifier: EPL-2.0 OR Apache-2.0
+
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTestConfigurationMQTTKeepAlive(t *testing.T) {
+	tests := []struct {
+		name        string
+		keepAliveMS int
+		expected    uint16
+		valid       bool
+	}{
+		{name: "disabled", keepAliveMS: 0, expected: 0, valid: true},
+		{name: "one second", keepAliveMS: 1000, expected: 1, valid: true},
+		{name: "rounded up", keepAliveMS: 1500, expected: 2, valid: true},
+		{name: "default", keepAliveMS: 20000, expected: 20, valid: true},
+		{name: "maximum", keepAliveMS: 65535000, expected: 65535, valid: true},
+		{name: "below one second", keepAliveMS: 999},
+		{name: "above maximum", keepAliveMS: 65535001},
+		{name: "negative", keepAliveMS: -1000},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cfg := &TestConfiguration{MQTTVersion: MQTTVersion5, MQTTKeepAliveMS: test.keepAliveMS}
+			keepAlive, err := cfg.mqttKeepAlive()
+			if !test.valid {
+				require.Error(t, err)
+				require.Error(t, cfg.Validate())
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, test.expected, keepAlive)
+			require.NoError(t, cfg.Validate())
+		})
+	}
+}
+
+func TestTestConfigurationValidateMQTTVersion(t *testing.T) {
+	for _, version := range []string{MQTTVersion311, MQTTVersion5} {
+		require.NoError(t, (&TestConfiguration{MQTTVersion: version}).Validate())
+	}
+	require.EqualError(t, (&TestConfiguration{MQTTVersion: "3"}).Validate(), "unsupported MQTT version 3")
+}
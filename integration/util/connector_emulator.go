@@ -0,0 +1,169 @@
+// Copyright (c) 2026 Contributors to the Eclipse Foundation
+//
+// See the NOTICE file(s) distributed with this work for additional
+// information regarding copyright ownership.
+//
+// This program and the accompanying materials are made available under the
+// terms of the Eclipse Public License 2.0 which is available at
+// https://www.eclipse.org/legal/epl-2.0, or the Apache License, Version 2.0
+// which is available at https://www.apache.org/licenses/LICENSE-2.0.
+//
+// SPDX-License-Identifier: EPL-2.0 OR Apache-2.0
+
+package util
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/eclipse/ditto-clients-golang/protocol"
+	"github.com/google/uuid"
+
+	MQTT "github.com/eclipse/paho.mqtt.golang"
+)
+
+// connectorEmulatorDeviceTopics match the device topics with and without tenant and device IDs
+var connectorEmulatorDeviceTopics = []string{
+	topicEventShort + "/#", string(KindEvent) + "/#", topicTelemetryShort + "/#", string(KindTelemetry) + "/#",
+}
+
+// ConnectorEmulator emulates the suite connector between the local broker and the fake Ditto,
+// which allows running full cloud round trip tests without a Hono and Ditto cluster.
+// Device events and telemetry are processed by the fake Ditto, i.e. twin commands are applied
+// and live messages are delivered. Live messages sent to things are forwarded to the devices as
+// command requests and the command responses are returned to the fake Ditto.
+// The emulator also answers the thing configuration requests.
+type ConnectorEmulator struct {
+	cfg      *TestConfiguration
+	client   MQTT.Client
+	ditto    *FakeDitto
+	thingCfg *ThingConfiguration
+
+	mutex   sync.Mutex
+	pending map[string]chan *protocol.Envelope
+}
+
+// StartConnectorEmulator connects to the local broker from the test configuration
+// and starts bridging it to the fake Ditto on behalf of the configured thing
+func StartConnectorEmulator(ctx context.Context, cfg *TestConfiguration, ditto *FakeDitto,
+	thingCfg *ThingConfiguration) (*ConnectorEmulator, error) {
+	client, err := NewMQTTClient(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	emulator := &ConnectorEmulator{
+		cfg:      cfg,
+		client:   client,
+		ditto:    ditto,
+		thingCfg: thingCfg,
+		pending:  map[string]chan *protocol.Envelope{},
+	}
+
+	filters := map[string]byte{
+		topicThingCfgRequest:        1,
+		CommandResponsesTopicFilter: 1,
+	}
+	for _, topic := range connectorEmulatorDeviceTopics {
+		filters[topic] = 1
+	}
+	subscribeCtx, cancel := context.WithTimeout(ctx, MillisToDuration(cfg.MQTTAcknowledgeTimeoutMS))
+	defer cancel()
+	if err := waitForToken(subscribeCtx, client.SubscribeMultiple(filters, emulator.handle)); err != nil {
+		client.Disconnect(uint(cfg.MQTTQuiesceMS))
+		return nil, fmt.Errorf("unable to subscribe to device topics: %v", err)
+	}
+
+	ditto.HandleLiveMessages(emulator.forwardLiveMessage)
+	return emulator, nil
+}
+
+// Stop stops the bridging and disconnects from the local broker
+func (emulator *ConnectorEmulator) Stop() {
+	emulator.ditto.HandleLiveMessages(nil)
+	emulator.client.Disconnect(uint(emulator.cfg.MQTTQuiesceMS))
+}
+
+func (emulator *ConnectorEmulator) handle(client MQTT.Client, message MQTT.Message) {
+	topic := message.Topic()
+	if topic == topicThingCfgRequest {
+		go SendMQTTMessage(context.Background(), emulator.cfg, client, topicThingCfgResponse, emulator.thingCfg)
+		return
+	}
+
+	envelope := &protocol.Envelope{}
+	if err := json.Unmarshal(message.Payload(), envelope); err != nil || envelope.Topic == nil {
+		return
+	}
+
+	if commandTopic, err := ParseCommandTopic(topic); err == nil {
+		if !commandTopic.Request {
+			emulator.resolve(commandTopic, envelope)
+		}
+		return
+	}
+
+	if _, err := ParseDeviceTopic(topic); err == nil {
+		// Processing may wait for live responses, it must not block the client
+		go emulator.ditto.ProcessEnvelope(envelope)
+	}
+}
+
+func (emulator *ConnectorEmulator) resolve(commandTopic *CommandTopic, response *protocol.Envelope) {
+	if response.Status == 0 {
+		response.Status = commandTopic.Status
+	}
+
+	emulator.mutex.Lock()
+	ch, ok := emulator.pending[commandTopic.RequestID]
+	emulator.mutex.Unlock()
+	if ok {
+		select {
+		case ch <- response:
+		default:
+		}
+	}
+}
+
+func (emulator *ConnectorEmulator) forwardLiveMessage(message *protocol.Envelope) *protocol.Envelope {
+	thingID := message.Topic.Namespace + ":" + message.Topic.EntityName
+	requestID := uuid.New().String()
+
+	responseRequired := true
+	timeout := fakeDittoDefaultTimeout
+	if message.Headers != nil {
+		responseRequired = message.Headers.Values[protocol.HeaderResponseRequired] != false
+		timeout = parseFakeDittoTimeout(message.Headers.Timeout())
+	}
+
+	ch := make(chan *protocol.Envelope, 1)
+	if responseRequired {
+		emulator.mutex.Lock()
+		emulator.pending[requestID] = ch
+		emulator.mutex.Unlock()
+
+		defer func() {
+			emulator.mutex.Lock()
+			delete(emulator.pending, requestID)
+			emulator.mutex.Unlock()
+		}()
+	} else {
+		requestID = ""
+	}
+
+	err := PublishCommandRequest(context.Background(), emulator.cfg, emulator.client, thingID, requestID,
+		string(message.Topic.Action), message)
+	if err != nil || !responseRequired {
+		return nil
+	}
+
+	select {
+	case response := <-ch:
+		return response
+	case <-time.After(timeout):
+		return nil
+	}
+}
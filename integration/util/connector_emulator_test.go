@@ -0,0 +1,109 @@
+// Copyright (c) 2026 Contributors to the Eclipse Foundation
+//
+// See the NOTICE file(s) distributed with this work for additional
+// information regarding copyright ownership.
+//
+// This program and the accompanying materials are made available under the
+// terms of the Eclipse Public License 2.0 which is available at
+// https://www.eclipse.org/legal/epl-2.0, or the Apache License, Version 2.0
+// which is available at https://www.apache.org/licenses/LICENSE-2.0.
+//
+// SPDX-License-Identifier: EPL-2.0 OR Apache-2.0
+
+package util
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/eclipse/ditto-clients-golang/protocol"
+	"github.com/stretchr/testify/require"
+)
+
+// startTestEmulator starts a local broker, a fake Ditto with an existing test thing and a connector emulator
+// bridging them, and returns a test configuration connecting to both
+func startTestEmulator(t *testing.T) (*LocalBroker, *TestConfiguration) {
+	broker, cfg := startTestBroker(t)
+	ditto, dittoCfg := startTestDitto(t)
+	cfg.DigitalTwinAPIAddress = dittoCfg.DigitalTwinAPIAddress
+	cfg.DigitalTwinAPIUsername = dittoCfg.DigitalTwinAPIUsername
+	cfg.DigitalTwinAPIPassword = dittoCfg.DigitalTwinAPIPassword
+
+	ctx, cancel := NewTestContext(t)
+	defer cancel()
+	createTestThing(ctx, t, cfg)
+
+	emulator, err := StartConnectorEmulator(ctx, cfg, ditto, &ThingConfiguration{DeviceID: testThingID, TenantID: "test"})
+	require.NoError(t, err)
+	t.Cleanup(emulator.Stop)
+	return broker, cfg
+}
+
+func TestConnectorEmulatorDeviceEvent(t *testing.T) {
+	broker, cfg := startTestEmulator(t)
+	ctx, cancel := NewTestContext(t)
+	defer cancel()
+	ws := subscribeForTestThingEvents(ctx, t, cfg, testThingID)
+
+	command := (&protocol.Envelope{}).
+		WithTopic(newThingTopic(testThingID, protocol.ChannelTwin, protocol.CriterionCommands, protocol.ActionModify)).
+		WithHeaders(protocol.NewHeaders(protocol.WithCorrelationID("device-command"))).
+		WithPath("/attributes/location").
+		WithValue("lab")
+	payload, err := json.Marshal(command)
+	require.NoError(t, err)
+	require.NoError(t, broker.Publish(GetEventTopic("test", testThingID), payload, 1))
+
+	err = ProcessWSMessages(ctx, cfg, ws, func(event *protocol.Envelope) (bool, error) {
+		if event.Topic.String() != GetTwinEventTopic(testThingID, protocol.ActionCreated) {
+			return false, nil
+		}
+		if event.Path != "/attributes/location" || event.Value != "lab" {
+			return true, fmt.Errorf("unexpected event %s: %v", event.Path, event.Value)
+		}
+		return true, nil
+	})
+	require.NoError(t, err)
+}
+
+func TestConnectorEmulatorLiveMessage(t *testing.T) {
+	broker, cfg := startTestEmulator(t)
+	ctx, cancel := NewTestContext(t)
+	defer cancel()
+
+	requests := make(chan string, 1)
+	unsubscribe, err := broker.Subscribe(CommandRequestsTopicFilter, func(topic string, payload []byte) {
+		requests <- topic
+		commandTopic, err := ParseCommandTopic(topic)
+		if err != nil {
+			return
+		}
+		request := &protocol.Envelope{}
+		if err := json.Unmarshal(payload, request); err != nil {
+			return
+		}
+		response := (&protocol.Envelope{}).
+			WithTopic(request.Topic).
+			WithHeaders(request.Headers).
+			WithPath(strings.Replace(request.Path, "/inbox/", "/outbox/", 1)).
+			WithValue(map[string]interface{}{"echo": request.Value}).
+			WithStatus(http.StatusOK)
+		data, _ := json.Marshal(response)
+		// The broker calls the handler synchronously, so the response is published asynchronously
+		go broker.Publish(GetCommandResponseTopic(commandTopic.DeviceID, commandTopic.RequestID, http.StatusOK), data, 1)
+	})
+	require.NoError(t, err)
+	defer unsubscribe()
+
+	featureURL := GetFeatureURL(GetThingURL(cfg.DigitalTwinAPIAddress, testThingID), "test")
+	body, err := ExecuteOperation(ctx, cfg, featureURL, "echo", "hello")
+	require.NoError(t, err)
+	require.JSONEq(t, `{"echo":"hello"}`, string(body))
+
+	topic := <-requests
+	require.True(t, strings.HasPrefix(topic, "command//"+testThingID+"/req/"), "unexpected command topic %s", topic)
+	require.True(t, strings.HasSuffix(topic, "/echo"), "unexpected command topic %s", topic)
+}
@@ -0,0 +1,386 @@
+// Copyright (c) 2026 Contributors to the Eclipse Foundation
+//
+// See the NOTICE file(s) distributed with this work for additional
+// information regarding copyright ownership.
+//
+// This program and the accompanying materials are made available under the
+// terms of the Eclipse Public License 2.0 which is available at
+// https://www.eclipse.org/legal/epl-2.0, or the Apache License, Version 2.0
+// which is available at https://www.apache.org/licenses/LICENSE-2.0.
+//
+// SPDX-License-Identifier: EPL-2.0 OR Apache-2.0
+
+package util
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/eclipse/ditto-clients-golang/protocol"
+)
+
+// ContainerStatus is the status of a container
+type ContainerStatus string
+
+const (
+	// ContainersThingSuffix is appended to the device ID to get the ID of the thing, which has the container features
+	ContainersThingSuffix = ":edge:containers"
+	// ContainerFactoryFeatureID is the ID of the feature, which creates the containers
+	ContainerFactoryFeatureID = "ContainerFactory"
+	// ContainerFeaturePrefix is the prefix of the IDs of the features, which represent the containers
+	ContainerFeaturePrefix = "Container:"
+
+	// ContainerStatusCreated is reported when a container is created, but not started.
+	ContainerStatusCreated ContainerStatus = "CREATED"
+	// ContainerStatusRunning is reported while a container is running.
+	ContainerStatusRunning ContainerStatus = "RUNNING"
+	// ContainerStatusPaused is reported while a container is paused.
+	ContainerStatusPaused ContainerStatus = "PAUSED"
+	// ContainerStatusStopped is reported when a container is stopped.
+	ContainerStatusStopped ContainerStatus = "STOPPED"
+	// ContainerStatusExited is reported when the process of a container exits.
+	ContainerStatusExited ContainerStatus = "EXITED"
+	// ContainerStatusDead is reported when a container cannot be recovered.
+	ContainerStatusDead ContainerStatus = "DEAD"
+	// ContainerStatusUnknown is reported when the status of a container cannot be determined.
+	ContainerStatusUnknown ContainerStatus = "UNKNOWN"
+
+	// ContainerRestartNo never restarts a container
+	ContainerRestartNo = "no"
+	// ContainerRestartAlways always restarts a container
+	ContainerRestartAlways = "always"
+	// ContainerRestartUnlessStopped restarts a container unless it is stopped
+	ContainerRestartUnlessStopped = "unless-stopped"
+	// ContainerRestartOnFailure restarts a container if it exits with a failure
+	ContainerRestartOnFailure = "on-failure"
+
+	containerOperationCreate           = "create"
+	containerOperationCreateWithConfig = "createWithConfig"
+	containerOperationStart            = "start"
+	containerOperationStop             = "stop"
+	containerOperationStopWithOptions  = "stopWithOptions"
+	containerOperationPause            = "pause"
+	containerOperationResume           = "resume"
+	containerOperationRename           = "rename"
+	containerOperationUpdate           = "update"
+	containerOperationRemove           = "remove"
+
+	containerPropertyStatus = "status"
+	containerPropertyState  = "status/state"
+)
+
+// ContainerMountPoint mounts a file or directory of the host in a container
+type ContainerMountPoint struct {
+	Source          string `json:"source"`
+	Destination     string `json:"destination"`
+	PropagationMode string `json:"propagationMode,omitempty"`
+}
+
+// ContainerDecryption holds the keys and recipients used to decrypt the image of a container
+type ContainerDecryption struct {
+	Keys       []string `json:"keys,omitempty"`
+	Recipients []string `json:"recipients,omitempty"`
+}
+
+// ContainerDevice makes a device of the host accessible in a container
+type ContainerDevice struct {
+	PathOnHost        string `json:"pathOnHost"`
+	PathInContainer   string `json:"pathInContainer"`
+	CgroupPermissions string `json:"cgroupPermissions,omitempty"`
+}
+
+// ContainerRestartPolicy is the restart policy of a container, which is applied when the container exits
+type ContainerRestartPolicy struct {
+	Type          string `json:"type"`
+	MaxRetryCount int    `json:"maxRetryCount,omitempty"`
+	// RetryTimeout is the timeout in seconds of each restart, if the type is on-failure
+	RetryTimeout int64 `json:"retryTimeout,omitempty"`
+}
+
+// ContainerPortMapping maps a range of host ports to a container port
+type ContainerPortMapping struct {
+	Proto         string `json:"proto,omitempty"`
+	ContainerPort int    `json:"containerPort"`
+	HostIP        string `json:"hostIP,omitempty"`
+	HostPort      int    `json:"hostPort"`
+	HostPortEnd   int    `json:"hostPortEnd,omitempty"`
+}
+
+// ContainerLogConfig configures the logging of a container
+type ContainerLogConfig struct {
+	Type          string `json:"type,omitempty"`
+	MaxFiles      int    `json:"maxFiles,omitempty"`
+	MaxSize       string `json:"maxSize,omitempty"`
+	RootDir       string `json:"rootDir,omitempty"`
+	Mode          string `json:"mode,omitempty"`
+	MaxBufferSize string `json:"maxBufferSize,omitempty"`
+}
+
+// ContainerResources limits the memory of a container, the values are numbers with a unit suffix, e.g. "500M"
+type ContainerResources struct {
+	Memory            string `json:"memory,omitempty"`
+	MemoryReservation string `json:"memoryReservation,omitempty"`
+	MemorySwap        string `json:"memorySwap,omitempty"`
+}
+
+// ContainerConfig is the configuration of a container
+type ContainerConfig struct {
+	DomainName        string                  `json:"domainName,omitempty"`
+	HostName          string                  `json:"hostName,omitempty"`
+	Env               []string                `json:"env,omitempty"`
+	Cmd               []string                `json:"cmd,omitempty"`
+	Privileged        bool                    `json:"privileged,omitempty"`
+	ExtraHosts        []string                `json:"extraHosts,omitempty"`
+	ExtraCapabilities []string                `json:"extraCapabilities,omitempty"`
+	NetworkMode       string                  `json:"networkMode,omitempty"`
+	OpenStdin         bool                    `json:"openStdin,omitempty"`
+	Tty               bool                    `json:"tty,omitempty"`
+	MountPoints       []*ContainerMountPoint  `json:"mountPoints,omitempty"`
+	Decryption        *ContainerDecryption    `json:"decryption,omitempty"`
+	Devices           []*ContainerDevice      `json:"devices,omitempty"`
+	RestartPolicy     *ContainerRestartPolicy `json:"restartPolicy,omitempty"`
+	PortMappings      []*ContainerPortMapping `json:"portMappings,omitempty"`
+	Log               *ContainerLogConfig     `json:"log,omitempty"`
+	Resources         *ContainerResources     `json:"resources,omitempty"`
+}
+
+// ContainerCreateRequest is the parameter of the create and createWithConfig operations
+type ContainerCreateRequest struct {
+	ImageRef string           `json:"imageRef"`
+	Start    bool             `json:"start"`
+	Config   *ContainerConfig `json:"config,omitempty"`
+}
+
+// ContainerStopOptions is the parameter of the stopWithOptions operation
+type ContainerStopOptions struct {
+	// Signal is the signal name or number, e.g. SIGTERM
+	Signal string `json:"signal,omitempty"`
+	// Timeout is the period in seconds to wait for the container to stop gracefully
+	Timeout int64 `json:"timeout,omitempty"`
+	Force   bool  `json:"force,omitempty"`
+}
+
+// ContainerUpdateRestartPolicy is the restart policy of the update operation
+type ContainerUpdateRestartPolicy struct {
+	Type          string `json:"type"`
+	MaxRetryCount int    `json:"maxRetryCount,omitempty"`
+	// Timeout is the timeout in seconds of each restart, if the type is on-failure
+	Timeout int64 `json:"timeout,omitempty"`
+}
+
+// ContainerUpdateOptions is the parameter of the update operation
+type ContainerUpdateOptions struct {
+	RestartPolicy *ContainerUpdateRestartPolicy `json:"restartPolicy,omitempty"`
+	Resources     *ContainerResources           `json:"resources,omitempty"`
+}
+
+// ContainerState is the runtime state of a container
+type ContainerState struct {
+	Status     ContainerStatus `json:"status"`
+	PID        int64           `json:"pid,omitempty"`
+	ExitCode   int64           `json:"exitCode,omitempty"`
+	Error      string          `json:"error,omitempty"`
+	StartedAt  string          `json:"startedAt,omitempty"`
+	FinishedAt string          `json:"finishedAt,omitempty"`
+	OOMKilled  bool            `json:"oomKilled,omitempty"`
+}
+
+// ContainerInfo is the status of a container feature
+type ContainerInfo struct {
+	Name      string           `json:"name"`
+	ImageRef  string           `json:"imageRef"`
+	Config    *ContainerConfig `json:"config,omitempty"`
+	CreatedAt string           `json:"createdAt,omitempty"`
+	State     *ContainerState  `json:"state,omitempty"`
+}
+
+// GetContainersThingID returns the ID of the thing, which has the container features of the device
+func GetContainersThingID(deviceID string) string {
+	return deviceID + ContainersThingSuffix
+}
+
+// GetContainerFeatureID returns the ID of the feature, which represents the container
+func GetContainerFeatureID(containerID string) string {
+	return ContainerFeaturePrefix + containerID
+}
+
+// ContainersClient creates containers through the ContainerFactory feature and manages them
+// through their container features
+type ContainersClient struct {
+	*FeatureClient
+}
+
+// NewContainersClient creates a client for the containers thing of the device
+func NewContainersClient(ctx context.Context, cfg *TestConfiguration, deviceID string) (*ContainersClient, error) {
+	client, err := NewFeatureClient(ctx, cfg, GetContainersThingID(deviceID), ContainerFactoryFeatureID)
+	if err != nil {
+		return nil, err
+	}
+	return &ContainersClient{FeatureClient: client}, nil
+}
+
+// Create creates a container from the image and returns its ID. The container is created with the default
+// configuration if the configuration is nil, and is started if requested.
+func (client *ContainersClient) Create(ctx context.Context, imageRef string, config *ContainerConfig,
+	start bool) (string, error) {
+	operation := containerOperationCreate
+	if config != nil {
+		operation = containerOperationCreateWithConfig
+	}
+	body, err := client.ExecuteOperation(ctx, operation,
+		&ContainerCreateRequest{ImageRef: imageRef, Start: start, Config: config})
+	if err != nil {
+		return "", err
+	}
+
+	var containerID string
+	if err := json.Unmarshal(body, &containerID); err != nil || containerID == "" {
+		return "", fmt.Errorf("invalid container ID in the response to %s: %s", operation, string(body))
+	}
+	return containerID, nil
+}
+
+// Containers retrieves the status of all containers keyed by container ID
+func (client *ContainersClient) Containers(ctx context.Context) (map[string]*ContainerInfo, error) {
+	thing, err := client.Thing.GetThing(ctx)
+	if err != nil {
+		return nil, err
+	}
+	features, _ := thing["features"].(map[string]interface{})
+
+	containers := map[string]*ContainerInfo{}
+	for featureID, feature := range features {
+		if !strings.HasPrefix(featureID, ContainerFeaturePrefix) {
+			continue
+		}
+		info := &ContainerInfo{}
+		object, _ := feature.(map[string]interface{})
+		if status, ok := getJSONPointer(object, []string{"properties", containerPropertyStatus}); ok {
+			if err := Convert(status, info); err != nil {
+				return nil, fmt.Errorf("invalid status of feature %s: %v", featureID, err)
+			}
+		}
+		containers[strings.TrimPrefix(featureID, ContainerFeaturePrefix)] = info
+	}
+	return containers, nil
+}
+
+// Container retrieves the status of a container
+func (client *ContainersClient) Container(ctx context.Context, containerID string) (*ContainerInfo, error) {
+	info := &ContainerInfo{}
+	if err := client.Thing.GetFeatureProperty(ctx, GetContainerFeatureID(containerID),
+		containerPropertyStatus, info); err != nil {
+		return nil, err
+	}
+	return info, nil
+}
+
+// Start starts a container
+func (client *ContainersClient) Start(ctx context.Context, containerID string) error {
+	return client.execute(ctx, containerID, containerOperationStart, nil)
+}
+
+// Stop stops a container
+func (client *ContainersClient) Stop(ctx context.Context, containerID string) error {
+	return client.execute(ctx, containerID, containerOperationStop, nil)
+}
+
+// StopWithOptions stops a container with a signal and a timeout
+func (client *ContainersClient) StopWithOptions(ctx context.Context, containerID string,
+	options *ContainerStopOptions) error {
+	return client.execute(ctx, containerID, containerOperationStopWithOptions, options)
+}
+
+// Pause pauses a running container
+func (client *ContainersClient) Pause(ctx context.Context, containerID string) error {
+	return client.execute(ctx, containerID, containerOperationPause, nil)
+}
+
+// Resume resumes a paused container
+func (client *ContainersClient) Resume(ctx context.Context, containerID string) error {
+	return client.execute(ctx, containerID, containerOperationResume, nil)
+}
+
+// Rename renames a container
+func (client *ContainersClient) Rename(ctx context.Context, containerID string, name string) error {
+	return client.execute(ctx, containerID, containerOperationRename, name)
+}
+
+// Update updates the restart policy and the resources of a container
+func (client *ContainersClient) Update(ctx context.Context, containerID string,
+	options *ContainerUpdateOptions) error {
+	return client.execute(ctx, containerID, containerOperationUpdate, options)
+}
+
+// Remove removes a container, a running container is stopped before if forced
+func (client *ContainersClient) Remove(ctx context.Context, containerID string, force bool) error {
+	return client.execute(ctx, containerID, containerOperationRemove, force)
+}
+
+// WaitForStatus waits until the container reaches the status or the context is done, as e.g. pulling the image
+// may take longer than the WebSocket event timeout. The current status is checked first, so a status reached
+// before the call is not missed.
+func (client *ContainersClient) WaitForStatus(ctx context.Context, containerID string,
+	status ContainerStatus) (*ContainerState, error) {
+	if info, err := client.Container(ctx, containerID); err == nil && info.State != nil && info.State.Status == status {
+		return info.State, nil
+	}
+
+	featureID := GetContainerFeatureID(containerID)
+	var last *ContainerState
+	err := WatchWSMessages(ctx, client.ws, func(envelope *protocol.Envelope) (bool, error) {
+		value, ok := featurePropertyFromEvent(envelope, client.Thing.ThingID, featureID, containerPropertyState)
+		if !ok {
+			return false, nil
+		}
+		state := &ContainerState{}
+		if err := Convert(value, state); err != nil {
+			return false, nil
+		}
+		last = state
+		return state.Status == status, nil
+	})
+	if err != nil {
+		if last != nil {
+			return last, fmt.Errorf("container %s not %s, last status %s: %v", containerID, status, last.Status, err)
+		}
+		return nil, fmt.Errorf("container %s not %s: %v", containerID, status, err)
+	}
+	return last, nil
+}
+
+// WaitForRemoval waits until the feature of the container is deleted or the context is done, as e.g. stopping
+// the container may take longer than the WebSocket event timeout
+func (client *ContainersClient) WaitForRemoval(ctx context.Context, containerID string) error {
+	containers, err := client.Containers(ctx)
+	if err != nil {
+		return err
+	}
+	if _, ok := containers[containerID]; !ok {
+		return nil
+	}
+
+	featurePath := GetFeatureURL("", GetContainerFeatureID(containerID))
+	err = WatchWSMessages(ctx, client.ws, func(envelope *protocol.Envelope) (bool, error) {
+		topic := envelope.Topic
+		return topic != nil && topic.Channel == protocol.ChannelTwin && topic.Criterion == protocol.CriterionEvents &&
+			topic.Action == protocol.ActionDeleted && topic.Namespace+":"+topic.EntityName == client.Thing.ThingID &&
+			strings.TrimSuffix(envelope.Path, "/") == featurePath, nil
+	})
+	if err != nil {
+		return fmt.Errorf("container %s not removed: %v", containerID, err)
+	}
+	return nil
+}
+
+func (client *ContainersClient) execute(ctx context.Context, containerID string, operation string,
+	params interface{}) error {
+	if containerID == "" {
+		return errors.New("container ID is not specified")
+	}
+	_, err := client.Thing.ExecuteOperation(ctx, GetContainerFeatureID(containerID), operation, params)
+	return err
+}
@@ -0,0 +1,899 @@
+// Copyright (c) 2026 Contributors to the Eclipse Foundation
+//
+// See the NOTICE file(s) distributed with this work for additional
+// information regarding copyright ownership.
+//
+// This program and the accompanying materials are made available under the
+// terms of the Eclipse Public License 2.0 which is available at
+// https://www.eclipse.org/legal/epl-2.0, or the Apache License, Version 2.0
+// which is available at https://www.apache.org/licenses/LICENSE-2.0.
+//
+// SPDX-License-Identifier: EPL-2.0 OR Apache-2.0
+
+package util
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/eclipse/ditto-clients-golang/protocol"
+	"golang.org/x/net/websocket"
+)
+
+const (
+	fakeDittoThingsPrefix = "/api/2/things/"
+	fakeDittoWSPath       = "/ws/2"
+
+	fakeDittoDefaultTimeout = 10 * time.Second
+
+	wsStartSendLiveCommands = "START-SEND-LIVE-COMMANDS"
+	wsStopSendLiveCommands  = "STOP-SEND-LIVE-COMMANDS"
+	wsStartSendLiveEvents   = "START-SEND-LIVE-EVENTS"
+	wsStopSendLiveEvents    = "STOP-SEND-LIVE-EVENTS"
+)
+
+var (
+	fakeDittoInboxPath = regexp.MustCompile("^(/features/[^/]+)?/inbox/messages/([^/]+)$")
+	fakeDittoFilter    = regexp.MustCompile(`^eq\(thingId,"([^"]+)"\)$`)
+)
+
+// LiveMessageHandler handles a live message sent to a thing and returns the response to it.
+// A nil response means that the message is not answered.
+type LiveMessageHandler func(message *protocol.Envelope) *protocol.Envelope
+
+// FakeDitto is an in-memory stand-in for the Ditto REST and WebSocket APIs, which allows running tests without a real Ditto.
+// The things are stored as plain JSON objects. Modifications of the things are published as twin events to the WebSocket
+// sessions, which have started sending events. Live messages are delivered to the WebSocket sessions, which have started
+// sending messages, and to the registered live message handler.
+// The acknowledgements requested by REST requests are fulfilled by the fake itself for the built-in labels and
+// by the WebSocket sessions, which have declared them, for the custom labels.
+// Only thing ID equality filters, e.g. eq(thingId,"namespace:name"), are supported for WebSocket subscriptions,
+// other filters are ignored.
+type FakeDitto struct {
+	server *httptest.Server
+
+	username string
+	password string
+
+	mutex     sync.Mutex
+	things    map[string]map[string]interface{}
+	revisions map[string]int64
+	sessions  map[*fakeDittoSession]bool
+	pending   map[string]chan *protocol.Envelope
+	acks      map[string]*fakeDittoAcks
+	handler   LiveMessageHandler
+}
+
+// fakeDittoSession is a WebSocket session. The messages to the session are queued and written by a separate
+// goroutine, so a session, which does not read its messages, does not block the fake Ditto.
+type fakeDittoSession struct {
+	conn         *websocket.Conn
+	declaredAcks map[string]bool
+
+	mutex    sync.Mutex
+	events   bool
+	messages bool
+	// the events and the live messages are subscribed with their own filters
+	eventsFilter   string
+	messagesFilter string
+	outbox         []string
+	queued         chan struct{}
+}
+
+type fakeDittoError struct {
+	Status  int    `json:"status"`
+	Error   string `json:"error"`
+	Message string `json:"message"`
+}
+
+// StartFakeDitto starts a fake Ditto, which accepts only the given basic authentication credentials
+func StartFakeDitto(username string, password string) *FakeDitto {
+	ditto := &FakeDitto{
+		username:  username,
+		password:  password,
+		things:    map[string]map[string]interface{}{},
+		revisions: map[string]int64{},
+		sessions:  map[*fakeDittoSession]bool{},
+		pending:   map[string]chan *protocol.Envelope{},
+		acks:      map[string]*fakeDittoAcks{},
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(fakeDittoThingsPrefix, ditto.handleThings)
+	mux.Handle(fakeDittoWSPath, websocket.Server{Handler: ditto.handleWS})
+
+	ditto.server = httptest.NewServer(ditto.authenticate(mux))
+	return ditto
+}
+
+// URL returns the address to be used as Digital Twin API address in the test configuration
+func (ditto *FakeDitto) URL() string {
+	return ditto.server.URL
+}
+
+// Close stops the fake Ditto and closes all WebSocket sessions
+func (ditto *FakeDitto) Close() {
+	ditto.mutex.Lock()
+	for session := range ditto.sessions {
+		session.conn.Close()
+	}
+	ditto.mutex.Unlock()
+	ditto.server.Close()
+}
+
+// HandleLiveMessages registers a handler, which is called for each live message sent to a thing
+func (ditto *FakeDitto) HandleLiveMessages(handler LiveMessageHandler) {
+	ditto.mutex.Lock()
+	defer ditto.mutex.Unlock()
+	ditto.handler = handler
+}
+
+// GetThing returns a copy of a stored thing
+func (ditto *FakeDitto) GetThing(thingID string) (map[string]interface{}, bool) {
+	ditto.mutex.Lock()
+	defer ditto.mutex.Unlock()
+
+	thing, ok := ditto.things[thingID]
+	if !ok {
+		return nil, false
+	}
+	result := map[string]interface{}{}
+	Convert(thing, &result)
+	return result, true
+}
+
+// ProcessEnvelope processes a Ditto protocol twin command or live message as if it was sent by a device
+// and returns the response to it, if any
+func (ditto *FakeDitto) ProcessEnvelope(envelope *protocol.Envelope) *protocol.Envelope {
+	return ditto.processEnvelope(envelope, nil)
+}
+
+func (ditto *FakeDitto) authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if username, password, ok := r.BasicAuth(); !ok || username != ditto.username || password != ditto.password {
+			writeFakeDittoError(w, http.StatusUnauthorized, "gateway:authentication.failed", "invalid credentials")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (ditto *FakeDitto) handleThings(w http.ResponseWriter, r *http.Request) {
+	thingID, path := splitThingsRequestPath(r.URL.Path)
+	if thingID == "" {
+		writeFakeDittoError(w, http.StatusNotFound, "resource.notfound", "thing ID not specified")
+		return
+	}
+
+	if matches := fakeDittoInboxPath.FindStringSubmatch(path); matches != nil && r.Method == http.MethodPost {
+		ditto.handleRESTMessage(w, r, thingID, path, matches[2])
+		return
+	}
+
+	var value interface{}
+	if r.Method == http.MethodPut || r.Method == http.MethodPatch {
+		body, err := io.ReadAll(r.Body)
+		if err == nil {
+			err = json.Unmarshal(body, &value)
+		}
+		if err != nil {
+			writeFakeDittoError(w, http.StatusBadRequest, "json.invalid", "invalid JSON body")
+			return
+		}
+	}
+
+	labels := requestedFakeDittoAcks(r, AckTwinPersisted)
+	headers := newFakeDittoSignalHeaders(r.Header.Get(protocol.HeaderCorrelationID), labels)
+	acks := ditto.expectAcks(headers.CorrelationID(), labels, AckTwinPersisted, AckSearchPersisted)
+	defer ditto.releaseAcks(acks)
+
+	var status int
+	switch r.Method {
+	case http.MethodGet:
+		result, ok := ditto.retrieve(thingID, path)
+		if !ok {
+			writeFakeDittoError(w, http.StatusNotFound, "things:entity.notfound",
+				fmt.Sprintf("%s of thing %s not found", path, thingID))
+			return
+		}
+		writeFakeDittoJSON(w, http.StatusOK, result)
+		return
+	case http.MethodPut:
+		status = ditto.modify(thingID, path, value, false, headers)
+	case http.MethodPatch:
+		status = ditto.modify(thingID, path, value, true, headers)
+	case http.MethodDelete:
+		status = ditto.delete(thingID, path, headers)
+	default:
+		writeFakeDittoError(w, http.StatusMethodNotAllowed, "method.notallowed", r.Method+" not supported")
+		return
+	}
+
+	switch {
+	case status == http.StatusNotFound:
+		writeFakeDittoError(w, status, "things:entity.notfound", fmt.Sprintf("%s of thing %s not found", path, thingID))
+		return
+	case status >= http.StatusBadRequest:
+		w.WriteHeader(status)
+		return
+	}
+	if status != http.StatusCreated {
+		value = nil
+	}
+
+	switch {
+	case len(labels) == 0:
+		w.WriteHeader(http.StatusAccepted)
+	case len(labels) == 1 && labels[0] == AckTwinPersisted:
+		if value == nil {
+			w.WriteHeader(status)
+		} else {
+			writeFakeDittoJSON(w, status, value)
+		}
+	default:
+		received := acks.wait(parseFakeDittoTimeout(r.Header.Get(protocol.HeaderTimeout),
+			r.URL.Query().Get(protocol.HeaderTimeout)))
+		for _, label := range []string{AckTwinPersisted, AckSearchPersisted} {
+			if containsAckLabel(labels, label) {
+				received[label] = &Acknowledgement{Status: status, Payload: value}
+			}
+		}
+		writeFakeDittoAcks(w, labels, received)
+	}
+}
+
+func (ditto *FakeDitto) handleRESTMessage(w http.ResponseWriter, r *http.Request, thingID, path, subject string) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		writeFakeDittoError(w, http.StatusBadRequest, "messages:payload.invalid", err.Error())
+		return
+	}
+	var value interface{}
+	if len(body) > 0 && json.Unmarshal(body, &value) != nil {
+		value = string(body)
+	}
+
+	responseRequired := r.Header.Get(protocol.HeaderResponseRequired) != "false"
+	timeout := parseFakeDittoTimeout(r.Header.Get(protocol.HeaderTimeout), r.URL.Query().Get(protocol.HeaderTimeout))
+
+	labels := requestedFakeDittoAcks(r, AckLiveResponse)
+	headers := newFakeDittoSignalHeaders(r.Header.Get(protocol.HeaderCorrelationID), labels)
+	headers.Values[protocol.HeaderResponseRequired] = responseRequired
+	headers.Values[protocol.HeaderTimeout] = formatTimeout(timeout)
+	if contentType := r.Header.Get("Content-Type"); contentType != "" {
+		headers.Values[protocol.HeaderContentType] = contentType
+	}
+	message := (&protocol.Envelope{}).
+		WithTopic(newThingTopic(thingID, protocol.ChannelLive, protocol.CriterionMessages,
+			protocol.TopicAction(subject))).
+		WithHeaders(headers).
+		WithPath(path).
+		WithValue(value)
+
+	acks := ditto.expectAcks(headers.CorrelationID(), labels, AckLiveResponse)
+	defer ditto.releaseAcks(acks)
+
+	if !responseRequired || timeout == 0 || len(labels) == 0 {
+		ditto.routeLiveMessage(message, nil, 0)
+		w.WriteHeader(http.StatusAccepted)
+		return
+	}
+
+	deadline := time.Now().Add(timeout)
+	var response *protocol.Envelope
+	if containsAckLabel(labels, AckLiveResponse) {
+		response = ditto.routeLiveMessage(message, nil, timeout)
+	} else {
+		ditto.routeLiveMessage(message, nil, 0)
+	}
+
+	if len(labels) == 1 && labels[0] == AckLiveResponse {
+		if response == nil {
+			writeFakeDittoError(w, http.StatusRequestTimeout, "messages:timeout",
+				fmt.Sprintf("no response to message %s received in %v", subject, timeout))
+			return
+		}
+		status := response.Status
+		if status == 0 {
+			status = http.StatusOK
+		}
+		if response.Value == nil {
+			w.WriteHeader(status)
+			return
+		}
+		writeFakeDittoJSON(w, status, response.Value)
+		return
+	}
+
+	received := acks.wait(time.Until(deadline))
+	if containsAckLabel(labels, AckLiveResponse) {
+		if response == nil {
+			received[AckLiveResponse] = &Acknowledgement{
+				Status: http.StatusRequestTimeout,
+				Payload: &fakeDittoError{
+					Status:  http.StatusRequestTimeout,
+					Error:   "messages:timeout",
+					Message: fmt.Sprintf("no response to message %s received in %v", subject, timeout),
+				},
+			}
+		} else {
+			status := response.Status
+			if status == 0 {
+				status = http.StatusOK
+			}
+			received[AckLiveResponse] = &Acknowledgement{Status: status, Payload: response.Value}
+		}
+	}
+	writeFakeDittoAcks(w, labels, received)
+}
+
+func (ditto *FakeDitto) handleWS(conn *websocket.Conn) {
+	session := &fakeDittoSession{
+		conn:         conn,
+		declaredAcks: map[string]bool{},
+		queued:       make(chan struct{}, 1),
+	}
+	for _, label := range splitAckLabels(conn.Request().Header.Get(headerDeclaredAcks)) {
+		session.declaredAcks[label] = true
+	}
+
+	ditto.mutex.Lock()
+	ditto.sessions[session] = true
+	ditto.mutex.Unlock()
+
+	closed := make(chan struct{})
+	go session.writeMessages(closed)
+
+	defer func() {
+		ditto.mutex.Lock()
+		delete(ditto.sessions, session)
+		ditto.mutex.Unlock()
+		close(closed)
+		conn.Close()
+	}()
+
+	for {
+		var payload string
+		if err := websocket.Message.Receive(conn, &payload); err != nil {
+			return
+		}
+		payload = strings.TrimSpace(payload)
+		if strings.HasPrefix(payload, "START-SEND-") || strings.HasPrefix(payload, "STOP-SEND-") {
+			session.handleProtocolMessage(payload)
+			continue
+		}
+
+		envelope := &protocol.Envelope{}
+		if err := json.Unmarshal([]byte(payload), envelope); err != nil || envelope.Topic == nil {
+			continue
+		}
+		if envelope.Topic.Channel == protocol.ChannelLive {
+			// Waiting for a live response must not block the session
+			go ditto.processWSEnvelope(envelope, session)
+		} else {
+			ditto.processWSEnvelope(envelope, session)
+		}
+	}
+}
+
+func (ditto *FakeDitto) processWSEnvelope(envelope *protocol.Envelope, session *fakeDittoSession) {
+	if response := ditto.processEnvelope(envelope, session); response != nil {
+		session.send(response)
+	}
+}
+
+func (session *fakeDittoSession) handleProtocolMessage(payload string) {
+	command := payload
+	filter := ""
+	if i := strings.Index(payload, "?"); i >= 0 {
+		command = payload[:i]
+		if values, err := parseFakeDittoQuery(payload[i+1:]); err == nil {
+			filter = values
+		}
+	}
+
+	session.mutex.Lock()
+	switch command {
+	case string(StartSendEvents):
+		session.events = true
+		session.eventsFilter = filter
+	case string(StopSendEvents):
+		session.events = false
+	case string(StartSendMessages):
+		session.messages = true
+		session.messagesFilter = filter
+	case string(StopSendMessages):
+		session.messages = false
+	case wsStartSendLiveCommands, wsStopSendLiveCommands, wsStartSendLiveEvents, wsStopSendLiveEvents:
+	default:
+		session.mutex.Unlock()
+		return
+	}
+	session.mutex.Unlock()
+
+	session.sendText(command + ":ACK")
+}
+
+func parseFakeDittoQuery(query string) (string, error) {
+	for _, param := range strings.Split(query, "&") {
+		if strings.HasPrefix(param, "filter=") {
+			return strings.TrimPrefix(param, "filter="), nil
+		}
+	}
+	return "", nil
+}
+
+func (session *fakeDittoSession) accepts(thingID string, events bool) bool {
+	session.mutex.Lock()
+	defer session.mutex.Unlock()
+
+	filter := session.messagesFilter
+	if events {
+		filter = session.eventsFilter
+	}
+	if (events && !session.events) || (!events && !session.messages) {
+		return false
+	}
+	if matches := fakeDittoFilter.FindStringSubmatch(filter); matches != nil {
+		return matches[1] == thingID
+	}
+	return true
+}
+
+func (session *fakeDittoSession) send(envelope *protocol.Envelope) {
+	data, err := json.Marshal(envelope)
+	if err != nil {
+		return
+	}
+	session.sendText(string(data))
+}
+
+// sendText queues a message to the session without waiting for it to be written
+func (session *fakeDittoSession) sendText(text string) {
+	session.mutex.Lock()
+	session.outbox = append(session.outbox, text)
+	session.mutex.Unlock()
+
+	select {
+	case session.queued <- struct{}{}:
+	default:
+	}
+}
+
+// writeMessages writes the queued messages in order until the session is closed
+func (session *fakeDittoSession) writeMessages(closed <-chan struct{}) {
+	for {
+		select {
+		case <-session.queued:
+		case <-closed:
+			return
+		}
+		for {
+			session.mutex.Lock()
+			if len(session.outbox) == 0 {
+				session.mutex.Unlock()
+				break
+			}
+			text := session.outbox[0]
+			session.outbox = session.outbox[1:]
+			session.mutex.Unlock()
+
+			if err := websocket.Message.Send(session.conn, text); err != nil {
+				return
+			}
+		}
+	}
+}
+
+func (ditto *FakeDitto) processEnvelope(envelope *protocol.Envelope, origin *fakeDittoSession) *protocol.Envelope {
+	topic := envelope.Topic
+	thingID := topic.Namespace + ":" + topic.EntityName
+
+	if topic.Criterion == criterionAcks {
+		ditto.acknowledge(envelope)
+		return nil
+	}
+
+	if topic.Channel == protocol.ChannelLive {
+		if envelope.Status != 0 {
+			ditto.resolvePending(envelope)
+			return nil
+		}
+		timeout := fakeDittoDefaultTimeout
+		if envelope.Headers != nil {
+			timeout = parseFakeDittoTimeout(envelope.Headers.Timeout())
+			if !envelope.Headers.IsResponseRequired() {
+				timeout = 0
+			}
+		}
+		return ditto.routeLiveMessage(envelope, origin, timeout)
+	}
+
+	if topic.Criterion != protocol.CriterionCommands {
+		return nil
+	}
+
+	var (
+		status int
+		value  interface{}
+	)
+	correlationID := ""
+	if envelope.Headers != nil {
+		correlationID = envelope.Headers.CorrelationID()
+	}
+	headers := newFakeDittoSignalHeaders(correlationID, nil)
+	switch topic.Action {
+	case protocol.ActionRetrieve:
+		result, ok := ditto.retrieve(thingID, envelope.Path)
+		status = http.StatusOK
+		if !ok {
+			status = http.StatusNotFound
+		}
+		value = result
+	case protocol.ActionCreate, protocol.ActionModify:
+		status = ditto.modify(thingID, envelope.Path, envelope.Value, false, headers)
+	case protocol.ActionMerge:
+		status = ditto.modify(thingID, envelope.Path, envelope.Value, true, headers)
+	case protocol.ActionDelete:
+		status = ditto.delete(thingID, envelope.Path, headers)
+	default:
+		status = http.StatusBadRequest
+	}
+
+	if envelope.Headers != nil && envelope.Headers.Values[protocol.HeaderResponseRequired] == false {
+		return nil
+	}
+	return (&protocol.Envelope{}).
+		WithTopic(topic).
+		WithHeaders(envelope.Headers).
+		WithPath(envelope.Path).
+		WithValue(value).
+		WithStatus(status)
+}
+
+// routeLiveMessage delivers a live message to all sessions and to the live message handler.
+// If the timeout is positive, it waits for the first response.
+func (ditto *FakeDitto) routeLiveMessage(message *protocol.Envelope, origin *fakeDittoSession,
+	timeout time.Duration) *protocol.Envelope {
+	thingID := message.Topic.Namespace + ":" + message.Topic.EntityName
+	correlationID := ""
+	if message.Headers != nil {
+		correlationID = message.Headers.CorrelationID()
+	}
+
+	ch := make(chan *protocol.Envelope, 1)
+	ditto.mutex.Lock()
+	if timeout > 0 && correlationID != "" {
+		ditto.pending[correlationID] = ch
+	}
+	handler := ditto.handler
+	var sessions []*fakeDittoSession
+	for session := range ditto.sessions {
+		if session != origin && session.accepts(thingID, false) {
+			sessions = append(sessions, session)
+		} else {
+			ditto.issueWeakAcks(session, message)
+		}
+	}
+	ditto.mutex.Unlock()
+
+	defer func() {
+		ditto.mutex.Lock()
+		delete(ditto.pending, correlationID)
+		ditto.mutex.Unlock()
+	}()
+
+	for _, session := range sessions {
+		session.send(message)
+	}
+	if handler != nil {
+		go func() {
+			if response := handler(message); response != nil {
+				select {
+				case ch <- response:
+				default:
+				}
+			}
+		}()
+	}
+
+	if timeout <= 0 {
+		return nil
+	}
+	select {
+	case response := <-ch:
+		return response
+	case <-time.After(timeout):
+		return nil
+	}
+}
+
+func (ditto *FakeDitto) resolvePending(response *protocol.Envelope) {
+	if response.Headers == nil {
+		return
+	}
+	ditto.mutex.Lock()
+	ch, ok := ditto.pending[response.Headers.CorrelationID()]
+	ditto.mutex.Unlock()
+	if ok {
+		select {
+		case ch <- response:
+		default:
+		}
+	}
+}
+
+func (ditto *FakeDitto) retrieve(thingID string, path string) (interface{}, bool) {
+	ditto.mutex.Lock()
+	defer ditto.mutex.Unlock()
+
+	thing, ok := ditto.things[thingID]
+	if !ok {
+		return nil, false
+	}
+	return getJSONPointer(thing, splitJSONPointer(path))
+}
+
+// modify creates, replaces or merges the value at the path of a thing and publishes the twin event with the given headers
+func (ditto *FakeDitto) modify(thingID string, path string, value interface{}, merge bool,
+	headers *protocol.Headers) int {
+	ditto.mutex.Lock()
+
+	segments := splitJSONPointer(path)
+	thing, exists := ditto.things[thingID]
+	if !exists {
+		if len(segments) > 0 {
+			ditto.mutex.Unlock()
+			return http.StatusNotFound
+		}
+		thing = map[string]interface{}{}
+	}
+	if !merge && !hasFakeDittoParent(thing, segments) {
+		ditto.mutex.Unlock()
+		return http.StatusNotFound
+	}
+
+	// Ditto publishes the merged event with the applied patch rather than the merged value
+	patch := value
+	if merge {
+		if current, ok := getJSONPointer(thing, segments); ok {
+			value = mergeJSON(current, value)
+		}
+	}
+
+	_, found := getJSONPointer(thing, segments)
+	created := !exists || !found
+	if len(segments) == 0 {
+		newThing, ok := value.(map[string]interface{})
+		if !ok {
+			ditto.mutex.Unlock()
+			return http.StatusBadRequest
+		}
+		newThing["thingId"] = thingID
+		ditto.things[thingID] = newThing
+		value = newThing
+	} else {
+		setJSONPointer(thing, segments, value)
+	}
+
+	action := protocol.ActionModified
+	status := http.StatusNoContent
+	if created {
+		action = protocol.ActionCreated
+		status = http.StatusCreated
+	}
+	if merge {
+		action = protocol.ActionMerged
+		status = http.StatusNoContent
+		value = patch
+	}
+	event := ditto.newTwinEvent(thingID, action, path, value, headers)
+	ditto.mutex.Unlock()
+
+	ditto.publishEvent(thingID, event)
+	return status
+}
+
+func (ditto *FakeDitto) delete(thingID string, path string, headers *protocol.Headers) int {
+	ditto.mutex.Lock()
+
+	thing, ok := ditto.things[thingID]
+	if !ok {
+		ditto.mutex.Unlock()
+		return http.StatusNotFound
+	}
+	segments := splitJSONPointer(path)
+	if len(segments) == 0 {
+		delete(ditto.things, thingID)
+	} else if !deleteJSONPointer(thing, segments) {
+		ditto.mutex.Unlock()
+		return http.StatusNotFound
+	}
+	event := ditto.newTwinEvent(thingID, protocol.ActionDeleted, path, nil, headers)
+	if len(segments) == 0 {
+		delete(ditto.revisions, thingID)
+	}
+	ditto.mutex.Unlock()
+
+	ditto.publishEvent(thingID, event)
+	return http.StatusNoContent
+}
+
+// newTwinEvent must be called with locked mutex
+func (ditto *FakeDitto) newTwinEvent(thingID string, action protocol.TopicAction, path string,
+	value interface{}, headers *protocol.Headers) *protocol.Envelope {
+	ditto.revisions[thingID]++
+	if path == "" {
+		path = "/"
+	}
+	copied := value
+	if value != nil {
+		Convert(value, &copied)
+	}
+	return (&protocol.Envelope{}).
+		WithTopic(newThingTopic(thingID, protocol.ChannelTwin, protocol.CriterionEvents, action)).
+		WithHeaders(headers).
+		WithPath(path).
+		WithValue(copied).
+		WithRevision(ditto.revisions[thingID]).
+		WithTimestamp(time.Now().UTC().Format(time.RFC3339Nano))
+}
+
+func (ditto *FakeDitto) publishEvent(thingID string, event *protocol.Envelope) {
+	ditto.mutex.Lock()
+	var sessions []*fakeDittoSession
+	for session := range ditto.sessions {
+		if session.accepts(thingID, true) {
+			sessions = append(sessions, session)
+		} else {
+			ditto.issueWeakAcks(session, event)
+		}
+	}
+	ditto.mutex.Unlock()
+
+	for _, session := range sessions {
+		session.send(event)
+	}
+}
+
+func newThingTopic(thingID string, channel protocol.TopicChannel, criterion protocol.TopicCriterion,
+	action protocol.TopicAction) *protocol.Topic {
+	namespace, name := thingID, ""
+	if i := strings.Index(thingID, ":"); i >= 0 {
+		namespace, name = thingID[:i], thingID[i+1:]
+	}
+	return (&protocol.Topic{}).
+		WithNamespace(namespace).
+		WithEntityName(name).
+		WithGroup(protocol.GroupThings).
+		WithChannel(channel).
+		WithCriterion(criterion).
+		WithAction(action)
+}
+
+func splitThingsRequestPath(requestPath string) (string, string) {
+	rest := strings.TrimPrefix(requestPath, fakeDittoThingsPrefix)
+	if i := strings.Index(rest, "/"); i >= 0 {
+		return rest[:i], rest[i:]
+	}
+	return rest, ""
+}
+
+// hasFakeDittoParent checks if the parent of a path, which is to be created, exists as Ditto requires it.
+// A feature must exist before its properties are modified, while the missing objects inside attributes
+// and properties are created.
+func hasFakeDittoParent(thing map[string]interface{}, segments []string) bool {
+	if len(segments) > 2 && segments[0] == "features" {
+		_, ok := getJSONPointer(thing, segments[:2])
+		return ok
+	}
+	return true
+}
+
+func splitJSONPointer(path string) []string {
+	path = strings.Trim(path, "/")
+	if path == "" {
+		return nil
+	}
+	return strings.Split(path, "/")
+}
+
+func getJSONPointer(root map[string]interface{}, segments []string) (interface{}, bool) {
+	var current interface{} = root
+	for _, segment := range segments {
+		object, ok := current.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		if current, ok = object[segment]; !ok {
+			return nil, false
+		}
+	}
+	return current, true
+}
+
+func setJSONPointer(root map[string]interface{}, segments []string, value interface{}) {
+	current := root
+	for _, segment := range segments[:len(segments)-1] {
+		next, ok := current[segment].(map[string]interface{})
+		if !ok {
+			next = map[string]interface{}{}
+			current[segment] = next
+		}
+		current = next
+	}
+	current[segments[len(segments)-1]] = value
+}
+
+func deleteJSONPointer(root map[string]interface{}, segments []string) bool {
+	parent, ok := getJSONPointer(root, segments[:len(segments)-1])
+	if !ok {
+		return false
+	}
+	object, ok := parent.(map[string]interface{})
+	if !ok {
+		return false
+	}
+	last := segments[len(segments)-1]
+	if _, ok := object[last]; !ok {
+		return false
+	}
+	delete(object, last)
+	return true
+}
+
+// mergeJSON applies a JSON merge patch (RFC 7396) to the current value
+func mergeJSON(current interface{}, patch interface{}) interface{} {
+	patchObject, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+	currentObject, ok := current.(map[string]interface{})
+	if !ok {
+		currentObject = map[string]interface{}{}
+	}
+	for key, value := range patchObject {
+		if value == nil {
+			delete(currentObject, key)
+		} else {
+			currentObject[key] = mergeJSON(currentObject[key], value)
+		}
+	}
+	return currentObject
+}
+
+// parseFakeDittoTimeout parses the first non-empty timeout in Ditto format, e.g. "10", "10s" or "500ms"
+func parseFakeDittoTimeout(values ...string) time.Duration {
+	for _, value := range values {
+		if value == "" {
+			continue
+		}
+		if strings.HasSuffix(value, "ms") || strings.HasSuffix(value, "s") || strings.HasSuffix(value, "m") {
+			if timeout, err := time.ParseDuration(value); err == nil {
+				return timeout
+			}
+		}
+		if seconds, err := strconv.Atoi(value); err == nil {
+			return time.Duration(seconds) * time.Second
+		}
+	}
+	return fakeDittoDefaultTimeout
+}
+
+func writeFakeDittoJSON(w http.ResponseWriter, status int, value interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(value)
+}
+
+func writeFakeDittoError(w http.ResponseWriter, status int, errorCode string, message string) {
+	writeFakeDittoJSON(w, status, &fakeDittoError{Status: status, Error: errorCode, Message: message})
+}
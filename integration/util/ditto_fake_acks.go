@@ -0,0 +1,215 @@
+// Copyright (c) 2026 Contributors to the Eclipse Foundation
+//
+// See the NOTICE file(s) distributed with this work for additional
+// information regarding copyright ownership.
+//
+// This program and the accompanying materials are made available under the
+// terms of the Eclipse Public License 2.0 which is available at
+// https://www.eclipse.org/legal/epl-2.0, or the Apache License, Version 2.0
+// which is available at https://www.apache.org/licenses/LICENSE-2.0.
+//
+// SPDX-License-Identifier: EPL-2.0 OR Apache-2.0
+
+package util
+
+import (
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/eclipse/ditto-clients-golang/protocol"
+	"github.com/google/uuid"
+)
+
+// fakeDittoAcks collects the acknowledgements requested for a signal, which are issued by the WebSocket sessions
+type fakeDittoAcks struct {
+	correlationID string
+
+	mutex    sync.Mutex
+	awaited  map[string]bool
+	received map[string]*Acknowledgement
+	done     chan struct{}
+}
+
+// requestedFakeDittoAcks returns the acknowledgement labels requested by a REST request.
+// If the request does not specify them, only the default label is requested.
+func requestedFakeDittoAcks(r *http.Request, defaultLabel string) []string {
+	if _, ok := r.Header[http.CanonicalHeaderKey(headerRequestedAcks)]; !ok {
+		return []string{defaultLabel}
+	}
+	return splitAckLabels(r.Header.Get(headerRequestedAcks))
+}
+
+func splitAckLabels(value string) []string {
+	var labels []string
+	for _, label := range strings.Split(value, ",") {
+		if label = strings.TrimSpace(label); label != "" {
+			labels = append(labels, label)
+		}
+	}
+	return labels
+}
+
+func containsAckLabel(labels []string, label string) bool {
+	for _, requested := range labels {
+		if requested == label {
+			return true
+		}
+	}
+	return false
+}
+
+// newFakeDittoSignalHeaders returns the headers of a signal published by the fake Ditto
+func newFakeDittoSignalHeaders(correlationID string, requestedAcks []string) *protocol.Headers {
+	if correlationID == "" {
+		correlationID = uuid.New().String()
+	}
+	headers := protocol.NewHeaders(protocol.WithCorrelationID(correlationID))
+	if len(requestedAcks) > 0 {
+		headers.Values[headerRequestedAcks] = requestedAcks
+	}
+	return headers
+}
+
+// expectAcks starts collecting the requested acknowledgements of a signal, except for the ones fulfilled by the fake
+// Ditto itself. It must be called before the signal is published, so that the weak acknowledgements are not missed.
+func (ditto *FakeDitto) expectAcks(correlationID string, labels []string, fulfilled ...string) *fakeDittoAcks {
+	acks := &fakeDittoAcks{
+		correlationID: correlationID,
+		awaited:       map[string]bool{},
+		received:      map[string]*Acknowledgement{},
+		done:          make(chan struct{}),
+	}
+	for _, label := range labels {
+		if !containsAckLabel(fulfilled, label) {
+			acks.awaited[label] = true
+		}
+	}
+	if len(acks.awaited) == 0 {
+		close(acks.done)
+		return acks
+	}
+
+	ditto.mutex.Lock()
+	ditto.acks[correlationID] = acks
+	ditto.mutex.Unlock()
+	return acks
+}
+
+func (ditto *FakeDitto) releaseAcks(acks *fakeDittoAcks) {
+	ditto.mutex.Lock()
+	defer ditto.mutex.Unlock()
+	if ditto.acks[acks.correlationID] == acks {
+		delete(ditto.acks, acks.correlationID)
+	}
+}
+
+// acknowledge handles an acknowledgement sent by a WebSocket session
+func (ditto *FakeDitto) acknowledge(envelope *protocol.Envelope) {
+	if envelope.Headers == nil {
+		return
+	}
+	ditto.mutex.Lock()
+	acks, ok := ditto.acks[envelope.Headers.CorrelationID()]
+	ditto.mutex.Unlock()
+	if ok {
+		acks.add(string(envelope.Topic.Action), &Acknowledgement{
+			Status:  envelope.Status,
+			Payload: envelope.Value,
+			Headers: envelope.Headers.Values,
+		})
+	}
+}
+
+// issueWeakAcks issues weak acknowledgements for the labels declared by a session, which does not receive a signal.
+// It must be called with locked mutex.
+func (ditto *FakeDitto) issueWeakAcks(session *fakeDittoSession, signal *protocol.Envelope) {
+	if len(session.declaredAcks) == 0 || signal.Headers == nil {
+		return
+	}
+	acks, ok := ditto.acks[signal.Headers.CorrelationID()]
+	if !ok {
+		return
+	}
+	for label := range session.declaredAcks {
+		acks.add(label, &Acknowledgement{
+			Status: http.StatusOK,
+			Headers: map[string]interface{}{
+				protocol.HeaderCorrelationID: acks.correlationID,
+				headerWeakAck:                true,
+			},
+		})
+	}
+}
+
+// add records the first acknowledgement with an awaited label
+func (acks *fakeDittoAcks) add(label string, ack *Acknowledgement) {
+	acks.mutex.Lock()
+	defer acks.mutex.Unlock()
+
+	if _, ok := acks.received[label]; ok || !acks.awaited[label] {
+		return
+	}
+	acks.received[label] = ack
+	if len(acks.received) == len(acks.awaited) {
+		close(acks.done)
+	}
+}
+
+// wait waits for the awaited acknowledgements until the timeout expires.
+// The acknowledgements, which are not received in time, are returned with status 408.
+func (acks *fakeDittoAcks) wait(timeout time.Duration) map[string]*Acknowledgement {
+	if timeout > 0 {
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+		select {
+		case <-acks.done:
+		case <-timer.C:
+		}
+	}
+
+	acks.mutex.Lock()
+	defer acks.mutex.Unlock()
+
+	result := map[string]*Acknowledgement{}
+	for label := range acks.awaited {
+		if ack, ok := acks.received[label]; ok {
+			result[label] = ack
+		} else {
+			result[label] = &Acknowledgement{
+				Status: http.StatusRequestTimeout,
+				Payload: &fakeDittoError{
+					Status:  http.StatusRequestTimeout,
+					Error:   "acknowledgement:request.timeout",
+					Message: "the acknowledgement " + label + " was not received in time",
+				},
+				Headers: map[string]interface{}{protocol.HeaderCorrelationID: acks.correlationID},
+			}
+		}
+	}
+	return result
+}
+
+// writeFakeDittoAcks writes the response to a request with requested acknowledgements. A single acknowledgement
+// is written as the response itself, multiple ones are aggregated with status 200 if all of them are successful
+// and status 424 otherwise.
+func writeFakeDittoAcks(w http.ResponseWriter, labels []string, acks map[string]*Acknowledgement) {
+	if len(labels) == 1 {
+		ack := acks[labels[0]]
+		if ack.Payload == nil {
+			w.WriteHeader(ack.Status)
+			return
+		}
+		writeFakeDittoJSON(w, ack.Status, ack.Payload)
+		return
+	}
+
+	status := http.StatusOK
+	for _, ack := range acks {
+		if ack.Status < 200 || ack.Status >= 300 {
+			status = http.StatusFailedDependency
+		}
+	}
+	writeFakeDittoJSON(w, status, acks)
+}
@@ -0,0 +1,302 @@
+// Copyright (c) 2026 Contributors to the Eclipse Foundation
+//
+// See the NOTICE file(s) distributed with this work for additional
+// information regarding copyright ownership.
+//
+// This program and the accompanying materials are made available under the
+// terms of the Eclipse Public License 2.0 which is available at
+// https://www.eclipse.org/legal/epl-2.0, or the Apache License, Version 2.0
+// which is available at https://www.apache.org/licenses/LICENSE-2.0.
+//
+// SPDX-License-Identifier: EPL-2.0 OR Apache-2.0
+
+package util
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/eclipse/ditto-clients-golang/protocol"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/websocket"
+)
+
+const testThingID = "test:device"
+
+// startTestDitto starts a fake Ditto, which is closed on test cleanup,
+// and returns a test configuration connecting to it
+func startTestDitto(t *testing.T) (*FakeDitto, *TestConfiguration) {
+	ditto := StartFakeDitto("ditto", "ditto")
+	t.Cleanup(ditto.Close)
+
+	return ditto, &TestConfiguration{
+		DigitalTwinAPIAddress:  ditto.URL(),
+		DigitalTwinAPIUsername: "ditto",
+		DigitalTwinAPIPassword: "ditto",
+		WSEventTimeoutMS:       3000,
+	}
+}
+
+func createTestThing(ctx context.Context, t *testing.T, cfg *TestConfiguration) string {
+	thingURL := GetThingURL(cfg.DigitalTwinAPIAddress, testThingID)
+	_, err := SendDigitalTwinRequest(ctx, cfg, http.MethodPut, thingURL, map[string]interface{}{})
+	require.NoError(t, err)
+	return thingURL
+}
+
+// subscribeForTestThingEvents opens a WebSocket session receiving the twin events of the test thing
+func subscribeForTestThingEvents(ctx context.Context, t *testing.T, cfg *TestConfiguration, thingID string,
+	declaredAcks ...string) *websocket.Conn {
+	ws, err := NewDigitalTwinWSConnection(ctx, cfg, declaredAcks...)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		ws.Close()
+	})
+	require.NoError(t, SubscribeForWSMessages(ctx, cfg, ws, StartSendEvents, fmt.Sprintf(`eq(thingId,"%s")`, thingID)))
+	return ws
+}
+
+func TestFakeDittoTwinRequests(t *testing.T) {
+	_, cfg := startTestDitto(t)
+	ctx, cancel := NewTestContext(t)
+	defer cancel()
+
+	thingURL := createTestThing(ctx, t, cfg)
+	featureURL := GetFeatureURL(thingURL, "test")
+
+	// Ditto does not create a missing feature for its properties
+	_, err := SendDigitalTwinRequest(ctx, cfg, http.MethodPut, featureURL+"/properties/nested/value", 1)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "404")
+
+	_, err = SendDigitalTwinRequest(ctx, cfg, http.MethodPut, featureURL, map[string]interface{}{})
+	require.NoError(t, err)
+	_, err = SendDigitalTwinRequest(ctx, cfg, http.MethodPut, featureURL+"/properties/nested/value", 1)
+	require.NoError(t, err)
+	_, err = SendDigitalTwinRequest(ctx, cfg, http.MethodPut, thingURL+"/attributes/location/room", "lab")
+	require.NoError(t, err)
+
+	body, err := GetFeaturePropertyValue(ctx, cfg, featureURL, "nested/value")
+	require.NoError(t, err)
+	require.JSONEq(t, "1", string(body))
+	body, err = SendDigitalTwinRequest(ctx, cfg, http.MethodGet, thingURL+"/attributes/location/room", nil)
+	require.NoError(t, err)
+	require.JSONEq(t, `"lab"`, string(body))
+
+	_, err = SendDigitalTwinRequest(ctx, cfg, http.MethodDelete, featureURL, nil)
+	require.NoError(t, err)
+	_, err = SendDigitalTwinRequest(ctx, cfg, http.MethodGet, featureURL, nil)
+	require.Error(t, err)
+
+	_, err = SendDigitalTwinRequest(ctx, &TestConfiguration{
+		DigitalTwinAPIAddress:  cfg.DigitalTwinAPIAddress,
+		DigitalTwinAPIUsername: "ditto",
+		DigitalTwinAPIPassword: "invalid",
+	}, http.MethodGet, thingURL, nil)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "401")
+}
+
+func TestFakeDittoTwinEvents(t *testing.T) {
+	_, cfg := startTestDitto(t)
+	ctx, cancel := NewTestContext(t)
+	defer cancel()
+
+	thingURL := createTestThing(ctx, t, cfg)
+	ws := subscribeForTestThingEvents(ctx, t, cfg, testThingID)
+
+	_, err := SendDigitalTwinRequest(ctx, cfg, http.MethodPut, thingURL+"/attributes/test", "value",
+		WithCorrelationID("test-correlation"))
+	require.NoError(t, err)
+
+	err = ProcessWSMessages(ctx, cfg, ws, func(event *protocol.Envelope) (bool, error) {
+		if event.Topic.String() != GetTwinEventTopic(testThingID, protocol.ActionCreated) {
+			return false, nil
+		}
+		if event.Path != "/attributes/test" || event.Value != "value" {
+			return true, fmt.Errorf("unexpected event %s: %v", event.Path, event.Value)
+		}
+		if event.Headers.CorrelationID() != "test-correlation" {
+			return true, fmt.Errorf("unexpected correlation ID %s", event.Headers.CorrelationID())
+		}
+		return true, nil
+	})
+	require.NoError(t, err)
+}
+
+func TestFakeDittoRequestedAcks(t *testing.T) {
+	_, cfg := startTestDitto(t)
+	ctx, cancel := NewTestContext(t)
+	defer cancel()
+
+	thingURL := createTestThing(ctx, t, cfg)
+
+	// The acknowledging session issues a strong acknowledgement for each event
+	ws := subscribeForTestThingEvents(ctx, t, cfg, testThingID, "test-ack")
+	// The filtered session does not receive the events, so weak acknowledgements are issued for it
+	subscribeForTestThingEvents(ctx, t, cfg, "test:other", "weak-ack")
+
+	acknowledged := make(chan error, 1)
+	go func() {
+		count := 0
+		acknowledged <- ProcessWSMessages(ctx, cfg, ws, func(event *protocol.Envelope) (bool, error) {
+			count++
+			return count == 2, SendAcknowledgement(ctx, ws, event, "test-ack", http.StatusOK, "done")
+		})
+	}()
+
+	body, err := SendDigitalTwinRequest(ctx, cfg, http.MethodPut, thingURL+"/attributes/first", 1,
+		WithRequestedAcks(AckTwinPersisted, "test-ack", "weak-ack"))
+	require.NoError(t, err)
+	acks, err := ParseAcknowledgements(body)
+	require.NoError(t, err)
+	require.Len(t, acks, 3)
+	require.Equal(t, http.StatusCreated, acks[AckTwinPersisted].Status)
+	require.Equal(t, http.StatusOK, acks["test-ack"].Status)
+	require.Equal(t, "done", acks["test-ack"].Payload)
+	require.False(t, acks["test-ack"].IsWeak())
+	require.True(t, acks["weak-ack"].IsWeak())
+
+	// An acknowledgement, which is not declared by any session, times out and fails the request
+	body, err = SendDigitalTwinRequest(ctx, cfg, http.MethodPut, thingURL+"/attributes/second", 2,
+		WithRequestedAcks(AckTwinPersisted, "test-ack", "missing-ack"), WithTimeout(500*time.Millisecond))
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "424")
+	acks, err = ParseAcknowledgements(body)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, acks["test-ack"].Status)
+	require.Equal(t, http.StatusRequestTimeout, acks["missing-ack"].Status)
+
+	require.NoError(t, <-acknowledged)
+
+	// No acknowledgements requested means that the request is only accepted
+	_, err = SendDigitalTwinRequest(ctx, cfg, http.MethodPut, thingURL+"/attributes/third", 3, WithRequestedAcks())
+	require.NoError(t, err)
+}
+
+func TestFakeDittoLiveMessages(t *testing.T) {
+	ditto, cfg := startTestDitto(t)
+	ctx, cancel := NewTestContext(t)
+	defer cancel()
+
+	thingURL := createTestThing(ctx, t, cfg)
+	ditto.HandleLiveMessages(func(message *protocol.Envelope) *protocol.Envelope {
+		return (&protocol.Envelope{}).
+			WithTopic(message.Topic).
+			WithHeaders(message.Headers).
+			WithPath(strings.Replace(message.Path, "/inbox/", "/outbox/", 1)).
+			WithValue(map[string]interface{}{"echo": message.Value}).
+			WithStatus(http.StatusOK)
+	})
+
+	body, err := ExecuteOperation(ctx, cfg, GetFeatureURL(thingURL, "test"), "echo", "hello")
+	require.NoError(t, err)
+	require.JSONEq(t, `{"echo":"hello"}`, string(body))
+}
+
+func TestFakeDittoStalledSession(t *testing.T) {
+	_, cfg := startTestDitto(t)
+	ctx, cancel := NewTestContext(t)
+	defer cancel()
+
+	thingURL := createTestThing(ctx, t, cfg)
+
+	// The stalled session never reads the events sent to it
+	subscribeForTestThingEvents(ctx, t, cfg, testThingID)
+	ws := subscribeForTestThingEvents(ctx, t, cfg, testThingID)
+
+	value := strings.Repeat("x", 256*1024)
+	const modifications = 64
+	for i := 0; i < modifications; i++ {
+		_, err := SendDigitalTwinRequest(ctx, cfg, http.MethodPut, thingURL+"/attributes/large", value)
+		require.NoError(t, err)
+	}
+
+	count := 0
+	require.NoError(t, ProcessWSMessages(ctx, cfg, ws, func(event *protocol.Envelope) (bool, error) {
+		count++
+		return count == modifications, nil
+	}))
+}
+
+func TestFakeDittoSessionFilters(t *testing.T) {
+	_, cfg := startTestDitto(t)
+	ctx, cancel := NewTestContext(t)
+	defer cancel()
+
+	const otherThingID = "test:other"
+	thingURL := createTestThing(ctx, t, cfg)
+	otherThingURL := GetThingURL(cfg.DigitalTwinAPIAddress, otherThingID)
+	_, err := SendDigitalTwinRequest(ctx, cfg, http.MethodPut, otherThingURL, map[string]interface{}{})
+	require.NoError(t, err)
+
+	// The events of the test thing and the live messages of the other thing are subscribed on one session
+	ws := subscribeForTestThingEvents(ctx, t, cfg, testThingID)
+	require.NoError(t, SubscribeForWSMessages(ctx, cfg, ws, StartSendMessages,
+		fmt.Sprintf(`eq(thingId,"%s")`, otherThingID)))
+
+	_, err = SendDigitalTwinRequest(ctx, cfg, http.MethodPut, otherThingURL+"/attributes/test", "filtered")
+	require.NoError(t, err)
+	_, err = SendDigitalTwinRequest(ctx, cfg, http.MethodPut, thingURL+"/attributes/test", "value")
+	require.NoError(t, err)
+
+	sender, err := NewDigitalTwinWSConnection(ctx, cfg)
+	require.NoError(t, err)
+	defer sender.Close()
+	for _, thingID := range []string{testThingID, otherThingID} {
+		message := (&protocol.Envelope{}).
+			WithTopic(newThingTopic(thingID, protocol.ChannelLive, protocol.CriterionMessages, protocol.TopicAction("test"))).
+			WithHeaders(protocol.NewHeaders(protocol.WithResponseRequired(false))).
+			WithPath(GetFeatureInboxMessagePath("test", "test")).
+			WithValue(thingID)
+		data, err := json.Marshal(message)
+		require.NoError(t, err)
+		require.NoError(t, websocket.Message.Send(sender, string(data)))
+	}
+
+	var received []string
+	require.NoError(t, ProcessWSMessages(ctx, cfg, ws, func(envelope *protocol.Envelope) (bool, error) {
+		received = append(received,
+			string(envelope.Topic.Channel)+" "+envelope.Topic.Namespace+":"+envelope.Topic.EntityName)
+		return len(received) == 2, nil
+	}))
+	require.Equal(t, []string{"twin " + testThingID, "live " + otherThingID}, received)
+}
+
+func TestFakeDittoMergeEventCarriesPatch(t *testing.T) {
+	_, cfg := startTestDitto(t)
+	ctx, cancel := NewTestContext(t)
+	defer cancel()
+
+	thingURL := createTestThing(ctx, t, cfg)
+	_, err := SendDigitalTwinRequest(ctx, cfg, http.MethodPut, thingURL+"/attributes/location",
+		map[string]interface{}{"room": "lab", "floor": 1})
+	require.NoError(t, err)
+
+	ws := subscribeForTestThingEvents(ctx, t, cfg, testThingID)
+
+	_, err = SendDigitalTwinRequest(ctx, cfg, http.MethodPatch, thingURL+"/attributes/location",
+		map[string]interface{}{"floor": 2})
+	require.NoError(t, err)
+
+	err = ProcessWSMessages(ctx, cfg, ws, func(event *protocol.Envelope) (bool, error) {
+		if event.Topic.String() != GetTwinEventTopic(testThingID, protocol.ActionMerged) {
+			return false, nil
+		}
+		value, _ := json.Marshal(event.Value)
+		if event.Path != "/attributes/location" || string(value) != `{"floor":2}` {
+			return true, fmt.Errorf("unexpected merged event %s: %s", event.Path, value)
+		}
+		return true, nil
+	})
+	require.NoError(t, err)
+
+	body, err := SendDigitalTwinRequest(ctx, cfg, http.MethodGet, thingURL+"/attributes/location", nil)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"room":"lab","floor":2}`, string(body))
+}
@@ -0,0 +1,138 @@
+// Copyright (c) 2026 Contributors to the Eclipse Foundation
+//
+// See the NOTICE file(s) distributed with this work for additional
+// information regarding copyright ownership.
+//
+// This program and the accompanying materials are made available under the
+// terms of the Eclipse Public License 2.0 which is available at
+// https://www.eclipse.org/legal/epl-2.0, or the Apache License, Version 2.0
+// which is available at https://www.apache.org/licenses/LICENSE-2.0.
+//
+// SPDX-License-Identifier: EPL-2.0 OR Apache-2.0
+
+package util
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/eclipse/ditto-clients-golang/protocol"
+	"golang.org/x/net/websocket"
+)
+
+const thingEventsFilterTemplate = `eq(thingId,"%s")`
+
+// FeatureClient executes the operations of a feature and follows the changes of the feature properties,
+// which report the progress of the operations. The twin events of the thing are subscribed on creation,
+// so no change made after an operation is executed is missed.
+type FeatureClient struct {
+	cfg *TestConfiguration
+	ws  *websocket.Conn
+
+	Thing     *ThingClient
+	FeatureID string
+}
+
+// NewFeatureClient opens a WebSocket session to Ditto and subscribes for the twin events of the thing
+func NewFeatureClient(ctx context.Context, cfg *TestConfiguration, thingID string,
+	featureID string) (*FeatureClient, error) {
+	ws, err := NewDigitalTwinWSConnection(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+	filter := fmt.Sprintf(thingEventsFilterTemplate, thingID)
+	if err := SubscribeForWSMessages(ctx, cfg, ws, StartSendEvents, filter); err != nil {
+		ws.Close()
+		return nil, fmt.Errorf("unable to subscribe for events of thing %s: %v", thingID, err)
+	}
+	return &FeatureClient{
+		cfg:       cfg,
+		ws:        ws,
+		Thing:     NewThingClient(cfg, thingID),
+		FeatureID: featureID,
+	}, nil
+}
+
+// Close closes the WebSocket session
+func (client *FeatureClient) Close() error {
+	return client.ws.Close()
+}
+
+// subscribeForMessages additionally subscribes for the live messages of the thing,
+// which carry the messages sent from the feature outbox
+func (client *FeatureClient) subscribeForMessages(ctx context.Context) error {
+	filter := fmt.Sprintf(thingEventsFilterTemplate, client.Thing.ThingID)
+	if err := SubscribeForWSMessages(ctx, client.cfg, client.ws, StartSendMessages, filter); err != nil {
+		return fmt.Errorf("unable to subscribe for messages of thing %s: %v", client.Thing.ThingID, err)
+	}
+	return nil
+}
+
+// ExecuteOperation executes an operation of the feature
+func (client *FeatureClient) ExecuteOperation(ctx context.Context, operation string, params interface{},
+	opts ...RequestOption) ([]byte, error) {
+	return client.Thing.ExecuteOperation(ctx, client.FeatureID, operation, params, opts...)
+}
+
+// GetProperty retrieves a property of the feature and unmarshals it to the given value
+func (client *FeatureClient) GetProperty(ctx context.Context, property string, value interface{}) error {
+	return client.Thing.GetFeatureProperty(ctx, client.FeatureID, property, value)
+}
+
+// WatchProperty processes the values of a feature property from the twin events until the processing is finished
+// or the context is done. The WebSocket event timeout does not apply, as the property may report a long running
+// operation. The property may be nested, e.g. "status/lastOperation", and is extracted from the events, which modify
+// any of its parents. Deleted properties are not processed.
+func (client *FeatureClient) WatchProperty(ctx context.Context, property string,
+	process func(value interface{}) (bool, error)) error {
+	return WatchWSMessages(ctx, client.ws, func(envelope *protocol.Envelope) (bool, error) {
+		value, ok := client.propertyFromEvent(envelope, property)
+		if !ok {
+			return false, nil
+		}
+		return process(value)
+	})
+}
+
+// outboxMessageValue returns the value of a live message sent from the feature outbox with the subject
+func (client *FeatureClient) outboxMessageValue(envelope *protocol.Envelope, subject string) (interface{}, bool) {
+	topic := envelope.Topic
+	if topic == nil || topic.Channel != protocol.ChannelLive || topic.Criterion != protocol.CriterionMessages ||
+		envelope.Status != 0 || topic.Namespace+":"+topic.EntityName != client.Thing.ThingID {
+		return nil, false
+	}
+	return envelope.Value, envelope.Path == GetFeatureOutboxMessagePath(client.FeatureID, subject)
+}
+
+// propertyFromEvent extracts the value of the feature property from a twin event
+func (client *FeatureClient) propertyFromEvent(envelope *protocol.Envelope, property string) (interface{}, bool) {
+	return featurePropertyFromEvent(envelope, client.Thing.ThingID, client.FeatureID, property)
+}
+
+// featurePropertyFromEvent extracts the value of a property of a feature of the thing from a twin event
+func featurePropertyFromEvent(envelope *protocol.Envelope, thingID string, featureID string,
+	property string) (interface{}, bool) {
+	topic := envelope.Topic
+	if topic == nil || topic.Channel != protocol.ChannelTwin || topic.Criterion != protocol.CriterionEvents ||
+		topic.Action == protocol.ActionDeleted {
+		return nil, false
+	}
+	if topic.Namespace+":"+topic.EntityName != thingID {
+		return nil, false
+	}
+
+	propertyPath := GetFeaturePropertyPath(featureID, property)
+	eventPath := strings.TrimSuffix(envelope.Path, "/")
+	if eventPath == propertyPath {
+		return envelope.Value, true
+	}
+	if eventPath != "" && !strings.HasPrefix(propertyPath, eventPath+"/") {
+		return nil, false
+	}
+	root, ok := envelope.Value.(map[string]interface{})
+	if !ok {
+		return nil, false
+	}
+	return getJSONPointer(root, splitJSONPointer(strings.TrimPrefix(propertyPath, eventPath)))
+}
@@ -0,0 +1,128 @@
+// Copyright (c) 2026 Contributors to the Eclipse Foundation
+//
+// See the NOTICE file(s) distributed with this work for additional
+// information regarding copyright ownership.
+//
+// This program and the accompanying materials are made available under the
+// terms of the Eclipse Public License 2.0 which is available at
+// https://www.eclipse.org/legal/epl-2.0, or the Apache License, Version 2.0
+// which is available at https://www.apache.org/licenses/LICENSE-2.0.
+//
+// SPDX-License-Identifier: EPL-2.0 OR Apache-2.0
+
+package util
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/eclipse/ditto-clients-golang/model"
+	"github.com/stretchr/testify/require"
+)
+
+const testEventTimeout = 200 * time.Millisecond
+
+// putDelayed puts the feature property after a multiple of the WebSocket event timeout
+func putDelayed(ctx context.Context, client *ThingClient, featureID string, property string,
+	value interface{}) <-chan error {
+	result := make(chan error, 1)
+	go func() {
+		select {
+		case <-time.After(3 * testEventTimeout):
+			result <- client.PutFeatureProperty(ctx, featureID, property, value)
+		case <-ctx.Done():
+			result <- ctx.Err()
+		}
+	}()
+	return result
+}
+
+func TestFeatureClientWatchPropertyOutlastsEventTimeout(t *testing.T) {
+	_, cfg := startTestDitto(t)
+	ctx, cancel := NewTestContext(t)
+	defer cancel()
+
+	createTestThing(ctx, t, cfg)
+	cfg.WSEventTimeoutMS = int(testEventTimeout.Milliseconds())
+	client, err := NewFeatureClient(ctx, cfg, testThingID, "test")
+	require.NoError(t, err)
+	defer client.Close()
+	require.NoError(t, client.Thing.PutFeature(ctx, "test", &model.Feature{}))
+
+	put := putDelayed(ctx, client.Thing, "test", "status/lastOperation", map[string]interface{}{"status": "FINISHED"})
+	err = client.WatchProperty(ctx, "status/lastOperation", func(value interface{}) (bool, error) {
+		operation, ok := value.(map[string]interface{})
+		return ok && operation["status"] == "FINISHED", nil
+	})
+	require.NoError(t, err)
+	require.NoError(t, <-put)
+}
+
+func TestFeatureClientWatchPropertyBoundedByContext(t *testing.T) {
+	_, cfg := startTestDitto(t)
+	ctx, cancel := NewTestContext(t)
+	defer cancel()
+
+	createTestThing(ctx, t, cfg)
+	client, err := NewFeatureClient(ctx, cfg, testThingID, "test")
+	require.NoError(t, err)
+	defer client.Close()
+
+	watchCtx, watchCancel := context.WithTimeout(ctx, testEventTimeout)
+	defer watchCancel()
+	start := time.Now()
+	err = client.WatchProperty(watchCtx, "status/lastOperation", func(value interface{}) (bool, error) {
+		return true, nil
+	})
+	require.Error(t, err)
+	require.Less(t, time.Since(start), time.Duration(cfg.WSEventTimeoutMS)*time.Millisecond)
+}
+
+func TestContainersClientWaitForStatusOutlastsEventTimeout(t *testing.T) {
+	_, cfg := startTestDitto(t)
+	ctx, cancel := NewTestContext(t)
+	defer cancel()
+
+	thingURL := GetThingURL(cfg.DigitalTwinAPIAddress, GetContainersThingID(testThingID))
+	_, err := SendDigitalTwinRequest(ctx, cfg, http.MethodPut, thingURL, map[string]interface{}{})
+	require.NoError(t, err)
+	cfg.WSEventTimeoutMS = int(testEventTimeout.Milliseconds())
+	client, err := NewContainersClient(ctx, cfg, testThingID)
+	require.NoError(t, err)
+	defer client.Close()
+
+	featureID := GetContainerFeatureID("test")
+	require.NoError(t, client.Thing.PutFeature(ctx, featureID, &model.Feature{}))
+	put := putDelayed(ctx, client.Thing, featureID, containerPropertyState, &ContainerState{Status: ContainerStatusRunning})
+
+	state, err := client.WaitForStatus(ctx, "test", ContainerStatusRunning)
+	require.NoError(t, err)
+	require.Equal(t, ContainerStatusRunning, state.Status)
+	require.NoError(t, <-put)
+}
+
+func TestContainersClientWaitForRemovalOutlastsEventTimeout(t *testing.T) {
+	_, cfg := startTestDitto(t)
+	ctx, cancel := NewTestContext(t)
+	defer cancel()
+
+	thingURL := GetThingURL(cfg.DigitalTwinAPIAddress, GetContainersThingID(testThingID))
+	_, err := SendDigitalTwinRequest(ctx, cfg, http.MethodPut, thingURL, map[string]interface{}{})
+	require.NoError(t, err)
+	cfg.WSEventTimeoutMS = int(testEventTimeout.Milliseconds())
+	client, err := NewContainersClient(ctx, cfg, testThingID)
+	require.NoError(t, err)
+	defer client.Close()
+
+	featureID := GetContainerFeatureID("test")
+	require.NoError(t, client.Thing.PutFeature(ctx, featureID, &model.Feature{}))
+	deleted := make(chan error, 1)
+	time.AfterFunc(3*testEventTimeout, func() {
+		deleted <- client.Thing.DeleteFeature(ctx, featureID)
+	})
+
+	require.NoError(t, client.WaitForRemoval(ctx, "test"))
+	require.NoError(t, <-deleted)
+}
@@ -0,0 +1,209 @@
+// Copyright (c) 2026 Contributors to the Eclipse Foundation
+//
+// See the NOTICE file(s) distributed with this work for additional
+// information regarding copyright ownership.
+//
+// This program and the accompanying materials are made available under the
+// terms of the Eclipse Public License 2.0 which is available at
+// https://www.eclipse.org/legal/epl-2.0, or the Apache License, Version 2.0
+// which is available at https://www.apache.org/licenses/LICENSE-2.0.
+//
+// SPDX-License-Identifier: EPL-2.0 OR Apache-2.0
+
+package util
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/eclipse/ditto-clients-golang/protocol"
+)
+
+// UploadState is the state of a file upload
+type UploadState string
+
+const (
+	// UploadStatePending is reported when an upload is requested and waits to be started.
+	UploadStatePending UploadState = "PENDING"
+	// UploadStateUploading is reported while the file is uploaded.
+	UploadStateUploading UploadState = "UPLOADING"
+	// UploadStatePaused is reported when an upload is paused.
+	UploadStatePaused UploadState = "PAUSED"
+	// UploadStateSuccess is reported when the file is uploaded.
+	UploadStateSuccess UploadState = "SUCCESS"
+	// UploadStateFailed is reported when an upload fails.
+	UploadStateFailed UploadState = "FAILED"
+	// UploadStateCanceled is reported when an upload is canceled.
+	UploadStateCanceled UploadState = "CANCELED"
+
+	// UploadProviderGeneric uploads the files with HTTP requests to the upload URL
+	UploadProviderGeneric = "generic"
+	// UploadProviderAWS uploads the files to an AWS S3 bucket
+	UploadProviderAWS = "aws"
+	// UploadProviderAzure uploads the files to an Azure Blob Storage container
+	UploadProviderAzure = "azure"
+
+	uploadOptionProvider     = "storage.provider"
+	uploadOptionURL          = "https.url"
+	uploadOptionMethod       = "https.method"
+	uploadOptionHeaderPrefix = "https.header."
+
+	uploadOperationTrigger = "trigger"
+	uploadOperationStart   = "start"
+	uploadOperationCancel  = "cancel"
+	uploadMessageRequest   = "request"
+
+	uploadPropertyLastUpload = "lastUpload"
+)
+
+// IsFinal returns true if no more progress is reported for an upload in the state
+func (state UploadState) IsFinal() bool {
+	return state == UploadStateSuccess || state == UploadStateFailed || state == UploadStateCanceled
+}
+
+// UploadOptions are the options of a file upload, which select the storage provider and configure it
+type UploadOptions struct {
+	// StorageProvider is one of the upload providers, the generic provider is used by the device if empty
+	StorageProvider string
+	// URL is the HTTPS URL of the generic provider
+	URL string
+	// Method is the HTTP method of the generic provider, PUT is used by the device if empty
+	Method string
+	// Headers are the HTTP headers of the generic provider requests
+	Headers map[string]string
+	// Properties are the provider specific options, e.g. "aws.region" or "azure.container.name"
+	Properties map[string]string
+}
+
+// ToMap returns the options in the format of the upload operations
+func (options *UploadOptions) ToMap() map[string]string {
+	result := map[string]string{}
+	if options == nil {
+		return result
+	}
+	for name, value := range options.Properties {
+		result[name] = value
+	}
+	if options.StorageProvider != "" {
+		result[uploadOptionProvider] = options.StorageProvider
+	}
+	if options.URL != "" {
+		result[uploadOptionURL] = options.URL
+	}
+	if options.Method != "" {
+		result[uploadOptionMethod] = options.Method
+	}
+	for name, value := range options.Headers {
+		result[uploadOptionHeaderPrefix+name] = value
+	}
+	return result
+}
+
+// UploadAction is the parameter of the upload operations, e.g. trigger and start
+type UploadAction struct {
+	CorrelationID string            `json:"correlationId"`
+	Options       map[string]string `json:"options,omitempty"`
+}
+
+// UploadRequest is the value of the request message, which is sent from the feature outbox for each file to upload
+type UploadRequest struct {
+	CorrelationID string            `json:"correlationId"`
+	Options       map[string]string `json:"options,omitempty"`
+}
+
+// UploadStatus is the progress of a file upload, reported by the lastUpload property
+type UploadStatus struct {
+	CorrelationID string            `json:"correlationId"`
+	State         UploadState       `json:"state"`
+	Progress      int               `json:"progress,omitempty"`
+	Message       string            `json:"message,omitempty"`
+	StatusCode    string            `json:"statusCode,omitempty"`
+	StartTime     string            `json:"startTime,omitempty"`
+	EndTime       string            `json:"endTime,omitempty"`
+	Info          map[string]string `json:"info,omitempty"`
+}
+
+// isUploadCorrelated returns true if the upload ID is the correlation ID of the triggering operation
+// or is derived from it, as done for the requests of the individual files, e.g. "<correlation-id>#1"
+func isUploadCorrelated(uploadID string, correlationID string) bool {
+	return uploadID == correlationID || strings.HasPrefix(uploadID, correlationID+"#")
+}
+
+// awaitUpload starts the uploads requested by the feature for the correlation ID with the options returned for
+// each request and waits for as many uploads to reach a final state as are started. Uploads, which are not requested,
+// e.g. when the options are given with the triggering operation, are awaited as well. The optional check aborts
+// the waiting with an error, e.g. when the operation preceding the upload fails. Only the context bounds the waiting,
+// as uploading large files may take longer than the WebSocket event timeout.
+func awaitUpload(ctx context.Context, client *FeatureClient, correlationID string,
+	options func(*UploadRequest) *UploadOptions, progress func(*UploadStatus),
+	check func(*protocol.Envelope) error) (*UploadStatus, error) {
+	started := map[string]bool{}
+	finished := map[string]bool{}
+	var (
+		last   *UploadStatus
+		failed *UploadStatus
+	)
+	err := WatchWSMessages(ctx, client.ws, func(envelope *protocol.Envelope) (bool, error) {
+		if check != nil {
+			if err := check(envelope); err != nil {
+				return true, err
+			}
+		}
+
+		if value, ok := client.outboxMessageValue(envelope, uploadMessageRequest); ok {
+			request := &UploadRequest{}
+			if err := Convert(value, request); err != nil || !isUploadCorrelated(request.CorrelationID, correlationID) {
+				return false, nil
+			}
+			var startOptions *UploadOptions
+			if options != nil {
+				startOptions = options(request)
+			}
+			if startOptions == nil {
+				return true, fmt.Errorf("upload %s requested, but no upload options are specified", request.CorrelationID)
+			}
+			start := &UploadAction{CorrelationID: request.CorrelationID, Options: startOptions.ToMap()}
+			if _, err := client.ExecuteOperation(ctx, uploadOperationStart, start); err != nil {
+				return true, fmt.Errorf("unable to start upload %s: %v", request.CorrelationID, err)
+			}
+			started[request.CorrelationID] = true
+			return false, nil
+		}
+
+		value, ok := client.propertyFromEvent(envelope, uploadPropertyLastUpload)
+		if !ok {
+			return false, nil
+		}
+		status := &UploadStatus{}
+		if err := Convert(value, status); err != nil || !isUploadCorrelated(status.CorrelationID, correlationID) {
+			return false, nil
+		}
+		last = status
+		if progress != nil {
+			progress(status)
+		}
+		if !status.State.IsFinal() {
+			return false, nil
+		}
+		if status.State != UploadStateSuccess && failed == nil {
+			failed = status
+		}
+		finished[status.CorrelationID] = true
+		return len(finished) >= len(started), nil
+	})
+	if err != nil {
+		return last, fmt.Errorf("upload %s not finished: %v", correlationID, err)
+	}
+	if failed != nil {
+		return failed, fmt.Errorf("upload %s finished with state %s: %s", failed.CorrelationID, failed.State, failed.Message)
+	}
+	return last, nil
+}
+
+// fixedUploadOptions returns the same options for all upload requests
+func fixedUploadOptions(options *UploadOptions) func(*UploadRequest) *UploadOptions {
+	return func(*UploadRequest) *UploadOptions {
+		return options
+	}
+}
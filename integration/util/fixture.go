@@ -0,0 +1,136 @@
+// Copyright (c) 2026 Contributors to the Eclipse Foundation
+//
+// See the NOTICE file(s) distributed with this work for additional
+// information regarding copyright ownership.
+//
+// This program and the accompanying materials are made available under the
+// terms of the Eclipse Public License 2.0 which is available at
+// https://www.eclipse.org/legal/epl-2.0, or the Apache License, Version 2.0
+// which is available at https://www.apache.org/licenses/LICENSE-2.0.
+//
+// SPDX-License-Identifier: EPL-2.0 OR Apache-2.0
+
+package util
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/caarlos0/env/v6"
+
+	"github.com/eclipse/ditto-clients-golang/model"
+
+	"github.com/google/uuid"
+
+	"github.com/stretchr/testify/require"
+)
+
+const fixtureChildPassword = "fixture"
+
+var fixtureNameInvalidChars = regexp.MustCompile("[^a-zA-Z0-9_-]+")
+
+// ThingFixture is a thing or a feature created for a single test, which is deleted when the test completes
+type ThingFixture struct {
+	ThingID string
+	// FeatureID is only set for feature fixtures
+	FeatureID string
+
+	// Client is bound to the fixture's thing, it accesses the local digital twin,
+	// if selected by the test configuration
+	Client TwinClient
+}
+
+// NewFeatureFixture creates a uniquely named feature on the device thing for the given test,
+// using the twin client of the suite. The feature is deleted when the test completes.
+func (suite *SuiteInitializer) NewFeatureFixture(t *testing.T, feature *model.Feature) *ThingFixture {
+	if feature == nil {
+		feature = &model.Feature{}
+	}
+
+	ctx, cancel := suite.NewTestContext(t)
+	defer cancel()
+	recorder := TrafficRecorderFromContext(ctx)
+
+	client := suite.NewTwinClient(suite.ThingCfg.DeviceID)
+	featureID := newFixtureName(t)
+	require.NoError(t, client.PutFeature(ctx, featureID, feature), "create fixture feature %s", featureID)
+
+	t.Cleanup(func() {
+		ctx, cancel := newCleanupContext(recorder)
+		defer cancel()
+
+		if err := client.DeleteFeature(ctx, featureID); err != nil {
+			t.Logf("unable to delete fixture feature %s: %v", featureID, err)
+		}
+	})
+
+	return &ThingFixture{
+		ThingID:   suite.ThingCfg.DeviceID,
+		FeatureID: featureID,
+		Client:    client,
+	}
+}
+
+// NewChildThingFixture creates a uniquely named child device of the test device for the given test,
+// both in the device registry and as a thing. The device registry configuration is read from the environment.
+// The device and the thing are deleted when the test completes.
+func (suite *SuiteInitializer) NewChildThingFixture(t *testing.T) *ThingFixture {
+	ctx, cancel := suite.NewTestContext(t)
+	defer cancel()
+	recorder := TrafficRecorderFromContext(ctx)
+
+	registryCfg := &DeviceRegistryConfiguration{}
+	opts := env.Options{RequiredIfNoDef: true}
+	require.NoError(t, env.Parse(registryCfg, opts), "failed to process device registry environment variables")
+
+	policyID := suite.ThingCfg.PolicyID
+	if policyID == "" {
+		// Reuse the policy of the test device, if not reported by the thing configuration
+		deviceClient := NewThingClient(suite.Cfg, suite.ThingCfg.DeviceID)
+		require.NoError(t, deviceClient.get(ctx, deviceClient.ThingURL+"/policyId", &policyID), "get device policy ID")
+	}
+
+	thingID := fmt.Sprintf("%s:%s", suite.ThingCfg.DeviceID, newFixtureName(t))
+	registryAPI := strings.TrimSuffix(registryCfg.DeviceRegistryAPIAddress, "/") + "/v1"
+	tenantURL := fmt.Sprintf("%s/devices/%s/", registryAPI, suite.ThingCfg.TenantID)
+
+	resources := CreateDeviceResources(thingID, suite.ThingCfg.TenantID, policyID,
+		fixtureChildPassword, registryAPI, registryCfg.DeviceRegistryAPIUsername,
+		registryCfg.DeviceRegistryAPIPassword, suite.Cfg)
+	// Connect the child device via the test device
+	resources[0].Body = fmt.Sprintf(`{"via":["%s"]}`, suite.ThingCfg.DeviceID)
+
+	err := RegisterDeviceResources(ctx, suite.Cfg, resources, thingID, tenantURL,
+		registryCfg.DeviceRegistryAPIUsername, registryCfg.DeviceRegistryAPIPassword)
+	require.NoError(t, err, "create fixture child thing %s", thingID)
+
+	t.Cleanup(func() {
+		ctx, cancel := newCleanupContext(recorder)
+		defer cancel()
+
+		if err := DeleteResources(ctx, suite.Cfg, resources, thingID, tenantURL,
+			registryCfg.DeviceRegistryAPIUsername, registryCfg.DeviceRegistryAPIPassword); err != nil {
+			t.Logf("unable to delete fixture child thing %s: %v", thingID, err)
+		}
+	})
+
+	return &ThingFixture{
+		ThingID: thingID,
+		Client:  suite.NewTwinClient(thingID),
+	}
+}
+
+// newCleanupContext returns a context for cleaning up a fixture, which is not bound to the test context,
+// as it may have already expired. The recorder of the test context is passed, its file is still open
+// during the cleanups registered after the test context is created.
+func newCleanupContext(recorder *TrafficRecorder) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(WithTrafficRecorder(context.Background(), recorder), testDeadlineGrace)
+}
+
+func newFixtureName(t *testing.T) string {
+	name := strings.Trim(fixtureNameInvalidChars.ReplaceAllString(t.Name(), "-"), "-")
+	return fmt.Sprintf("%s-%s", name, uuid.New().String()[:8])
+}
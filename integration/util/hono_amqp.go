@@ -0,0 +1,241 @@
+// Copyright (c) 2026 Contributors to the Eclipse Foundation
+//
+// See the NOTICE file(s) distributed with this work for additional
+// information regarding copyright ownership.
+//
+// This program and the accompanying materials are made available under the
+// terms of the Eclipse Public License 2.0 which is available at
+// https://www.eclipse.org/legal/epl-2.0, or the Apache License, Version 2.0
+// which is available at https://www.apache.org/licenses/LICENSE-2.0.
+//
+// SPDX-License-Identifier: EPL-2.0 OR Apache-2.0
+
+package util
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Azure/go-amqp"
+)
+
+const (
+	honoAMQPPropertyDeviceID = "device_id"
+	honoAMQPPropertyStatus   = "status"
+
+	honoAMQPReceiverCredit = 100
+	honoAMQPCloseTimeout   = 5 * time.Second
+)
+
+// HonoAMQPConnection is an AMQP 1.0 connection to the Hono northbound messaging API
+type HonoAMQPConnection struct {
+	conn    *amqp.Conn
+	session *amqp.Session
+
+	mutex   sync.Mutex
+	senders map[string]*amqp.Sender
+}
+
+type honoAMQPReceiver struct {
+	receiver *amqp.Receiver
+}
+
+// NewHonoAMQPConnection connects to the Hono AMQP messaging network with SASL PLAIN authentication.
+// TLS is used for addresses with the amqps scheme.
+func NewHonoAMQPConnection(ctx context.Context, cfg *HonoConfiguration) (*HonoAMQPConnection, error) {
+	opts := &amqp.ConnOptions{
+		SASLType: amqp.SASLTypePlain(cfg.HonoAMQPUsername, cfg.HonoAMQPPassword),
+	}
+	if strings.HasPrefix(cfg.HonoAMQPAddress, "amqps://") {
+		tlsConfig, err := NewTLSConfig(cfg.HonoAMQPCACert, "", "")
+		if err != nil {
+			return nil, err
+		}
+		opts.TLSConfig = tlsConfig
+	}
+
+	conn, err := amqp.Dial(ctx, cfg.HonoAMQPAddress, opts)
+	if err != nil {
+		return nil, fmt.Errorf("unable to connect to Hono AMQP messaging at %s: %v", cfg.HonoAMQPAddress, err)
+	}
+	session, err := conn.NewSession(ctx, nil)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("unable to create AMQP session: %v", err)
+	}
+	return &HonoAMQPConnection{conn: conn, session: session, senders: map[string]*amqp.Sender{}}, nil
+}
+
+// Receive starts receiving the messages sent to the address
+func (connection *HonoAMQPConnection) Receive(ctx context.Context, address string) (HonoReceiver, error) {
+	receiver, err := connection.session.NewReceiver(ctx, address, &amqp.ReceiverOptions{
+		Credit: honoAMQPReceiverCredit,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to receive from %s: %v", address, err)
+	}
+	return &honoAMQPReceiver{receiver: receiver}, nil
+}
+
+// Send sends a message to the address. The sender links are reused for subsequent messages to the same address.
+func (connection *HonoAMQPConnection) Send(ctx context.Context, address string, message *HonoMessage) error {
+	sender, err := connection.sender(ctx, address)
+	if err != nil {
+		return err
+	}
+	if err := sender.Send(ctx, toAMQPMessage(message), nil); err != nil {
+		return fmt.Errorf("unable to send message to %s: %v", address, err)
+	}
+	return nil
+}
+
+func (connection *HonoAMQPConnection) sender(ctx context.Context, address string) (*amqp.Sender, error) {
+	connection.mutex.Lock()
+	defer connection.mutex.Unlock()
+
+	if sender, ok := connection.senders[address]; ok {
+		return sender, nil
+	}
+	sender, err := connection.session.NewSender(ctx, address, nil)
+	if err != nil {
+		return nil, fmt.Errorf("unable to send to %s: %v", address, err)
+	}
+	connection.senders[address] = sender
+	return sender, nil
+}
+
+// Close closes the connection with all of its links
+func (connection *HonoAMQPConnection) Close() error {
+	return connection.conn.Close()
+}
+
+// Receive waits for the next message and accepts it
+func (receiver *honoAMQPReceiver) Receive(ctx context.Context) (*HonoMessage, error) {
+	message, err := receiver.receiver.Receive(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	if err := receiver.receiver.AcceptMessage(ctx, message); err != nil {
+		return nil, fmt.Errorf("unable to accept message: %v", err)
+	}
+	return fromAMQPMessage(message), nil
+}
+
+// Close detaches the receiver link
+func (receiver *honoAMQPReceiver) Close() error {
+	ctx, cancel := context.WithTimeout(context.Background(), honoAMQPCloseTimeout)
+	defer cancel()
+	return receiver.receiver.Close(ctx)
+}
+
+func toAMQPMessage(message *HonoMessage) *amqp.Message {
+	properties := &amqp.MessageProperties{}
+	if message.MessageID != "" {
+		properties.MessageID = message.MessageID
+	}
+	if message.CorrelationID != "" {
+		properties.CorrelationID = message.CorrelationID
+	}
+	if message.Address != "" {
+		properties.To = &message.Address
+	}
+	if message.ReplyTo != "" {
+		properties.ReplyTo = &message.ReplyTo
+	}
+	if message.Subject != "" {
+		properties.Subject = &message.Subject
+	}
+	if message.ContentType != "" {
+		properties.ContentType = &message.ContentType
+	}
+
+	applicationProperties := map[string]interface{}{}
+	for name, value := range message.Properties {
+		applicationProperties[name] = value
+	}
+	if message.DeviceID != "" {
+		applicationProperties[honoAMQPPropertyDeviceID] = message.DeviceID
+	}
+	if message.Status != 0 {
+		applicationProperties[honoAMQPPropertyStatus] = int32(message.Status)
+	}
+
+	return &amqp.Message{
+		Properties:            properties,
+		ApplicationProperties: applicationProperties,
+		Data:                  [][]byte{message.Payload},
+	}
+}
+
+func fromAMQPMessage(message *amqp.Message) *HonoMessage {
+	result := &HonoMessage{
+		Properties: map[string]interface{}{},
+		Payload:    message.GetData(),
+	}
+	if result.Payload == nil {
+		switch value := message.Value.(type) {
+		case []byte:
+			result.Payload = value
+		case string:
+			result.Payload = []byte(value)
+		}
+	}
+
+	if properties := message.Properties; properties != nil {
+		result.MessageID = amqpIDString(properties.MessageID)
+		result.CorrelationID = amqpIDString(properties.CorrelationID)
+		result.Address = amqpString(properties.To)
+		result.ReplyTo = amqpString(properties.ReplyTo)
+		result.Subject = amqpString(properties.Subject)
+		result.ContentType = amqpString(properties.ContentType)
+	}
+
+	for name, value := range message.ApplicationProperties {
+		switch name {
+		case honoAMQPPropertyDeviceID:
+			result.DeviceID = fmt.Sprint(value)
+		case honoAMQPPropertyStatus:
+			result.Status = amqpInt(value)
+		default:
+			result.Properties[name] = value
+		}
+	}
+	return result
+}
+
+func amqpString(value *string) string {
+	if value == nil {
+		return ""
+	}
+	return *value
+}
+
+func amqpIDString(id interface{}) string {
+	switch value := id.(type) {
+	case nil:
+		return ""
+	case []byte:
+		return string(value)
+	default:
+		return fmt.Sprint(value)
+	}
+}
+
+func amqpInt(value interface{}) int {
+	switch number := value.(type) {
+	case int32:
+		return int(number)
+	case int64:
+		return int(number)
+	case int:
+		return number
+	case uint32:
+		return int(number)
+	case int16:
+		return int(number)
+	}
+	return 0
+}
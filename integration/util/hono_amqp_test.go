@@ -0,0 +1,199 @@
+// Copyright (c) 2026 Contributors to the Eclipse Foundation
+//
+// See the NOTICE file(s) distributed with this work for additional
+// information regarding copyright ownership.
+//
+// This program and the accompanying materials are made available under the
+// terms of the Eclipse Public License 2.0 which is available at
+// https://www.eclipse.org/legal/epl-2.0, or the Apache License, Version 2.0
+// which is available at https://www.apache.org/licenses/LICENSE-2.0.
+//
+// SPDX-License-Identifier: EPL-2.0 OR Apache-2.0
+
+package util
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/Azure/go-amqp"
+	"github.com/eclipse/ditto-clients-golang/protocol"
+	"github.com/stretchr/testify/require"
+)
+
+const testTenantID = "test"
+
+// startTestHonoAMQP starts a local broker and a fake Hono served over AMQP,
+// and returns a Hono client connected to it with the AMQP messaging
+func startTestHonoAMQP(t *testing.T) (*LocalBroker, *HonoClient) {
+	broker, cfg := startTestBroker(t)
+	ctx, cancel := NewTestContext(t)
+	defer cancel()
+
+	hono, err := StartFakeHono(ctx, cfg, &ThingConfiguration{DeviceID: testThingID, TenantID: testTenantID})
+	require.NoError(t, err)
+	t.Cleanup(func() { hono.Close() })
+	address, err := hono.ListenAMQP("consumer", "secret")
+	require.NoError(t, err)
+
+	connection, err := NewHonoAMQPConnection(ctx, &HonoConfiguration{
+		HonoAMQPAddress:  address,
+		HonoAMQPUsername: "consumer",
+		HonoAMQPPassword: "secret",
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() { connection.Close() })
+	return broker, NewHonoClient(connection, testTenantID)
+}
+
+func newTestEnvelope(t *testing.T, path string, value interface{}) []byte {
+	envelope := (&protocol.Envelope{}).
+		WithTopic(newThingTopic(testThingID, protocol.ChannelTwin, protocol.CriterionCommands, protocol.ActionModify)).
+		WithHeaders(protocol.NewHeaders(protocol.WithCorrelationID("test-correlation"))).
+		WithPath(path).
+		WithValue(value)
+	data, err := json.Marshal(envelope)
+	require.NoError(t, err)
+	return data
+}
+
+func TestHonoAMQPConsumeEventsAndTelemetry(t *testing.T) {
+	broker, client := startTestHonoAMQP(t)
+	ctx, cancel := NewTestContext(t)
+	defer cancel()
+
+	events, err := client.ConsumeEvents(ctx)
+	require.NoError(t, err)
+	defer events.Close()
+	telemetry, err := client.ConsumeTelemetry(ctx)
+	require.NoError(t, err)
+	defer telemetry.Close()
+
+	require.NoError(t, broker.Publish(GetEventTopic("", ""), newTestEnvelope(t, "/attributes/event", 1), 1))
+	require.NoError(t, broker.Publish(GetTelemetryTopic("", ""), newTestEnvelope(t, "/attributes/telemetry", 2), 0))
+
+	message, envelope, err := WaitForHonoEnvelope(ctx, events, testThingID, nil)
+	require.NoError(t, err)
+	require.Equal(t, HonoContentTypeDitto, message.ContentType)
+	require.Equal(t, GetHonoEventAddress(testTenantID), message.Address)
+	require.Equal(t, "/attributes/event", envelope.Path)
+
+	message, envelope, err = WaitForHonoEnvelope(ctx, telemetry, testThingID, nil)
+	require.NoError(t, err)
+	require.Equal(t, GetHonoTelemetryAddress(testTenantID), message.Address)
+	require.Equal(t, "/attributes/telemetry", envelope.Path)
+}
+
+func TestHonoAMQPConsumeLargeEvent(t *testing.T) {
+	broker, cfg := startTestBroker(t)
+	ctx, cancel := NewTestContext(t)
+	defer cancel()
+
+	hono, err := StartFakeHono(ctx, cfg, &ThingConfiguration{DeviceID: testThingID, TenantID: testTenantID})
+	require.NoError(t, err)
+	defer hono.Close()
+	address, err := hono.ListenAMQP("consumer", "secret")
+	require.NoError(t, err)
+
+	conn, err := amqp.Dial(ctx, address, &amqp.ConnOptions{
+		SASLType:     amqp.SASLTypePlain("consumer", "secret"),
+		MaxFrameSize: 1024,
+	})
+	require.NoError(t, err)
+	defer conn.Close()
+	session, err := conn.NewSession(ctx, nil)
+	require.NoError(t, err)
+	receiver, err := session.NewReceiver(ctx, GetHonoEventAddress(testTenantID), nil)
+	require.NoError(t, err)
+
+	// The event is split in multiple transfers to stay within the max frame size of the client
+	value := strings.Repeat("x", 4096)
+	require.NoError(t, broker.Publish(GetEventTopic("", ""), newTestEnvelope(t, "/attributes/large", value), 1))
+
+	message, err := receiver.Receive(ctx, nil)
+	require.NoError(t, err)
+	require.NoError(t, receiver.AcceptMessage(ctx, message))
+	envelope := &protocol.Envelope{}
+	require.NoError(t, json.Unmarshal(message.GetData(), envelope))
+	require.Equal(t, "/attributes/large", envelope.Path)
+	require.Equal(t, value, envelope.Value)
+}
+
+func TestHonoAMQPOneWayCommand(t *testing.T) {
+	broker, client := startTestHonoAMQP(t)
+	ctx, cancel := NewTestContext(t)
+	defer cancel()
+
+	type request struct {
+		topic   string
+		payload []byte
+	}
+	requests := make(chan request, 1)
+	unsubscribe, err := broker.Subscribe(CommandRequestsTopicFilter, func(topic string, payload []byte) {
+		requests <- request{topic: topic, payload: payload}
+	})
+	require.NoError(t, err)
+	defer unsubscribe()
+
+	require.NoError(t, client.SendOneWayCommand(ctx, testThingID, "modify", map[string]interface{}{"value": 1}))
+
+	select {
+	case received := <-requests:
+		require.Equal(t, GetCommandRequestTopic(testThingID, "", "modify"), received.topic)
+		require.JSONEq(t, `{"value":1}`, string(received.payload))
+	case <-ctx.Done():
+		t.Fatal("command not received by the device")
+	}
+}
+
+func TestHonoAMQPCommandResponse(t *testing.T) {
+	broker, client := startTestHonoAMQP(t)
+	ctx, cancel := NewTestContext(t)
+	defer cancel()
+
+	unsubscribe, err := broker.Subscribe(CommandRequestsTopicFilter, func(topic string, payload []byte) {
+		commandTopic, err := ParseCommandTopic(topic)
+		if err != nil || commandTopic.RequestID == "" {
+			return
+		}
+		response := []byte(`{"echo":` + string(payload) + `}`)
+		// The broker calls the handler synchronously, so the response is published asynchronously
+		go broker.Publish(GetCommandResponseTopic(commandTopic.DeviceID, commandTopic.RequestID, http.StatusAccepted),
+			response, 1)
+	})
+	require.NoError(t, err)
+	defer unsubscribe()
+
+	response, err := client.SendCommand(ctx, testThingID, "echo", "hello")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusAccepted, response.Status)
+	require.Equal(t, testThingID, response.DeviceID)
+	require.NotEmpty(t, response.CorrelationID)
+	require.JSONEq(t, `{"echo":"hello"}`, string(response.Payload))
+
+	// The sender link of the command address is reused for the next command
+	response, err = client.SendCommand(ctx, testThingID, "echo", "again")
+	require.NoError(t, err)
+	require.JSONEq(t, `{"echo":"again"}`, string(response.Payload))
+}
+
+func TestHonoAMQPInvalidCredentials(t *testing.T) {
+	_, cfg := startTestBroker(t)
+	ctx, cancel := NewTestContext(t)
+	defer cancel()
+
+	hono, err := StartFakeHono(ctx, cfg, &ThingConfiguration{DeviceID: testThingID, TenantID: testTenantID})
+	require.NoError(t, err)
+	defer hono.Close()
+	address, err := hono.ListenAMQP("consumer", "secret")
+	require.NoError(t, err)
+
+	_, err = NewHonoAMQPConnection(ctx, &HonoConfiguration{
+		HonoAMQPAddress:  address,
+		HonoAMQPUsername: "consumer",
+		HonoAMQPPassword: "invalid",
+	})
+	require.Error(t, err)
+}
@@ -0,0 +1,255 @@
+// Copyright (c) 2026 Contributors to the Eclipse Foundation
+//
+// See the NOTICE file(s) distributed with this work for additional
+// information regarding copyright ownership.
+//
+// This program and the accompanying materials are made available under the
+// terms of the Eclipse Public License 2.0 which is available at
+// https://www.eclipse.org/legal/epl-2.0, or the Apache License, Version 2.0
+// which is available at https://www.apache.org/licenses/LICENSE-2.0.
+//
+// SPDX-License-Identifier: EPL-2.0 OR Apache-2.0
+
+package util
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+
+	"github.com/google/uuid"
+
+	MQTT "github.com/eclipse/paho.mqtt.golang"
+)
+
+// fakeHonoReceiverBuffer limits the messages kept for a receiver, which is not read fast enough
+const fakeHonoReceiverBuffer = 1000
+
+// FakeHono is an in-memory stand-in for the Hono northbound messaging API, which allows testing the Hono clients
+// without a Hono cluster. It connects to the local broker on behalf of the configured thing like the MQTT adapter:
+// device events and telemetry are delivered to the event and telemetry receivers of their tenant, commands
+// are published to the devices as command requests and the command responses are delivered to the reply-to
+// addresses of the commands. Messages sent to other addresses are delivered to their receivers as they are.
+// The fake Hono can also be served over AMQP 1.0 to test the AMQP messaging client.
+type FakeHono struct {
+	cfg      *TestConfiguration
+	client   MQTT.Client
+	thingCfg *ThingConfiguration
+
+	mutex     sync.Mutex
+	receivers map[string]map[*fakeHonoReceiver]bool
+	replies   map[string]*HonoMessage
+	closed    bool
+
+	amqpListeners   []net.Listener
+	amqpConnections map[*fakeHonoAMQPConnection]bool
+}
+
+type fakeHonoReceiver struct {
+	hono     *FakeHono
+	address  string
+	messages chan *HonoMessage
+	done     chan struct{}
+	once     sync.Once
+}
+
+// StartFakeHono connects to the local broker from the test configuration
+// and starts bridging it to the Hono messaging receivers
+func StartFakeHono(ctx context.Context, cfg *TestConfiguration, thingCfg *ThingConfiguration) (*FakeHono, error) {
+	client, err := NewMQTTClient(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	hono := &FakeHono{
+		cfg:       cfg,
+		client:    client,
+		thingCfg:  thingCfg,
+		receivers: map[string]map[*fakeHonoReceiver]bool{},
+		replies:   map[string]*HonoMessage{},
+
+		amqpConnections: map[*fakeHonoAMQPConnection]bool{},
+	}
+
+	filters := map[string]byte{CommandResponsesTopicFilter: 1}
+	for _, topic := range connectorEmulatorDeviceTopics {
+		filters[topic] = 1
+	}
+	subscribeCtx, cancel := context.WithTimeout(ctx, MillisToDuration(cfg.MQTTAcknowledgeTimeoutMS))
+	defer cancel()
+	if err := waitForToken(subscribeCtx, client.SubscribeMultiple(filters, hono.handle)); err != nil {
+		client.Disconnect(uint(cfg.MQTTQuiesceMS))
+		return nil, fmt.Errorf("unable to subscribe to device topics: %v", err)
+	}
+	return hono, nil
+}
+
+// Receive starts receiving the messages sent to the address
+func (hono *FakeHono) Receive(ctx context.Context, address string) (HonoReceiver, error) {
+	hono.mutex.Lock()
+	defer hono.mutex.Unlock()
+
+	if hono.closed {
+		return nil, errors.New("fake Hono is closed")
+	}
+	receiver := &fakeHonoReceiver{
+		hono:     hono,
+		address:  address,
+		messages: make(chan *HonoMessage, fakeHonoReceiverBuffer),
+		done:     make(chan struct{}),
+	}
+	if hono.receivers[address] == nil {
+		hono.receivers[address] = map[*fakeHonoReceiver]bool{}
+	}
+	hono.receivers[address][receiver] = true
+	return receiver, nil
+}
+
+// Send publishes the commands sent to the command address of the tenant to the devices.
+// Messages sent to other addresses are delivered to their receivers.
+func (hono *FakeHono) Send(ctx context.Context, address string, message *HonoMessage) error {
+	if address != GetHonoCommandAddress(hono.thingCfg.TenantID, "") {
+		hono.deliver(address, message)
+		return nil
+	}
+
+	_, deviceID, err := ParseHonoCommandAddress(message.Address)
+	if err != nil {
+		return err
+	}
+	var requestID string
+	if message.ReplyTo != "" {
+		requestID = uuid.New().String()
+		hono.mutex.Lock()
+		hono.replies[requestID] = message
+		hono.mutex.Unlock()
+	}
+	return publishJSON(ctx, hono.cfg, hono.client, GetCommandRequestTopic(deviceID, requestID, message.Subject),
+		qosCommand, json.RawMessage(message.Payload))
+}
+
+// Close disconnects from the local broker, stops listening for AMQP connections and closes all receivers
+func (hono *FakeHono) Close() error {
+	hono.mutex.Lock()
+	hono.closed = true
+	var receivers []*fakeHonoReceiver
+	for _, addressReceivers := range hono.receivers {
+		for receiver := range addressReceivers {
+			receivers = append(receivers, receiver)
+		}
+	}
+	listeners := hono.amqpListeners
+	var connections []net.Conn
+	for connection := range hono.amqpConnections {
+		connections = append(connections, connection.conn)
+	}
+	hono.mutex.Unlock()
+
+	for _, listener := range listeners {
+		listener.Close()
+	}
+	for _, conn := range connections {
+		conn.Close()
+	}
+	for _, receiver := range receivers {
+		receiver.Close()
+	}
+	hono.client.Disconnect(uint(hono.cfg.MQTTQuiesceMS))
+	return nil
+}
+
+func (hono *FakeHono) handle(client MQTT.Client, message MQTT.Message) {
+	topic := message.Topic()
+	if commandTopic, err := ParseCommandTopic(topic); err == nil {
+		if !commandTopic.Request {
+			hono.resolve(commandTopic, message.Payload())
+		}
+		return
+	}
+
+	deviceTopic, err := ParseDeviceTopic(topic)
+	if err != nil {
+		return
+	}
+	tenantID, deviceID := deviceTopic.TenantID, deviceTopic.DeviceID
+	if tenantID == "" {
+		tenantID = hono.thingCfg.TenantID
+	}
+	if deviceID == "" {
+		deviceID = hono.thingCfg.DeviceID
+	}
+
+	address := GetHonoEventAddress(tenantID)
+	if deviceTopic.Kind == KindTelemetry {
+		address = GetHonoTelemetryAddress(tenantID)
+	}
+	hono.deliver(address, &HonoMessage{
+		MessageID:   uuid.New().String(),
+		Address:     address,
+		ContentType: HonoContentTypeDitto,
+		DeviceID:    deviceID,
+		Payload:     message.Payload(),
+	})
+}
+
+func (hono *FakeHono) resolve(commandTopic *CommandTopic, payload []byte) {
+	hono.mutex.Lock()
+	command, ok := hono.replies[commandTopic.RequestID]
+	delete(hono.replies, commandTopic.RequestID)
+	hono.mutex.Unlock()
+	if !ok {
+		return
+	}
+
+	deviceID := commandTopic.DeviceID
+	if deviceID == "" {
+		deviceID = command.DeviceID
+	}
+	hono.deliver(command.ReplyTo, &HonoMessage{
+		MessageID:     uuid.New().String(),
+		CorrelationID: command.CorrelationID,
+		Address:       command.ReplyTo,
+		ContentType:   HonoContentTypeDitto,
+		DeviceID:      deviceID,
+		Status:        commandTopic.Status,
+		Payload:       payload,
+	})
+}
+
+func (hono *FakeHono) deliver(address string, message *HonoMessage) {
+	hono.mutex.Lock()
+	defer hono.mutex.Unlock()
+
+	for receiver := range hono.receivers[address] {
+		select {
+		case receiver.messages <- message:
+		default:
+		}
+	}
+}
+
+// Receive waits for the next message sent to the address of the receiver
+func (receiver *fakeHonoReceiver) Receive(ctx context.Context) (*HonoMessage, error) {
+	select {
+	case message := <-receiver.messages:
+		return message, nil
+	case <-receiver.done:
+		return nil, fmt.Errorf("receiver of %s is closed", receiver.address)
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// Close stops receiving messages
+func (receiver *fakeHonoReceiver) Close() error {
+	receiver.once.Do(func() {
+		receiver.hono.mutex.Lock()
+		delete(receiver.hono.receivers[receiver.address], receiver)
+		receiver.hono.mutex.Unlock()
+		close(receiver.done)
+	})
+	return nil
+}
@@ -0,0 +1,527 @@
+// Copyright (c) 2026 Contributors to the Eclipse Foundation
+//
+// See the NOTICE file(s) distributed with this work for additional
+// information regarding copyright ownership.
+//
+// This program and the accompanying materials are made available under the
+// terms of the Eclipse Public License 2.0 which is available at
+// https://www.eclipse.org/legal/epl-2.0, or the Apache License, Version 2.0
+// which is available at https://www.apache.org/licenses/LICENSE-2.0.
+//
+// SPDX-License-Identifier: EPL-2.0 OR Apache-2.0
+
+package util
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/Azure/go-amqp"
+)
+
+const (
+	fakeHonoAMQPMaxFrameSize = 65536
+	fakeHonoAMQPWindow       = 5000
+	fakeHonoAMQPCredit       = 100
+)
+
+// fakeHonoAMQPConnection serves a single AMQP 1.0 client connection, which is authenticated with SASL PLAIN.
+// The sender links of the client send their messages to the fake Hono and the receiver links of the client
+// receive from it as long as the client issues credit.
+type fakeHonoAMQPConnection struct {
+	hono   *FakeHono
+	conn   net.Conn
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	// the max frame size of the client, which limits the frames of the transfers to it
+	maxFrameSize uint32
+
+	writeMutex sync.Mutex
+	sessions   map[uint16]*fakeHonoAMQPSession
+}
+
+type fakeHonoAMQPSession struct {
+	connection *fakeHonoAMQPConnection
+	channel    uint16
+
+	mutex          sync.Mutex
+	nextIncomingID uint32
+	nextOutgoingID uint32
+
+	// the links are only accessed by the connection reader
+	links      map[uint32]*fakeHonoAMQPLink
+	nextHandle uint32
+}
+
+type fakeHonoAMQPLink struct {
+	session *fakeHonoAMQPSession
+	handle  uint32
+	address string
+
+	// the messages received on a sender link of the client, which may be split in multiple transfers
+	deliveryID uint32
+	settled    bool
+	payload    []byte
+
+	// the messages sent on a receiver link of the client
+	receiver HonoReceiver
+	cancel   context.CancelFunc
+	credited chan struct{}
+
+	mutex         sync.Mutex
+	deliveryCount uint32
+	credit        uint32
+	detached      bool
+}
+
+// ListenAMQP starts serving the fake Hono messaging over AMQP 1.0 to clients authenticated
+// with the credentials and returns the address to connect to. It stops listening when the fake Hono is closed.
+func (hono *FakeHono) ListenAMQP(username, password string) (string, error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return "", fmt.Errorf("unable to listen for AMQP connections: %v", err)
+	}
+
+	hono.mutex.Lock()
+	if hono.closed {
+		hono.mutex.Unlock()
+		listener.Close()
+		return "", errors.New("fake Hono is closed")
+	}
+	hono.amqpListeners = append(hono.amqpListeners, listener)
+	hono.mutex.Unlock()
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go hono.serveAMQP(conn, username, password)
+		}
+	}()
+	return "amqp://" + listener.Addr().String(), nil
+}
+
+func (hono *FakeHono) serveAMQP(conn net.Conn, username, password string) {
+	ctx, cancel := context.WithCancel(context.Background())
+	connection := &fakeHonoAMQPConnection{
+		hono:     hono,
+		conn:     conn,
+		ctx:      ctx,
+		cancel:   cancel,
+		sessions: map[uint16]*fakeHonoAMQPSession{},
+	}
+
+	hono.mutex.Lock()
+	if hono.closed {
+		hono.mutex.Unlock()
+		conn.Close()
+		return
+	}
+	hono.amqpConnections[connection] = true
+	hono.mutex.Unlock()
+
+	defer func() {
+		hono.mutex.Lock()
+		delete(hono.amqpConnections, connection)
+		hono.mutex.Unlock()
+		connection.close()
+	}()
+
+	if err := connection.authenticate(username, password); err != nil {
+		return
+	}
+	if err := connection.open(); err != nil {
+		return
+	}
+	for {
+		frame, err := readAMQPFrame(conn)
+		if err != nil {
+			return
+		}
+		if frame.body == nil {
+			continue
+		}
+		if closed, err := connection.handle(frame); closed || err != nil {
+			return
+		}
+	}
+}
+
+// authenticate exchanges the SASL and AMQP protocol headers and accepts only the PLAIN mechanism
+func (connection *fakeHonoAMQPConnection) authenticate(username, password string) error {
+	if err := connection.readProtocolHeader(amqpSASLHeader); err != nil {
+		return err
+	}
+	if err := connection.write(amqpFrameTypeSASL, 0, newAMQPPerformative(amqpSASLMechanisms, amqpSymbol("PLAIN"))); err != nil {
+		return err
+	}
+
+	frame, err := readAMQPFrame(connection.conn)
+	if err != nil {
+		return err
+	}
+	if frame.body == nil || frame.body.descriptor != amqpSASLInit {
+		return errors.New("SASL init expected")
+	}
+	response, _ := frame.body.field(1).([]byte)
+	credentials := bytes.Split(response, []byte{0})
+	var code uint8
+	if frame.body.stringField(0) != "PLAIN" || len(credentials) != 3 ||
+		string(credentials[1]) != username || string(credentials[2]) != password {
+		code = 1
+	}
+	if err := connection.write(amqpFrameTypeSASL, 0, newAMQPPerformative(amqpSASLOutcome, code)); err != nil {
+		return err
+	}
+	if code != 0 {
+		return errors.New("authentication failed")
+	}
+	return connection.readProtocolHeader(amqpProtocolHeader)
+}
+
+func (connection *fakeHonoAMQPConnection) readProtocolHeader(expected []byte) error {
+	header := make([]byte, len(expected))
+	if _, err := io.ReadFull(connection.conn, header); err != nil {
+		return err
+	}
+	connection.writeMutex.Lock()
+	_, err := connection.conn.Write(expected)
+	connection.writeMutex.Unlock()
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(header, expected) {
+		return fmt.Errorf("unexpected protocol header %v", header)
+	}
+	return nil
+}
+
+// open answers the open of the client and keeps the connection alive within the idle timeout of the client
+func (connection *fakeHonoAMQPConnection) open() error {
+	frame, err := readAMQPFrame(connection.conn)
+	if err != nil {
+		return err
+	}
+	if frame.body == nil || frame.body.descriptor != amqpOpen {
+		return errors.New("open expected")
+	}
+	connection.maxFrameSize = fakeHonoAMQPMaxFrameSize
+	if maxFrameSize, ok := frame.body.uintField(2); ok {
+		if maxFrameSize < amqpMinMaxFrameSize {
+			return fmt.Errorf("invalid max frame size %d", maxFrameSize)
+		}
+		if maxFrameSize < connection.maxFrameSize {
+			connection.maxFrameSize = maxFrameSize
+		}
+	}
+	if err := connection.write(amqpFrameTypeAMQP, 0, newAMQPPerformative(amqpOpen,
+		"fake-hono", nil, uint32(fakeHonoAMQPMaxFrameSize), uint16(255))); err != nil {
+		return err
+	}
+
+	if idleTimeout, ok := frame.body.uintField(4); ok && idleTimeout > 0 {
+		go func() {
+			ticker := time.NewTicker(MillisToDuration(int(idleTimeout)) / 2)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-ticker.C:
+					if err := connection.write(amqpFrameTypeAMQP, 0, nil); err != nil {
+						return
+					}
+				case <-connection.ctx.Done():
+					return
+				}
+			}
+		}()
+	}
+	return nil
+}
+
+// handle processes a frame of the client and returns true when the client closes the connection
+func (connection *fakeHonoAMQPConnection) handle(frame *amqpFrame) (bool, error) {
+	body := frame.body
+	if body.descriptor == amqpClose {
+		return true, connection.write(amqpFrameTypeAMQP, 0, newAMQPPerformative(amqpClose))
+	}
+	if body.descriptor == amqpBegin {
+		nextIncomingID, _ := body.uintField(1)
+		session := &fakeHonoAMQPSession{
+			connection:     connection,
+			channel:        frame.channel,
+			nextIncomingID: nextIncomingID,
+			links:          map[uint32]*fakeHonoAMQPLink{},
+		}
+		connection.sessions[frame.channel] = session
+		return false, connection.write(amqpFrameTypeAMQP, frame.channel, newAMQPPerformative(amqpBegin,
+			frame.channel, uint32(0), uint32(fakeHonoAMQPWindow), uint32(fakeHonoAMQPWindow)))
+	}
+
+	session, ok := connection.sessions[frame.channel]
+	if !ok {
+		return false, fmt.Errorf("unknown channel %d", frame.channel)
+	}
+	switch body.descriptor {
+	case amqpAttach:
+		return false, session.attach(body)
+	case amqpFlow:
+		session.flow(body)
+	case amqpTransfer:
+		return false, session.transfer(body, frame.payload)
+	case amqpDetach:
+		handle, _ := body.uintField(0)
+		return false, session.detach(handle)
+	case amqpEnd:
+		for handle := range session.links {
+			if err := session.detach(handle); err != nil {
+				return false, err
+			}
+		}
+		delete(connection.sessions, frame.channel)
+		return false, connection.write(amqpFrameTypeAMQP, frame.channel, newAMQPPerformative(amqpEnd))
+	}
+	// the client settles the deliveries on its receiver links, so its dispositions are ignored
+	return false, nil
+}
+
+func (connection *fakeHonoAMQPConnection) write(frameType byte, channel uint16, body *amqpDescribed, payload ...byte) error {
+	connection.writeMutex.Lock()
+	defer connection.writeMutex.Unlock()
+	return writeAMQPFrame(connection.conn, frameType, channel, body, payload)
+}
+
+// close closes the network connection and stops all links. It is called by the connection reader once it stops.
+func (connection *fakeHonoAMQPConnection) close() {
+	connection.cancel()
+	connection.conn.Close()
+	for _, session := range connection.sessions {
+		for _, link := range session.links {
+			link.stop()
+		}
+	}
+}
+
+// attach answers the attach of a link. The fake Hono does not create a terminus for a receiver link
+// it cannot receive from, so the link is detached right away with the error.
+func (session *fakeHonoAMQPSession) attach(body *amqpDescribed) error {
+	name := body.stringField(0)
+	clientHandle, _ := body.uintField(1)
+	receiving := body.boolField(2)
+
+	link := &fakeHonoAMQPLink{session: session, handle: session.nextHandle, address: body.address(6)}
+	session.nextHandle++
+	if receiving {
+		link.address = body.address(5)
+	}
+	source := newAMQPPerformative(amqpSource, link.address)
+	target := newAMQPPerformative(amqpTarget, link.address)
+
+	if !receiving {
+		session.links[clientHandle] = link
+		if err := session.write(newAMQPPerformative(amqpAttach,
+			name, link.handle, true, nil, nil, source, target)); err != nil {
+			return err
+		}
+		return session.issueCredit(link)
+	}
+
+	ctx, cancel := context.WithCancel(session.connection.ctx)
+	receiver, err := session.connection.hono.Receive(ctx, link.address)
+	if err != nil {
+		cancel()
+		if err := session.write(newAMQPPerformative(amqpAttach, name, link.handle, false)); err != nil {
+			return err
+		}
+		return session.write(newAMQPPerformative(amqpDetach, link.handle, true,
+			newAMQPPerformative(amqpError, amqpSymbol("amqp:not-found"), err.Error())))
+	}
+	link.receiver = receiver
+	link.cancel = cancel
+	link.credited = make(chan struct{}, 1)
+	session.links[clientHandle] = link
+	if err := session.write(newAMQPPerformative(amqpAttach,
+		name, link.handle, false, nil, nil, source, target, nil, nil, uint32(0))); err != nil {
+		return err
+	}
+	go link.sendMessages(ctx)
+	return nil
+}
+
+// flow updates the credit of a receiver link of the client
+func (session *fakeHonoAMQPSession) flow(body *amqpDescribed) {
+	clientHandle, ok := body.uintField(4)
+	if !ok {
+		return
+	}
+	link, ok := session.links[clientHandle]
+	if !ok || link.receiver == nil {
+		return
+	}
+	deliveryCount, _ := body.uintField(5)
+	linkCredit, _ := body.uintField(6)
+
+	link.mutex.Lock()
+	link.credit = deliveryCount + linkCredit - link.deliveryCount
+	link.mutex.Unlock()
+	select {
+	case link.credited <- struct{}{}:
+	default:
+	}
+}
+
+// transfer collects the message sent on a sender link of the client and sends it to the fake Hono.
+// The delivery is settled with the outcome of the send and the credit of the link is restored.
+func (session *fakeHonoAMQPSession) transfer(body *amqpDescribed, payload []byte) error {
+	session.mutex.Lock()
+	session.nextIncomingID++
+	session.mutex.Unlock()
+
+	clientHandle, _ := body.uintField(0)
+	link, ok := session.links[clientHandle]
+	if !ok || link.receiver != nil {
+		return fmt.Errorf("transfer on unknown link %d", clientHandle)
+	}
+	if link.payload == nil {
+		link.deliveryID, _ = body.uintField(1)
+		link.settled = body.boolField(4)
+	}
+	link.payload = append(link.payload, payload...)
+	if body.boolField(5) {
+		return nil
+	}
+	data := link.payload
+	link.payload = nil
+	link.deliveryCount++
+
+	message := &amqp.Message{}
+	err := message.UnmarshalBinary(data)
+	if err == nil {
+		err = session.connection.hono.Send(session.connection.ctx, link.address, fromAMQPMessage(message))
+	}
+	if !link.settled {
+		state := newAMQPPerformative(amqpAccepted)
+		if err != nil {
+			state = newAMQPPerformative(amqpRejected,
+				newAMQPPerformative(amqpError, amqpSymbol("amqp:internal-error"), err.Error()))
+		}
+		if err := session.write(newAMQPPerformative(amqpDisposition,
+			true, link.deliveryID, nil, true, state)); err != nil {
+			return err
+		}
+	}
+	return session.issueCredit(link)
+}
+
+// detach answers the detach of a link, after which no more messages are sent on it
+func (session *fakeHonoAMQPSession) detach(clientHandle uint32) error {
+	link, ok := session.links[clientHandle]
+	if !ok {
+		return fmt.Errorf("detach of unknown link %d", clientHandle)
+	}
+	delete(session.links, clientHandle)
+
+	link.mutex.Lock()
+	defer link.mutex.Unlock()
+	link.detached = true
+	if link.cancel != nil {
+		link.cancel()
+	}
+	return session.write(newAMQPPerformative(amqpDetach, link.handle, true))
+}
+
+func (session *fakeHonoAMQPSession) issueCredit(link *fakeHonoAMQPLink) error {
+	session.mutex.Lock()
+	defer session.mutex.Unlock()
+	return session.connection.write(amqpFrameTypeAMQP, session.channel, newAMQPPerformative(amqpFlow,
+		session.nextIncomingID, uint32(fakeHonoAMQPWindow), session.nextOutgoingID, uint32(fakeHonoAMQPWindow),
+		link.handle, link.deliveryCount, uint32(fakeHonoAMQPCredit)))
+}
+
+func (session *fakeHonoAMQPSession) write(body *amqpDescribed, payload ...byte) error {
+	return session.connection.write(amqpFrameTypeAMQP, session.channel, body, payload...)
+}
+
+// sendMessages transfers the messages received from the fake Hono to the client while it has credit
+func (link *fakeHonoAMQPLink) sendMessages(ctx context.Context) {
+	defer link.receiver.Close()
+	for {
+		select {
+		case <-link.credited:
+		case <-ctx.Done():
+			return
+		}
+		for link.hasCredit() {
+			message, err := link.receiver.Receive(ctx)
+			if err != nil {
+				return
+			}
+			if err := link.send(message); err != nil {
+				return
+			}
+		}
+	}
+}
+
+func (link *fakeHonoAMQPLink) hasCredit() bool {
+	link.mutex.Lock()
+	defer link.mutex.Unlock()
+	return link.credit > 0 && !link.detached
+}
+
+func (link *fakeHonoAMQPLink) send(message *HonoMessage) error {
+	data, err := toAMQPMessage(message).MarshalBinary()
+	if err != nil {
+		return err
+	}
+
+	link.mutex.Lock()
+	defer link.mutex.Unlock()
+	if link.detached {
+		return errors.New("link is detached")
+	}
+	session := link.session
+	session.mutex.Lock()
+	defer session.mutex.Unlock()
+	deliveryID := session.nextOutgoingID
+	link.deliveryCount++
+	link.credit--
+
+	// the message is split in multiple transfers, if it exceeds the max frame size of the client
+	transfer := newAMQPPerformative(amqpTransfer,
+		link.handle, deliveryID, []byte(fmt.Sprint(deliveryID)), uint32(0), false, true)
+	for {
+		size, err := amqpPayloadSize(session.connection.maxFrameSize, transfer)
+		if err != nil {
+			return err
+		}
+		more := len(data) > size
+		if !more {
+			size = len(data)
+		}
+		transfer.value.([]interface{})[5] = more
+		session.nextOutgoingID++
+		if err := session.write(transfer, data[:size]...); err != nil || !more {
+			return err
+		}
+		data = data[size:]
+		transfer = newAMQPPerformative(amqpTransfer, link.handle, nil, nil, nil, nil, true)
+	}
+}
+
+func (link *fakeHonoAMQPLink) stop() {
+	link.mutex.Lock()
+	defer link.mutex.Unlock()
+	link.detached = true
+	if link.cancel != nil {
+		link.cancel()
+	}
+}
@@ -0,0 +1,399 @@
+// Copyright (c) 2026 Contributors to the Eclipse Foundation
+//
+// See the NOTICE file(s) distributed with this work for additional
+// information regarding copyright ownership.
+//
+// This program and the accompanying materials are made available under the
+// terms of the Eclipse Public License 2.0 which is available at
+// https://www.eclipse.org/legal/epl-2.0, or the Apache License, Version 2.0
+// which is available at https://www.apache.org/licenses/LICENSE-2.0.
+//
+// SPDX-License-Identifier: EPL-2.0 OR Apache-2.0
+
+package util
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// The fake Hono AMQP listener only needs the AMQP 1.0 performatives of the connection, session and link
+// lifecycle, so it encodes and decodes the frames itself. The message sections are left to go-amqp.
+const (
+	amqpFrameTypeAMQP = 0x00
+	amqpFrameTypeSASL = 0x01
+
+	amqpOpen           = 0x10
+	amqpBegin          = 0x11
+	amqpAttach         = 0x12
+	amqpFlow           = 0x13
+	amqpTransfer       = 0x14
+	amqpDisposition    = 0x15
+	amqpDetach         = 0x16
+	amqpEnd            = 0x17
+	amqpClose          = 0x18
+	amqpError          = 0x1d
+	amqpAccepted       = 0x24
+	amqpRejected       = 0x25
+	amqpSource         = 0x28
+	amqpTarget         = 0x29
+	amqpSASLMechanisms = 0x40
+	amqpSASLInit       = 0x41
+	amqpSASLOutcome    = 0x44
+
+	amqpFrameHeaderSize = 8
+	// amqpMinMaxFrameSize is the smallest max frame size a peer may announce
+	amqpMinMaxFrameSize = 512
+)
+
+var (
+	amqpProtocolHeader = []byte{'A', 'M', 'Q', 'P', 0, 1, 0, 0}
+	amqpSASLHeader     = []byte{'A', 'M', 'Q', 'P', 3, 1, 0, 0}
+)
+
+// amqpSymbol is an AMQP symbol, which is encoded differently than a string
+type amqpSymbol string
+
+// amqpDescribed is a described AMQP value, e.g. a performative with its list of fields
+type amqpDescribed struct {
+	descriptor uint64
+	value      interface{}
+}
+
+type amqpFrame struct {
+	frameType byte
+	channel   uint16
+	body      *amqpDescribed
+	payload   []byte
+}
+
+func newAMQPPerformative(descriptor uint64, fields ...interface{}) *amqpDescribed {
+	if fields == nil {
+		fields = []interface{}{}
+	}
+	return &amqpDescribed{descriptor: descriptor, value: fields}
+}
+
+// field returns the field of a described list or nil, if it is omitted
+func (described *amqpDescribed) field(index int) interface{} {
+	fields, ok := described.value.([]interface{})
+	if !ok || index >= len(fields) {
+		return nil
+	}
+	return fields[index]
+}
+
+func (described *amqpDescribed) uintField(index int) (uint32, bool) {
+	switch value := described.field(index).(type) {
+	case uint8:
+		return uint32(value), true
+	case uint16:
+		return uint32(value), true
+	case uint32:
+		return value, true
+	case uint64:
+		return uint32(value), true
+	}
+	return 0, false
+}
+
+func (described *amqpDescribed) boolField(index int) bool {
+	value, _ := described.field(index).(bool)
+	return value
+}
+
+func (described *amqpDescribed) stringField(index int) string {
+	switch value := described.field(index).(type) {
+	case string:
+		return value
+	case amqpSymbol:
+		return string(value)
+	}
+	return ""
+}
+
+// address returns the address of a source or target field
+func (described *amqpDescribed) address(index int) string {
+	terminus, ok := described.field(index).(*amqpDescribed)
+	if !ok {
+		return ""
+	}
+	return terminus.stringField(0)
+}
+
+func readAMQPFrame(reader io.Reader) (*amqpFrame, error) {
+	header := make([]byte, amqpFrameHeaderSize)
+	if _, err := io.ReadFull(reader, header); err != nil {
+		return nil, err
+	}
+	size := binary.BigEndian.Uint32(header[0:4])
+	offset := uint32(header[4]) * 4
+	if size < amqpFrameHeaderSize || offset < amqpFrameHeaderSize || offset > size {
+		return nil, fmt.Errorf("invalid frame header %v", header)
+	}
+	data := make([]byte, size-amqpFrameHeaderSize)
+	if _, err := io.ReadFull(reader, data); err != nil {
+		return nil, err
+	}
+
+	frame := &amqpFrame{frameType: header[5], channel: binary.BigEndian.Uint16(header[6:8])}
+	data = data[offset-amqpFrameHeaderSize:]
+	if len(data) == 0 {
+		// an empty frame keeps the connection alive
+		return frame, nil
+	}
+	body, rest, err := decodeAMQP(data)
+	if err != nil {
+		return nil, err
+	}
+	described, ok := body.(*amqpDescribed)
+	if !ok {
+		return nil, fmt.Errorf("invalid frame body %v", body)
+	}
+	frame.body = described
+	frame.payload = rest
+	return frame, nil
+}
+
+// amqpPayloadSize returns the size of the payload, which fits into a frame of the max frame size with the body
+func amqpPayloadSize(maxFrameSize uint32, body *amqpDescribed) (int, error) {
+	buffer := &bytes.Buffer{}
+	if err := encodeAMQP(buffer, body); err != nil {
+		return 0, err
+	}
+	size := int(maxFrameSize) - amqpFrameHeaderSize - buffer.Len()
+	if size <= 0 {
+		return 0, fmt.Errorf("frame body exceeds the max frame size %d", maxFrameSize)
+	}
+	return size, nil
+}
+
+func writeAMQPFrame(writer io.Writer, frameType byte, channel uint16, body *amqpDescribed, payload []byte) error {
+	buffer := &bytes.Buffer{}
+	buffer.Write(make([]byte, amqpFrameHeaderSize))
+	if body != nil {
+		if err := encodeAMQP(buffer, body); err != nil {
+			return err
+		}
+		buffer.Write(payload)
+	}
+	data := buffer.Bytes()
+	binary.BigEndian.PutUint32(data[0:4], uint32(len(data)))
+	data[4] = 2
+	data[5] = frameType
+	binary.BigEndian.PutUint16(data[6:8], channel)
+	_, err := writer.Write(data)
+	return err
+}
+
+// encodeAMQP encodes the value with the fixed width encodings
+func encodeAMQP(buffer *bytes.Buffer, value interface{}) error {
+	switch value := value.(type) {
+	case nil:
+		buffer.WriteByte(0x40)
+	case bool:
+		if value {
+			buffer.WriteByte(0x41)
+		} else {
+			buffer.WriteByte(0x42)
+		}
+	case uint8:
+		buffer.Write([]byte{0x50, value})
+	case uint16:
+		buffer.WriteByte(0x60)
+		binary.Write(buffer, binary.BigEndian, value)
+	case uint32:
+		buffer.WriteByte(0x70)
+		binary.Write(buffer, binary.BigEndian, value)
+	case uint64:
+		buffer.WriteByte(0x80)
+		binary.Write(buffer, binary.BigEndian, value)
+	case []byte:
+		buffer.WriteByte(0xb0)
+		binary.Write(buffer, binary.BigEndian, uint32(len(value)))
+		buffer.Write(value)
+	case string:
+		buffer.WriteByte(0xb1)
+		binary.Write(buffer, binary.BigEndian, uint32(len(value)))
+		buffer.WriteString(value)
+	case amqpSymbol:
+		buffer.WriteByte(0xb3)
+		binary.Write(buffer, binary.BigEndian, uint32(len(value)))
+		buffer.WriteString(string(value))
+	case []interface{}:
+		items := &bytes.Buffer{}
+		for _, item := range value {
+			if err := encodeAMQP(items, item); err != nil {
+				return err
+			}
+		}
+		buffer.WriteByte(0xd0)
+		binary.Write(buffer, binary.BigEndian, uint32(items.Len()+4))
+		binary.Write(buffer, binary.BigEndian, uint32(len(value)))
+		buffer.Write(items.Bytes())
+	case *amqpDescribed:
+		// go-amqp expects the performative descriptors as small ulong
+		buffer.Write([]byte{0x00, 0x53, byte(value.descriptor)})
+		return encodeAMQP(buffer, value.value)
+	default:
+		return fmt.Errorf("unsupported AMQP value %T", value)
+	}
+	return nil
+}
+
+// decodeAMQP decodes the first value of the data and returns the rest of it. Floating point numbers,
+// timestamps and chars are kept as their bits. Maps and arrays are skipped as none of the handled fields needs them.
+func decodeAMQP(data []byte) (interface{}, []byte, error) {
+	if len(data) == 0 {
+		return nil, nil, errors.New("missing AMQP value")
+	}
+	code, data := data[0], data[1:]
+
+	fixed := func(size int) ([]byte, []byte, error) {
+		if len(data) < size {
+			return nil, nil, fmt.Errorf("truncated AMQP value %#02x", code)
+		}
+		return data[:size], data[size:], nil
+	}
+	variable := func(width int) ([]byte, []byte, error) {
+		sizeData, rest, err := fixed(width)
+		if err != nil {
+			return nil, nil, err
+		}
+		size := int(sizeData[0])
+		if width == 4 {
+			size = int(binary.BigEndian.Uint32(sizeData))
+		}
+		data = rest
+		return fixed(size)
+	}
+
+	switch code {
+	case 0x00:
+		descriptor, rest, err := decodeAMQP(data)
+		if err != nil {
+			return nil, nil, err
+		}
+		value, rest, err := decodeAMQP(rest)
+		if err != nil {
+			return nil, nil, err
+		}
+		described := &amqpDescribed{value: value}
+		switch number := descriptor.(type) {
+		case uint64:
+			described.descriptor = number
+		case uint8:
+			described.descriptor = uint64(number)
+		}
+		return described, rest, nil
+	case 0x40:
+		return nil, data, nil
+	case 0x41:
+		return true, data, nil
+	case 0x42:
+		return false, data, nil
+	case 0x43:
+		return uint32(0), data, nil
+	case 0x44:
+		return uint64(0), data, nil
+	case 0x45:
+		return []interface{}{}, data, nil
+	case 0x56, 0x50, 0x51, 0x52, 0x53, 0x54, 0x55:
+		value, rest, err := fixed(1)
+		if err != nil {
+			return nil, nil, err
+		}
+		switch code {
+		case 0x56:
+			return value[0] != 0, rest, nil
+		case 0x52:
+			return uint32(value[0]), rest, nil
+		case 0x53:
+			return uint64(value[0]), rest, nil
+		case 0x51, 0x54, 0x55:
+			return int64(int8(value[0])), rest, nil
+		}
+		return value[0], rest, nil
+	case 0x60, 0x61:
+		value, rest, err := fixed(2)
+		if err != nil {
+			return nil, nil, err
+		}
+		return binary.BigEndian.Uint16(value), rest, nil
+	case 0x70, 0x71, 0x72, 0x73:
+		value, rest, err := fixed(4)
+		if err != nil {
+			return nil, nil, err
+		}
+		if code == 0x71 {
+			return int32(binary.BigEndian.Uint32(value)), rest, nil
+		}
+		return binary.BigEndian.Uint32(value), rest, nil
+	case 0x80, 0x81, 0x82, 0x83:
+		value, rest, err := fixed(8)
+		if err != nil {
+			return nil, nil, err
+		}
+		if code == 0x81 {
+			return int64(binary.BigEndian.Uint64(value)), rest, nil
+		}
+		return binary.BigEndian.Uint64(value), rest, nil
+	case 0x98:
+		value, rest, err := fixed(16)
+		return value, rest, err
+	case 0xa0, 0xb0, 0xa1, 0xb1, 0xa3, 0xb3:
+		width := 1
+		if code&0xf0 == 0xb0 {
+			width = 4
+		}
+		value, rest, err := variable(width)
+		if err != nil {
+			return nil, nil, err
+		}
+		switch code & 0x0f {
+		case 0x01:
+			return string(value), rest, nil
+		case 0x03:
+			return amqpSymbol(value), rest, nil
+		}
+		return append([]byte(nil), value...), rest, nil
+	case 0xc0, 0xd0:
+		width := 1
+		if code == 0xd0 {
+			width = 4
+		}
+		value, rest, err := variable(width)
+		if err != nil || len(value) < width {
+			return nil, nil, fmt.Errorf("invalid AMQP list: %v", err)
+		}
+		count := int(value[0])
+		if width == 4 {
+			count = int(binary.BigEndian.Uint32(value))
+		}
+		items := value[width:]
+		// each item is encoded with at least its constructor
+		if count > len(items) {
+			return nil, nil, fmt.Errorf("invalid AMQP list count %d for %d bytes", count, len(items))
+		}
+		list := make([]interface{}, 0, count)
+		for i := 0; i < count; i++ {
+			var item interface{}
+			if item, items, err = decodeAMQP(items); err != nil {
+				return nil, nil, err
+			}
+			list = append(list, item)
+		}
+		return list, rest, nil
+	case 0xc1, 0xd1, 0xe0, 0xf0:
+		width := 1
+		if code&0xf0 != 0xc0 && code&0xf0 != 0xe0 {
+			width = 4
+		}
+		_, rest, err := variable(width)
+		return nil, rest, err
+	}
+	return nil, nil, fmt.Errorf("unsupported AMQP type %#02x", code)
+}
@@ -0,0 +1,177 @@
+// Copyright (c) 2026 Contributors to the Eclipse Foundation
+//
+// See the NOTICE file(s) distributed with this work for additional
+// information regarding copyright ownership.
+//
+// This program and the accompanying materials are made available under the
+// terms of the Eclipse Public License 2.0 which is available at
+// https://www.eclipse.org/legal/epl-2.0, or the Apache License, Version 2.0
+// which is available at https://www.apache.org/licenses/LICENSE-2.0.
+//
+// SPDX-License-Identifier: EPL-2.0 OR Apache-2.0
+
+package util
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAMQPFrameRoundTrip(t *testing.T) {
+	body := newAMQPPerformative(amqpAttach,
+		"link", uint32(1), true, false, nil, uint8(2), uint16(3), uint64(4), []byte{5, 6}, amqpSymbol("symbol"),
+		newAMQPPerformative(amqpSource, "address"), []interface{}{"nested", []interface{}{}})
+	buffer := &bytes.Buffer{}
+	require.NoError(t, writeAMQPFrame(buffer, amqpFrameTypeAMQP, 3, body, []byte("payload")))
+
+	frame, err := readAMQPFrame(buffer)
+	require.NoError(t, err)
+	require.Equal(t, byte(amqpFrameTypeAMQP), frame.frameType)
+	require.Equal(t, uint16(3), frame.channel)
+	require.Equal(t, body, frame.body)
+	require.Equal(t, []byte("payload"), frame.payload)
+	require.Equal(t, "address", frame.body.address(10))
+	require.Zero(t, buffer.Len())
+
+	// An empty frame without a body keeps the connection alive
+	require.NoError(t, writeAMQPFrame(buffer, amqpFrameTypeAMQP, 0, nil, nil))
+	require.Equal(t, amqpFrameHeaderSize, buffer.Len())
+	frame, err = readAMQPFrame(buffer)
+	require.NoError(t, err)
+	require.Nil(t, frame.body)
+
+	require.EqualError(t, writeAMQPFrame(buffer, amqpFrameTypeAMQP, 0, newAMQPPerformative(amqpOpen, 1), nil),
+		"unsupported AMQP value int")
+}
+
+func TestReadAMQPFrameInvalid(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+		err  string
+	}{
+		{
+			name: "size smaller than the header",
+			data: []byte{0, 0, 0, 4, 2, 0, 0, 0},
+			err:  "invalid frame header [0 0 0 4 2 0 0 0]",
+		},
+		{
+			name: "data offset within the header",
+			data: []byte{0, 0, 0, 8, 1, 0, 0, 0},
+			err:  "invalid frame header [0 0 0 8 1 0 0 0]",
+		},
+		{
+			name: "data offset beyond the size",
+			data: []byte{0, 0, 0, 8, 3, 0, 0, 0},
+			err:  "invalid frame header [0 0 0 8 3 0 0 0]",
+		},
+		{
+			name: "truncated header",
+			data: []byte{0, 0, 0, 8},
+			err:  "unexpected EOF",
+		},
+		{
+			name: "truncated body",
+			data: []byte{0, 0, 0, 12, 2, 0, 0, 0, 0x00},
+			err:  "unexpected EOF",
+		},
+		{
+			name: "body not described",
+			data: []byte{0, 0, 0, 9, 2, 0, 0, 0, 0x41},
+			err:  "invalid frame body true",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			frame, err := readAMQPFrame(bytes.NewReader(test.data))
+			require.EqualError(t, err, test.err)
+			require.Nil(t, frame)
+		})
+	}
+}
+
+func TestDecodeAMQP(t *testing.T) {
+	tests := []struct {
+		name  string
+		data  []byte
+		value interface{}
+	}{
+		{name: "uint0", data: []byte{0x43}, value: uint32(0)},
+		{name: "ulong0", data: []byte{0x44}, value: uint64(0)},
+		{name: "list0", data: []byte{0x45}, value: []interface{}{}},
+		{name: "boolean", data: []byte{0x56, 0x01}, value: true},
+		{name: "smalluint", data: []byte{0x52, 0x07}, value: uint32(7)},
+		{name: "smallulong", data: []byte{0x53, 0x07}, value: uint64(7)},
+		{name: "smallint", data: []byte{0x54, 0xff}, value: int64(-1)},
+		{name: "int", data: []byte{0x71, 0xff, 0xff, 0xff, 0xfe}, value: int32(-2)},
+		{name: "str8", data: []byte{0xa1, 0x02, 'o', 'k'}, value: "ok"},
+		{name: "sym8", data: []byte{0xa3, 0x02, 'o', 'k'}, value: amqpSymbol("ok")},
+		{name: "vbin8", data: []byte{0xa0, 0x01, 0x2a}, value: []byte{0x2a}},
+		{name: "list8", data: []byte{0xc0, 0x03, 0x02, 0x40, 0x41}, value: []interface{}{nil, true}},
+		{name: "map8 skipped", data: []byte{0xc1, 0x03, 0x02, 0x40, 0x40}, value: nil},
+		{
+			name:  "described with ulong descriptor",
+			data:  []byte{0x00, 0x80, 0, 0, 0, 0, 0, 0, 0, 0x10, 0x45},
+			value: &amqpDescribed{descriptor: amqpOpen, value: []interface{}{}},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			value, rest, err := decodeAMQP(append(test.data, 0xff))
+			require.NoError(t, err)
+			require.Equal(t, test.value, value)
+			require.Equal(t, []byte{0xff}, rest)
+		})
+	}
+}
+
+func TestDecodeAMQPInvalid(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+		err  string
+	}{
+		{name: "empty", data: []byte{}, err: "missing AMQP value"},
+		{name: "truncated uint", data: []byte{0x70, 0x00, 0x01}, err: "truncated AMQP value 0x70"},
+		{name: "truncated string", data: []byte{0xa1, 0x05, 'o', 'k'}, err: "truncated AMQP value 0xa1"},
+		{name: "truncated string size", data: []byte{0xb1, 0x00, 0x00}, err: "truncated AMQP value 0xb1"},
+		{name: "truncated list item", data: []byte{0xc0, 0x02, 0x01, 0x70}, err: "truncated AMQP value 0x70"},
+		{
+			name: "list8 count beyond its size",
+			data: []byte{0xc0, 0x02, 0xff, 0x40},
+			err:  "invalid AMQP list count 255 for 1 bytes",
+		},
+		{
+			name: "list32 count beyond its size",
+			data: []byte{0xd0, 0x00, 0x00, 0x00, 0x05, 0xff, 0xff, 0xff, 0xff, 0x40},
+			err:  "invalid AMQP list count 4294967295 for 1 bytes",
+		},
+		{name: "unsupported type", data: []byte{0xff}, err: "unsupported AMQP type 0xff"},
+		{name: "described without value", data: []byte{0x00, 0x53, 0x10}, err: "missing AMQP value"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			value, _, err := decodeAMQP(test.data)
+			require.EqualError(t, err, test.err)
+			require.Nil(t, value)
+		})
+	}
+}
+
+func TestAMQPPayloadSize(t *testing.T) {
+	transfer := newAMQPPerformative(amqpTransfer, uint32(0), uint32(0), []byte("0"), uint32(0), false, true)
+	buffer := &bytes.Buffer{}
+	require.NoError(t, encodeAMQP(buffer, transfer))
+
+	size, err := amqpPayloadSize(amqpMinMaxFrameSize, transfer)
+	require.NoError(t, err)
+	require.Equal(t, amqpMinMaxFrameSize-amqpFrameHeaderSize-buffer.Len(), size)
+
+	// A frame without room for the payload is not sent
+	maxFrameSize := amqpFrameHeaderSize + buffer.Len()
+	_, err = amqpPayloadSize(uint32(maxFrameSize), transfer)
+	require.EqualError(t, err, fmt.Sprintf("frame body exceeds the max frame size %d", maxFrameSize))
+}
@@ -0,0 +1,85 @@
+// Copyright (c) 2026 Contributors to the Eclipse Foundation
+//
+// See the NOTICE file(s) distributed with this work for additional
+// information regarding copyright ownership.
+//
+// This program and the accompanying materials are made available under the
+// terms of the Eclipse Public License 2.0 which is available at
+// https://www.eclipse.org/legal/epl-2.0, or the Apache License, Version 2.0
+// which is available at https://www.apache.org/licenses/LICENSE-2.0.
+//
+// SPDX-License-Identifier: EPL-2.0 OR Apache-2.0
+
+package util
+
+import (
+	"bytes"
+	"io"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/Azure/go-amqp"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFakeHonoAMQPSendSplitAtMaxFrameSize(t *testing.T) {
+	tests := []struct {
+		name      string
+		payload   string
+		transfers int
+	}{
+		{name: "single transfer", payload: "{}", transfers: 1},
+		{name: "multiple transfers", payload: strings.Repeat("x", 2048), transfers: 5},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			server, client := net.Pipe()
+			defer server.Close()
+			defer client.Close()
+
+			connection := &fakeHonoAMQPConnection{conn: server, maxFrameSize: amqpMinMaxFrameSize}
+			session := &fakeHonoAMQPSession{connection: connection, channel: 1, nextOutgoingID: 7}
+			link := &fakeHonoAMQPLink{session: session, handle: 2, credit: 1}
+			message := &HonoMessage{
+				Address:     GetHonoEventAddress(testTenantID),
+				DeviceID:    testThingID,
+				ContentType: HonoContentTypeDitto,
+				Payload:     []byte(test.payload),
+			}
+			sent := make(chan error, 1)
+			go func() {
+				sent <- link.send(message)
+			}()
+
+			var data []byte
+			transfers := 0
+			for more := true; more; transfers++ {
+				read := &bytes.Buffer{}
+				frame, err := readAMQPFrame(io.TeeReader(client, read))
+				require.NoError(t, err)
+				require.LessOrEqual(t, read.Len(), amqpMinMaxFrameSize)
+				require.Equal(t, uint16(1), frame.channel)
+				require.Equal(t, uint64(amqpTransfer), frame.body.descriptor)
+				handle, _ := frame.body.uintField(0)
+				require.Equal(t, uint32(2), handle)
+				// only the first transfer of the delivery carries its id
+				deliveryID, ok := frame.body.uintField(1)
+				require.Equal(t, transfers == 0, ok)
+				if ok {
+					require.Equal(t, uint32(7), deliveryID)
+				}
+				more = frame.body.boolField(5)
+				data = append(data, frame.payload...)
+			}
+			require.NoError(t, <-sent)
+			require.Equal(t, test.transfers, transfers)
+			require.Equal(t, uint32(7+transfers), session.nextOutgoingID)
+			require.Equal(t, uint32(0), link.credit)
+
+			received := &amqp.Message{}
+			require.NoError(t, received.UnmarshalBinary(data))
+			require.Equal(t, message.Payload, received.GetData())
+		})
+	}
+}
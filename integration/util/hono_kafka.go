@@ -0,0 +1,345 @@
+// Copyright (c) 2026 Contributors to the Eclipse Foundation
+//
+// See the NOTICE file(s) distributed with this work for additional
+// information regarding copyright ownership.
+//
+// This program and the accompanying materials are made available under the
+// terms of the Eclipse Public License 2.0 which is available at
+// https://www.eclipse.org/legal/epl-2.0, or the Apache License, Version 2.0
+// which is available at https://www.apache.org/licenses/LICENSE-2.0.
+//
+// SPDX-License-Identifier: EPL-2.0 OR Apache-2.0
+
+package util
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+	"github.com/segmentio/kafka-go/sasl"
+	"github.com/segmentio/kafka-go/sasl/plain"
+	"github.com/segmentio/kafka-go/sasl/scram"
+)
+
+const (
+	honoKafkaHeaderDeviceID         = "device_id"
+	honoKafkaHeaderCorrelationID    = "correlation-id"
+	honoKafkaHeaderSubject          = "subject"
+	honoKafkaHeaderContentType      = "content-type"
+	honoKafkaHeaderStatus           = "status"
+	honoKafkaHeaderResponseRequired = "response-required"
+
+	honoKafkaTopicPrefix = "hono."
+
+	honoKafkaDialTimeout = 10 * time.Second
+	honoKafkaMaxWait     = 500 * time.Millisecond
+)
+
+// HonoKafkaConnection is a connection to the Hono Kafka messaging network.
+// The addresses are mapped to the Hono topics, e.g. "event/<tenant-id>" is mapped to "hono.event.<tenant-id>".
+// All command responses of a tenant are sent to its command response topic regardless of the reply ID.
+type HonoKafkaConnection struct {
+	brokers []string
+	dialer  *kafka.Dialer
+	writer  *kafka.Writer
+
+	mutex     sync.Mutex
+	receivers map[*honoKafkaReceiver]bool
+}
+
+type honoKafkaReceiver struct {
+	connection *HonoKafkaConnection
+	address    string
+	readers    []*kafka.Reader
+
+	messages chan *HonoMessage
+	errors   chan error
+	cancel   context.CancelFunc
+	once     sync.Once
+}
+
+// NewHonoKafkaConnection creates a connection to the Hono Kafka brokers with optional SASL authentication and TLS
+func NewHonoKafkaConnection(ctx context.Context, cfg *HonoConfiguration) (*HonoKafkaConnection, error) {
+	dialer := &kafka.Dialer{
+		Timeout:   honoKafkaDialTimeout,
+		DualStack: true,
+	}
+	transport := &kafka.Transport{
+		DialTimeout: honoKafkaDialTimeout,
+	}
+
+	if cfg.HonoKafkaUsername != "" {
+		mechanism, err := newKafkaSASLMechanism(cfg)
+		if err != nil {
+			return nil, err
+		}
+		dialer.SASLMechanism = mechanism
+		transport.SASL = mechanism
+	}
+	if cfg.HonoKafkaTLS {
+		tlsConfig, err := NewTLSConfig(cfg.HonoKafkaCACert, "", "")
+		if err != nil {
+			return nil, err
+		}
+		dialer.TLS = tlsConfig
+		transport.TLS = tlsConfig
+	}
+
+	var brokers []string
+	for _, broker := range strings.Split(cfg.HonoKafkaBrokers, ",") {
+		if broker = strings.TrimSpace(broker); broker != "" {
+			brokers = append(brokers, broker)
+		}
+	}
+	if len(brokers) == 0 {
+		return nil, errors.New("no Hono Kafka brokers specified")
+	}
+
+	// Verify the brokers and the credentials eagerly, the readers and the writer connect lazily
+	conn, err := dialer.DialContext(ctx, "tcp", brokers[0])
+	if err != nil {
+		return nil, fmt.Errorf("unable to connect to Hono Kafka messaging at %s: %v", brokers[0], err)
+	}
+	conn.Close()
+
+	return &HonoKafkaConnection{
+		brokers: brokers,
+		dialer:  dialer,
+		writer: &kafka.Writer{
+			Addr:         kafka.TCP(brokers...),
+			Transport:    transport,
+			Balancer:     &kafka.Hash{},
+			RequiredAcks: kafka.RequireAll,
+		},
+		receivers: map[*honoKafkaReceiver]bool{},
+	}, nil
+}
+
+func newKafkaSASLMechanism(cfg *HonoConfiguration) (sasl.Mechanism, error) {
+	switch strings.ToUpper(cfg.HonoKafkaSASLMechanism) {
+	case "PLAIN":
+		return plain.Mechanism{Username: cfg.HonoKafkaUsername, Password: cfg.HonoKafkaPassword}, nil
+	case "SCRAM-SHA-256":
+		return scram.Mechanism(scram.SHA256, cfg.HonoKafkaUsername, cfg.HonoKafkaPassword)
+	case "SCRAM-SHA-512":
+		return scram.Mechanism(scram.SHA512, cfg.HonoKafkaUsername, cfg.HonoKafkaPassword)
+	default:
+		return nil, fmt.Errorf("unsupported Kafka SASL mechanism %s", cfg.HonoKafkaSASLMechanism)
+	}
+}
+
+// GetHonoKafkaTopic returns the Hono Kafka topic of an address, e.g. "hono.command.<tenant-id>"
+// for the command addresses of a tenant and its devices
+func GetHonoKafkaTopic(address string) (string, error) {
+	elements := strings.Split(address, "/")
+	if len(elements) < 2 || elements[0] == "" || elements[1] == "" {
+		return "", fmt.Errorf("invalid Hono address: %s", address)
+	}
+	return honoKafkaTopicPrefix + elements[0] + "." + elements[1], nil
+}
+
+// Receive starts receiving the messages sent to the topic of the address after the call.
+// The topic partitions are read directly without a consumer group, so no offsets are committed.
+func (connection *HonoKafkaConnection) Receive(ctx context.Context, address string) (HonoReceiver, error) {
+	topic, err := GetHonoKafkaTopic(address)
+	if err != nil {
+		return nil, err
+	}
+	partitions, err := connection.dialer.LookupPartitions(ctx, "tcp", connection.brokers[0], topic)
+	if err != nil {
+		return nil, fmt.Errorf("unable to look up the partitions of topic %s: %v", topic, err)
+	}
+
+	receiveCtx, cancel := context.WithCancel(context.Background())
+	receiver := &honoKafkaReceiver{
+		connection: connection,
+		address:    address,
+		messages:   make(chan *HonoMessage),
+		errors:     make(chan error, len(partitions)),
+		cancel:     cancel,
+	}
+	for _, partition := range partitions {
+		offset, err := connection.lastOffset(ctx, topic, partition.ID)
+		if err != nil {
+			receiver.Close()
+			return nil, err
+		}
+		reader := kafka.NewReader(kafka.ReaderConfig{
+			Brokers:   connection.brokers,
+			Topic:     topic,
+			Partition: partition.ID,
+			Dialer:    connection.dialer,
+			MaxWait:   honoKafkaMaxWait,
+		})
+		if err := reader.SetOffset(offset); err != nil {
+			reader.Close()
+			receiver.Close()
+			return nil, fmt.Errorf("unable to read topic %s: %v", topic, err)
+		}
+		receiver.readers = append(receiver.readers, reader)
+		go receiver.read(receiveCtx, reader)
+	}
+
+	connection.mutex.Lock()
+	connection.receivers[receiver] = true
+	connection.mutex.Unlock()
+	return receiver, nil
+}
+
+func (connection *HonoKafkaConnection) lastOffset(ctx context.Context, topic string, partition int) (int64, error) {
+	conn, err := connection.dialer.DialLeader(ctx, "tcp", connection.brokers[0], topic, partition)
+	if err != nil {
+		return 0, fmt.Errorf("unable to connect to the leader of topic %s partition %d: %v", topic, partition, err)
+	}
+	defer conn.Close()
+
+	offset, err := conn.ReadLastOffset()
+	if err != nil {
+		return 0, fmt.Errorf("unable to read the last offset of topic %s partition %d: %v", topic, partition, err)
+	}
+	return offset, nil
+}
+
+// Send sends a message to the topic of the address. The commands are keyed by the device ID,
+// so all commands to a device are kept in order.
+func (connection *HonoKafkaConnection) Send(ctx context.Context, address string, message *HonoMessage) error {
+	topic, err := GetHonoKafkaTopic(address)
+	if err != nil {
+		return err
+	}
+	if err := connection.writer.WriteMessages(ctx, toKafkaMessage(topic, message)); err != nil {
+		return fmt.Errorf("unable to send message to %s: %v", topic, err)
+	}
+	return nil
+}
+
+// Close closes all receivers and the writer
+func (connection *HonoKafkaConnection) Close() error {
+	connection.mutex.Lock()
+	var receivers []*honoKafkaReceiver
+	for receiver := range connection.receivers {
+		receivers = append(receivers, receiver)
+	}
+	connection.mutex.Unlock()
+
+	for _, receiver := range receivers {
+		receiver.Close()
+	}
+	return connection.writer.Close()
+}
+
+func (receiver *honoKafkaReceiver) read(ctx context.Context, reader *kafka.Reader) {
+	for {
+		message, err := reader.ReadMessage(ctx)
+		if err != nil {
+			if ctx.Err() == nil {
+				receiver.errors <- fmt.Errorf("unable to read topic %s: %v", reader.Config().Topic, err)
+			}
+			return
+		}
+		select {
+		case receiver.messages <- fromKafkaMessage(receiver.address, message):
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// Receive waits for the next message from any partition of the topic
+func (receiver *honoKafkaReceiver) Receive(ctx context.Context) (*HonoMessage, error) {
+	select {
+	case message := <-receiver.messages:
+		return message, nil
+	case err := <-receiver.errors:
+		return nil, err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// Close stops reading the partitions of the topic
+func (receiver *honoKafkaReceiver) Close() error {
+	receiver.once.Do(func() {
+		receiver.cancel()
+		for _, reader := range receiver.readers {
+			reader.Close()
+		}
+
+		receiver.connection.mutex.Lock()
+		delete(receiver.connection.receivers, receiver)
+		receiver.connection.mutex.Unlock()
+	})
+	return nil
+}
+
+func toKafkaMessage(topic string, message *HonoMessage) kafka.Message {
+	headers := []kafka.Header{}
+	addHeader := func(key string, value string) {
+		if value != "" {
+			headers = append(headers, kafka.Header{Key: key, Value: []byte(value)})
+		}
+	}
+
+	deviceID := message.DeviceID
+	if deviceID == "" {
+		if _, commandDeviceID, err := ParseHonoCommandAddress(message.Address); err == nil {
+			deviceID = commandDeviceID
+		}
+	}
+	addHeader(honoKafkaHeaderDeviceID, deviceID)
+	addHeader(honoKafkaHeaderCorrelationID, message.CorrelationID)
+	addHeader(honoKafkaHeaderSubject, message.Subject)
+	addHeader(honoKafkaHeaderContentType, message.ContentType)
+	if message.ReplyTo != "" {
+		addHeader(honoKafkaHeaderResponseRequired, "true")
+	}
+	if message.Status != 0 {
+		addHeader(honoKafkaHeaderStatus, strconv.Itoa(message.Status))
+	}
+	for name, value := range message.Properties {
+		addHeader(name, fmt.Sprint(value))
+	}
+
+	return kafka.Message{
+		Topic:   topic,
+		Key:     []byte(deviceID),
+		Headers: headers,
+		Value:   message.Payload,
+	}
+}
+
+func fromKafkaMessage(address string, message kafka.Message) *HonoMessage {
+	result := &HonoMessage{
+		MessageID:  fmt.Sprintf("%s/%d/%d", message.Topic, message.Partition, message.Offset),
+		Address:    address,
+		Properties: map[string]interface{}{},
+		Payload:    message.Value,
+	}
+	for _, header := range message.Headers {
+		value := string(header.Value)
+		switch header.Key {
+		case honoKafkaHeaderDeviceID:
+			result.DeviceID = value
+		case honoKafkaHeaderCorrelationID:
+			result.CorrelationID = value
+		case honoKafkaHeaderSubject:
+			result.Subject = value
+		case honoKafkaHeaderContentType:
+			result.ContentType = value
+		case honoKafkaHeaderStatus:
+			result.Status, _ = strconv.Atoi(value)
+		default:
+			result.Properties[header.Key] = value
+		}
+	}
+	if result.DeviceID == "" {
+		result.DeviceID = string(message.Key)
+	}
+	return result
+}
@@ -0,0 +1,177 @@
+// Copyright (c) 2026 Contributors to the Eclipse Foundation
+//
+// See the NOTICE file(s) distributed with this work for additional
+// information regarding copyright ownership.
+//
+// This program and the accompanying materials are made available under the
+// terms of the Eclipse Public License 2.0 which is available at
+// https://www.eclipse.org/legal/epl-2.0, or the Apache License, Version 2.0
+// which is available at https://www.apache.org/licenses/LICENSE-2.0.
+//
+// SPDX-License-Identifier: EPL-2.0 OR Apache-2.0
+
+package util
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"testing"
+
+	"github.com/segmentio/kafka-go"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetHonoKafkaTopic(t *testing.T) {
+	tests := []struct {
+		address string
+		topic   string
+	}{
+		{address: GetHonoEventAddress(testTenantID), topic: "hono.event.test"},
+		{address: GetHonoTelemetryAddress(testTenantID), topic: "hono.telemetry.test"},
+		{address: GetHonoCommandAddress(testTenantID, ""), topic: "hono.command.test"},
+		{address: GetHonoCommandAddress(testTenantID, testThingID), topic: "hono.command.test"},
+		{address: GetHonoCommandResponseAddress(testTenantID, "reply"), topic: "hono.command_response.test"},
+	}
+	for _, test := range tests {
+		topic, err := GetHonoKafkaTopic(test.address)
+		require.NoError(t, err, test.address)
+		require.Equal(t, test.topic, topic, test.address)
+	}
+
+	for _, address := range []string{"", "event", "event/", "/test", "/"} {
+		_, err := GetHonoKafkaTopic(address)
+		require.EqualError(t, err, "invalid Hono address: "+address)
+	}
+}
+
+func TestHonoKafkaConnectionInvalidAddress(t *testing.T) {
+	ctx, cancel := NewTestContext(t)
+	defer cancel()
+
+	// The address is checked before the brokers are accessed
+	connection := &HonoKafkaConnection{}
+	_, err := connection.Receive(ctx, "event")
+	require.EqualError(t, err, "invalid Hono address: event")
+	err = connection.Send(context.Background(), "command/", &HonoMessage{})
+	require.EqualError(t, err, "invalid Hono address: command/")
+}
+
+func TestHonoKafkaMessageHeaders(t *testing.T) {
+	tests := []struct {
+		name    string
+		address string
+		message *HonoMessage
+		headers map[string]string
+		key     string
+		// received is the message read back from the topic, only the offset based message ID is not compared
+		received *HonoMessage
+	}{
+		{
+			name:    "command to a device",
+			address: GetHonoCommandAddress(testTenantID, testThingID),
+			message: &HonoMessage{
+				CorrelationID: "correlation",
+				Address:       GetHonoCommandAddress(testTenantID, testThingID),
+				ReplyTo:       GetHonoCommandResponseAddress(testTenantID, "reply"),
+				Subject:       "modify",
+				ContentType:   HonoContentTypeDitto,
+				Properties:    map[string]interface{}{"custom": 1},
+				Payload:       []byte(`{"value":1}`),
+			},
+			headers: map[string]string{
+				"device_id":         testThingID,
+				"correlation-id":    "correlation",
+				"subject":           "modify",
+				"content-type":      HonoContentTypeDitto,
+				"response-required": "true",
+				"custom":            "1",
+			},
+			key: testThingID,
+			received: &HonoMessage{
+				CorrelationID: "correlation",
+				Address:       GetHonoCommandAddress(testTenantID, testThingID),
+				Subject:       "modify",
+				ContentType:   HonoContentTypeDitto,
+				DeviceID:      testThingID,
+				Properties:    map[string]interface{}{"custom": "1", "response-required": "true"},
+				Payload:       []byte(`{"value":1}`),
+			},
+		},
+		{
+			name:    "one-way command",
+			address: GetHonoCommandAddress(testTenantID, ""),
+			message: &HonoMessage{
+				Address:  GetHonoCommandAddress(testTenantID, ""),
+				DeviceID: testThingID,
+				Subject:  "reboot",
+			},
+			headers: map[string]string{
+				"device_id": testThingID,
+				"subject":   "reboot",
+			},
+			key: testThingID,
+			received: &HonoMessage{
+				Address:    GetHonoCommandAddress(testTenantID, ""),
+				Subject:    "reboot",
+				DeviceID:   testThingID,
+				Properties: map[string]interface{}{},
+			},
+		},
+		{
+			name:    "command response",
+			address: GetHonoCommandResponseAddress(testTenantID, "reply"),
+			message: &HonoMessage{
+				CorrelationID: "correlation",
+				DeviceID:      testThingID,
+				Status:        http.StatusAccepted,
+				Payload:       []byte(`{}`),
+			},
+			headers: map[string]string{
+				"device_id":      testThingID,
+				"correlation-id": "correlation",
+				"status":         strconv.Itoa(http.StatusAccepted),
+			},
+			key: testThingID,
+			received: &HonoMessage{
+				CorrelationID: "correlation",
+				Address:       GetHonoCommandResponseAddress(testTenantID, "reply"),
+				DeviceID:      testThingID,
+				Status:        http.StatusAccepted,
+				Properties:    map[string]interface{}{},
+				Payload:       []byte(`{}`),
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			topic, err := GetHonoKafkaTopic(test.address)
+			require.NoError(t, err)
+			message := toKafkaMessage(topic, test.message)
+			require.Equal(t, topic, message.Topic)
+			require.Equal(t, test.key, string(message.Key))
+			headers := map[string]string{}
+			for _, header := range message.Headers {
+				headers[header.Key] = string(header.Value)
+			}
+			require.Equal(t, test.headers, headers)
+
+			message.Partition = 1
+			message.Offset = 42
+			received := fromKafkaMessage(test.address, message)
+			require.Equal(t, topic+"/1/42", received.MessageID)
+			received.MessageID = ""
+			require.Equal(t, test.received, received)
+		})
+	}
+}
+
+func TestHonoKafkaMessageDeviceIDFromKey(t *testing.T) {
+	received := fromKafkaMessage(GetHonoEventAddress(testTenantID), kafka.Message{
+		Topic: "hono.event.test",
+		Key:   []byte(testThingID),
+		Value: []byte(`{}`),
+	})
+	require.Equal(t, testThingID, received.DeviceID)
+	require.Equal(t, GetHonoEventAddress(testTenantID), received.Address)
+}
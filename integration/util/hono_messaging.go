@@ -0,0 +1,244 @@
+// Copyright (c) 2026 Contributors to the Eclipse Foundation
+//
+// See the NOTICE file(s) distributed with this work for additional
+// information regarding copyright ownership.
+//
+// This program and the accompanying materials are made available under the
+// terms of the Eclipse Public License 2.0 which is available at
+// https://www.eclipse.org/legal/epl-2.0, or the Apache License, Version 2.0
+// which is available at https://www.apache.org/licenses/LICENSE-2.0.
+//
+// SPDX-License-Identifier: EPL-2.0 OR Apache-2.0
+
+package util
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/eclipse/ditto-clients-golang/protocol"
+	"github.com/google/uuid"
+)
+
+const (
+	// HonoMessagingAMQP is the messaging type of the tenants using the AMQP 1.0 messaging network
+	HonoMessagingAMQP = "amqp"
+
+	// HonoMessagingKafka is the messaging type of the tenants using the Kafka messaging network
+	HonoMessagingKafka = "kafka"
+
+	honoEventAddressPrefix           = "event"
+	honoTelemetryAddressPrefix       = "telemetry"
+	honoCommandAddressPrefix         = "command"
+	honoCommandResponseAddressPrefix = "command_response"
+
+	// HonoContentTypeDitto is the content type of the Ditto protocol messages exchanged with the devices
+	HonoContentTypeDitto = "application/vnd.eclipse.ditto+json"
+)
+
+// HonoMessage is a message exchanged with the Hono northbound messaging API.
+// It holds the properties, which are common to the AMQP 1.0 and the Kafka based messaging.
+type HonoMessage struct {
+	MessageID     string
+	CorrelationID string
+	// Address is the address of the message, e.g. "command/<tenant-id>/<device-id>" for commands
+	Address     string
+	ReplyTo     string
+	Subject     string
+	ContentType string
+	DeviceID    string
+	// Status is only set for command responses
+	Status     int
+	Properties map[string]interface{}
+	Payload    []byte
+}
+
+// Envelope decodes the payload of the message as a Ditto envelope
+func (message *HonoMessage) Envelope() (*protocol.Envelope, error) {
+	return DecodeDittoEnvelope(message.Payload)
+}
+
+// DecodeDittoEnvelope decodes a Ditto protocol message
+func DecodeDittoEnvelope(payload []byte) (*protocol.Envelope, error) {
+	envelope := &protocol.Envelope{}
+	if err := json.Unmarshal(payload, envelope); err != nil {
+		return nil, fmt.Errorf("invalid Ditto envelope: %v", err)
+	}
+	if envelope.Topic == nil {
+		return nil, errors.New("invalid Ditto envelope: missing topic")
+	}
+	return envelope, nil
+}
+
+// HonoMessaging is a connection to the Hono northbound messaging API
+type HonoMessaging interface {
+	// Receive starts receiving the messages sent to the address, e.g. "event/<tenant-id>"
+	Receive(ctx context.Context, address string) (HonoReceiver, error)
+	// Send sends a message to the address, e.g. "command/<tenant-id>"
+	Send(ctx context.Context, address string, message *HonoMessage) error
+	// Close closes the connection and all of its receivers
+	Close() error
+}
+
+// HonoReceiver receives the messages sent to an address
+type HonoReceiver interface {
+	// Receive waits for the next message
+	Receive(ctx context.Context) (*HonoMessage, error)
+	// Close stops receiving messages
+	Close() error
+}
+
+// NewHonoMessaging connects to the messaging network selected by the messaging type of the configuration
+func NewHonoMessaging(ctx context.Context, cfg *HonoConfiguration) (HonoMessaging, error) {
+	switch cfg.HonoMessagingType {
+	case HonoMessagingAMQP:
+		if cfg.HonoAMQPAddress == "" {
+			return nil, errors.New("Hono AMQP address is not specified")
+		}
+		return NewHonoAMQPConnection(ctx, cfg)
+	case HonoMessagingKafka:
+		if cfg.HonoKafkaBrokers == "" {
+			return nil, errors.New("Hono Kafka brokers are not specified")
+		}
+		return NewHonoKafkaConnection(ctx, cfg)
+	default:
+		return nil, fmt.Errorf("unsupported Hono messaging type %s", cfg.HonoMessagingType)
+	}
+}
+
+// HonoClient consumes the device events and telemetry and sends commands to the devices of a tenant
+// over the Hono northbound messaging API, which allows verifying the Hono hop without Ditto
+type HonoClient struct {
+	messaging HonoMessaging
+	tenantID  string
+}
+
+// NewHonoClient creates a client for the devices of the tenant
+func NewHonoClient(messaging HonoMessaging, tenantID string) *HonoClient {
+	return &HonoClient{messaging: messaging, tenantID: tenantID}
+}
+
+// GetHonoEventAddress returns the address of the events of a tenant
+func GetHonoEventAddress(tenantID string) string {
+	return honoEventAddressPrefix + "/" + tenantID
+}
+
+// GetHonoTelemetryAddress returns the address of the telemetry of a tenant
+func GetHonoTelemetryAddress(tenantID string) string {
+	return honoTelemetryAddressPrefix + "/" + tenantID
+}
+
+// GetHonoCommandAddress returns the address of the commands to a tenant.
+// If the device ID is not empty, the address of the commands to the device is returned.
+func GetHonoCommandAddress(tenantID string, deviceID string) string {
+	if deviceID == "" {
+		return honoCommandAddressPrefix + "/" + tenantID
+	}
+	return honoCommandAddressPrefix + "/" + tenantID + "/" + deviceID
+}
+
+// GetHonoCommandResponseAddress returns the address of the command responses to a reply ID
+func GetHonoCommandResponseAddress(tenantID string, replyID string) string {
+	return honoCommandResponseAddressPrefix + "/" + tenantID + "/" + replyID
+}
+
+// ParseHonoCommandAddress returns the tenant and device IDs of a device command address
+func ParseHonoCommandAddress(address string) (string, string, error) {
+	elements := strings.Split(address, "/")
+	if len(elements) != 3 || elements[0] != honoCommandAddressPrefix || elements[2] == "" {
+		return "", "", fmt.Errorf("not a device command address: %s", address)
+	}
+	return elements[1], elements[2], nil
+}
+
+// ConsumeEvents starts receiving the events of all devices of the tenant
+func (client *HonoClient) ConsumeEvents(ctx context.Context) (HonoReceiver, error) {
+	return client.messaging.Receive(ctx, GetHonoEventAddress(client.tenantID))
+}
+
+// ConsumeTelemetry starts receiving the telemetry of all devices of the tenant
+func (client *HonoClient) ConsumeTelemetry(ctx context.Context) (HonoReceiver, error) {
+	return client.messaging.Receive(ctx, GetHonoTelemetryAddress(client.tenantID))
+}
+
+// WaitForHonoEnvelope receives messages until a message from the device with a Ditto envelope accepted by the filter
+// arrives. Messages from other devices and messages, which are not Ditto envelopes, are skipped.
+// A nil filter accepts all envelopes.
+func WaitForHonoEnvelope(ctx context.Context, receiver HonoReceiver, deviceID string,
+	filter func(*protocol.Envelope) bool) (*HonoMessage, *protocol.Envelope, error) {
+	for {
+		message, err := receiver.Receive(ctx)
+		if err != nil {
+			return nil, nil, err
+		}
+		if deviceID != "" && message.DeviceID != deviceID {
+			continue
+		}
+		envelope, err := message.Envelope()
+		if err != nil {
+			continue
+		}
+		if filter == nil || filter(envelope) {
+			return message, envelope, nil
+		}
+	}
+}
+
+// SendOneWayCommand sends a command to a device without expecting a response
+func (client *HonoClient) SendOneWayCommand(ctx context.Context, deviceID string, subject string,
+	payload interface{}) error {
+	message, err := client.newCommand(deviceID, subject, payload)
+	if err != nil {
+		return err
+	}
+	return client.messaging.Send(ctx, GetHonoCommandAddress(client.tenantID, ""), message)
+}
+
+// SendCommand sends a command to a device and waits for its response until the context is done
+func (client *HonoClient) SendCommand(ctx context.Context, deviceID string, subject string,
+	payload interface{}) (*HonoMessage, error) {
+	message, err := client.newCommand(deviceID, subject, payload)
+	if err != nil {
+		return nil, err
+	}
+	message.CorrelationID = uuid.New().String()
+	message.ReplyTo = GetHonoCommandResponseAddress(client.tenantID, uuid.New().String())
+
+	receiver, err := client.messaging.Receive(ctx, message.ReplyTo)
+	if err != nil {
+		return nil, fmt.Errorf("unable to receive command responses: %v", err)
+	}
+	defer receiver.Close()
+
+	if err := client.messaging.Send(ctx, GetHonoCommandAddress(client.tenantID, ""), message); err != nil {
+		return nil, err
+	}
+
+	for {
+		response, err := receiver.Receive(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("response to command %s not received: %v", subject, err)
+		}
+		if response.CorrelationID == message.CorrelationID {
+			return response, nil
+		}
+	}
+}
+
+func (client *HonoClient) newCommand(deviceID string, subject string, payload interface{}) (*HonoMessage, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+	return &HonoMessage{
+		MessageID:   uuid.New().String(),
+		Address:     GetHonoCommandAddress(client.tenantID, deviceID),
+		Subject:     subject,
+		ContentType: HonoContentTypeDitto,
+		DeviceID:    deviceID,
+		Payload:     data,
+	}, nil
+}
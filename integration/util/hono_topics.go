@@ -0,0 +1,186 @@
+// Copyright (c) 2026 Contributors to the Eclipse Foundation
+//
+// See the NOTICE file(s) distributed with this work for additional
+// information regarding copyright ownership.
+//
+// This program and the accompanying materials are made available under the
+// terms of the Eclipse Public License 2.0 which is available at
+// https://www.eclipse.org/legal/epl-2.0, or the Apache License, Version 2.0
+// which is available at https://www.apache.org/licenses/LICENSE-2.0.
+//
+// SPDX-License-Identifier: EPL-2.0 OR Apache-2.0
+
+package util
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/eclipse/ditto-clients-golang/protocol"
+
+	MQTT "github.com/eclipse/paho.mqtt.golang"
+)
+
+// DeviceTopicKind is the kind of a message sent by a device to the local broker
+type DeviceTopicKind string
+
+const (
+	// KindEvent specifies an event message.
+	KindEvent DeviceTopicKind = "event"
+
+	// KindTelemetry specifies a telemetry message.
+	KindTelemetry DeviceTopicKind = "telemetry"
+
+	topicEventShort     = "e"
+	topicTelemetryShort = "t"
+	topicCommand        = "command"
+	topicCommandShort   = "c"
+
+	commandRequest  = "req"
+	commandResponse = "res"
+
+	deviceTopicTemplate          = "%s/%s/%s"
+	commandRequestTopicTemplate  = "command//%s/req/%s/%s"
+	commandResponseTopicTemplate = "command//%s/res/%s/%d"
+
+	// CommandRequestsTopicFilter matches the command requests for all devices.
+	CommandRequestsTopicFilter = "command//+/req/#"
+
+	// CommandResponsesTopicFilter matches the command responses of all devices.
+	CommandResponsesTopicFilter = "command//+/res/#"
+
+	qosEvent     = 1
+	qosTelemetry = 0
+	qosCommand   = 1
+)
+
+// DeviceTopic is a parsed event or telemetry topic
+type DeviceTopic struct {
+	Kind     DeviceTopicKind
+	TenantID string
+	DeviceID string
+}
+
+// CommandTopic is a parsed command request or command response topic
+type CommandTopic struct {
+	TenantID  string
+	DeviceID  string
+	Request   bool
+	RequestID string
+	// Subject is only set for command requests
+	Subject string
+	// Status is only set for command responses
+	Status int
+}
+
+// GetEventTopic returns the event topic of a device.
+// If the tenant and device IDs are empty, the topic for the connected device itself is returned.
+func GetEventTopic(tenantID string, deviceID string) string {
+	return getDeviceTopic(topicEventShort, tenantID, deviceID)
+}
+
+// GetTelemetryTopic returns the telemetry topic of a device.
+// If the tenant and device IDs are empty, the topic for the connected device itself is returned.
+func GetTelemetryTopic(tenantID string, deviceID string) string {
+	return getDeviceTopic(topicTelemetryShort, tenantID, deviceID)
+}
+
+func getDeviceTopic(prefix string, tenantID string, deviceID string) string {
+	if tenantID == "" && deviceID == "" {
+		return prefix
+	}
+	return fmt.Sprintf(deviceTopicTemplate, prefix, tenantID, deviceID)
+}
+
+// GetCommandRequestTopic returns the topic of a command request sent to a device
+func GetCommandRequestTopic(deviceID string, requestID string, subject string) string {
+	return fmt.Sprintf(commandRequestTopicTemplate, deviceID, requestID, subject)
+}
+
+// GetCommandResponseTopic returns the topic of a command response sent by a device
+func GetCommandResponseTopic(deviceID string, requestID string, status int) string {
+	return fmt.Sprintf(commandResponseTopicTemplate, deviceID, requestID, status)
+}
+
+// ParseDeviceTopic parses an event or telemetry topic in its short or long form,
+// e.g. "e", "telemetry" or "event/<tenant-id>/<device-id>".
+func ParseDeviceTopic(topic string) (*DeviceTopic, error) {
+	elements := strings.Split(topic, "/")
+
+	result := &DeviceTopic{}
+	switch elements[0] {
+	case topicEventShort, string(KindEvent):
+		result.Kind = KindEvent
+	case topicTelemetryShort, string(KindTelemetry):
+		result.Kind = KindTelemetry
+	default:
+		return nil, fmt.Errorf("not an event or telemetry topic: %s", topic)
+	}
+
+	switch len(elements) {
+	case 1:
+	case 3:
+		result.TenantID = elements[1]
+		result.DeviceID = elements[2]
+	default:
+		return nil, fmt.Errorf("invalid %s topic: %s", result.Kind, topic)
+	}
+	return result, nil
+}
+
+// ParseCommandTopic parses a command request or response topic in its short or long form,
+// e.g. "command//<device-id>/req/<request-id>/<subject>" or "c///res/<request-id>/<status>".
+func ParseCommandTopic(topic string) (*CommandTopic, error) {
+	elements := strings.Split(topic, "/")
+	if len(elements) != 6 || (elements[0] != topicCommand && elements[0] != topicCommandShort) {
+		return nil, fmt.Errorf("not a command topic: %s", topic)
+	}
+
+	result := &CommandTopic{
+		TenantID:  elements[1],
+		DeviceID:  elements[2],
+		RequestID: elements[4],
+	}
+	switch elements[3] {
+	case commandRequest:
+		result.Request = true
+		result.Subject = elements[5]
+	case commandResponse:
+		status, err := strconv.Atoi(elements[5])
+		if err != nil {
+			return nil, fmt.Errorf("invalid command response status in topic %s: %v", topic, err)
+		}
+		result.Status = status
+	default:
+		return nil, fmt.Errorf("invalid command topic: %s", topic)
+	}
+	return result, nil
+}
+
+// PublishEvent publishes a Ditto envelope as an event of a device with QoS 1.
+// If the tenant and device IDs are empty, the event is sent on behalf of the connected device itself.
+func PublishEvent(ctx context.Context, cfg *TestConfiguration, client MQTT.Client, tenantID string, deviceID string,
+	envelope *protocol.Envelope) error {
+	return publishJSON(ctx, cfg, client, GetEventTopic(tenantID, deviceID), qosEvent, envelope)
+}
+
+// PublishTelemetry publishes a Ditto envelope as telemetry of a device with QoS 0.
+// If the tenant and device IDs are empty, the telemetry is sent on behalf of the connected device itself.
+func PublishTelemetry(ctx context.Context, cfg *TestConfiguration, client MQTT.Client, tenantID string, deviceID string,
+	envelope *protocol.Envelope) error {
+	return publishJSON(ctx, cfg, client, GetTelemetryTopic(tenantID, deviceID), qosTelemetry, envelope)
+}
+
+// PublishCommandResponse publishes a Ditto envelope as a response to a command request with QoS 1
+func PublishCommandResponse(ctx context.Context, cfg *TestConfiguration, client MQTT.Client, deviceID string, requestID string,
+	status int, envelope *protocol.Envelope) error {
+	return publishJSON(ctx, cfg, client, GetCommandResponseTopic(deviceID, requestID, status), qosCommand, envelope)
+}
+
+// PublishCommandRequest publishes a Ditto envelope as a command request to a device with QoS 1
+func PublishCommandRequest(ctx context.Context, cfg *TestConfiguration, client MQTT.Client, deviceID string, requestID string,
+	subject string, envelope *protocol.Envelope) error {
+	return publishJSON(ctx, cfg, client, GetCommandRequestTopic(deviceID, requestID, subject), qosCommand, envelope)
+}
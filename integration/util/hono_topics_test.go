@@ -0,0 +1,140 @@
+// Copyright (c) 2026 Contributors to the Eclipse Foundation
+//
+// See the NOTICE file(s) distributed with this work for additional
+// information regarding copyright ownership.
+//
+// This program and the accompanying materials are made available under the
+// terms of the Eclipse Public License 2.0 which is available at
+// https://www.eclipse.org/legal/epl-2.0, or the Apache License, Version 2.0
+// which is available at https://www.apache.org/licenses/LICENSE-2.0.
+//
+// SPDX-License-Identifier: EPL-2.0 OR Apache-2.0
+
+package util
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseDeviceTopic(t *testing.T) {
+	tests := []struct {
+		topic    string
+		expected *DeviceTopic
+		err      string
+	}{
+		{topic: "e", expected: &DeviceTopic{Kind: KindEvent}},
+		{topic: "event", expected: &DeviceTopic{Kind: KindEvent}},
+		{topic: "t", expected: &DeviceTopic{Kind: KindTelemetry}},
+		{topic: "telemetry", expected: &DeviceTopic{Kind: KindTelemetry}},
+		{
+			topic:    "e/test/test:device",
+			expected: &DeviceTopic{Kind: KindEvent, TenantID: "test", DeviceID: "test:device"},
+		},
+		{
+			topic:    "telemetry/test/test:device",
+			expected: &DeviceTopic{Kind: KindTelemetry, TenantID: "test", DeviceID: "test:device"},
+		},
+		{
+			topic:    "t//test:device",
+			expected: &DeviceTopic{Kind: KindTelemetry, DeviceID: "test:device"},
+		},
+		{topic: GetEventTopic("", ""), expected: &DeviceTopic{Kind: KindEvent}},
+		{
+			topic:    GetTelemetryTopic("test", "test:device"),
+			expected: &DeviceTopic{Kind: KindTelemetry, TenantID: "test", DeviceID: "test:device"},
+		},
+		{topic: "e/test", err: "invalid event topic: e/test"},
+		{topic: "t/test/test:device/extra", err: "invalid telemetry topic: t/test/test:device/extra"},
+		{topic: "", err: "not an event or telemetry topic: "},
+		{topic: "c//test:device/req//modify", err: "not an event or telemetry topic: c//test:device/req//modify"},
+		{topic: "events/test/test:device", err: "not an event or telemetry topic: events/test/test:device"},
+	}
+	for _, test := range tests {
+		t.Run(test.topic, func(t *testing.T) {
+			topic, err := ParseDeviceTopic(test.topic)
+			if test.err != "" {
+				require.EqualError(t, err, test.err)
+				require.Nil(t, topic)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, test.expected, topic)
+		})
+	}
+}
+
+func TestParseCommandTopic(t *testing.T) {
+	invalidStatus := "invalid command response status in topic command///res/request-1/%s: " +
+		`strconv.Atoi: parsing "%s": invalid syntax`
+	tests := []struct {
+		topic    string
+		expected *CommandTopic
+		err      string
+	}{
+		{
+			topic:    "command//test:device/req/request-1/modify",
+			expected: &CommandTopic{DeviceID: "test:device", Request: true, RequestID: "request-1", Subject: "modify"},
+		},
+		{
+			topic:    "c//test:device/req//modify",
+			expected: &CommandTopic{DeviceID: "test:device", Request: true, Subject: "modify"},
+		},
+		{
+			topic: "command/test/test:device/req/request-1/modify",
+			expected: &CommandTopic{TenantID: "test", DeviceID: "test:device", Request: true,
+				RequestID: "request-1", Subject: "modify"},
+		},
+		{
+			topic:    "command///res/request-1/200",
+			expected: &CommandTopic{RequestID: "request-1", Status: http.StatusOK},
+		},
+		{
+			topic:    "c//test:device/res/request-1/404",
+			expected: &CommandTopic{DeviceID: "test:device", RequestID: "request-1", Status: http.StatusNotFound},
+		},
+		{
+			topic:    GetCommandRequestTopic("test:device", "request-1", "modify"),
+			expected: &CommandTopic{DeviceID: "test:device", Request: true, RequestID: "request-1", Subject: "modify"},
+		},
+		{
+			topic:    GetCommandResponseTopic("test:device", "request-1", http.StatusAccepted),
+			expected: &CommandTopic{DeviceID: "test:device", RequestID: "request-1", Status: http.StatusAccepted},
+		},
+		{
+			topic: "command///res/request-1/OK",
+			err:   fmt.Sprintf(invalidStatus, "OK", "OK"),
+		},
+		{
+			topic: "command///res/request-1/",
+			err:   fmt.Sprintf(invalidStatus, "", ""),
+		},
+		{
+			topic: "command//test:device/ack/request-1/modify",
+			err:   "invalid command topic: command//test:device/ack/request-1/modify",
+		},
+		{topic: "command//test:device/req/request-1", err: "not a command topic: command//test:device/req/request-1"},
+		{topic: "command//test:device/req", err: "not a command topic: command//test:device/req"},
+		{topic: "c", err: "not a command topic: c"},
+		{
+			topic: "command//test:device/req/request-1/modify/extra",
+			err:   "not a command topic: command//test:device/req/request-1/modify/extra",
+		},
+		{topic: "e//test:device/req/request-1/modify", err: "not a command topic: e//test:device/req/request-1/modify"},
+	}
+	for _, test := range tests {
+		t.Run(test.topic, func(t *testing.T) {
+			topic, err := ParseCommandTopic(test.topic)
+			if test.err != "" {
+				require.EqualError(t, err, test.err)
+				require.Nil(t, topic)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, test.expected, topic)
+		})
+	}
+}
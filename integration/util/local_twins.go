@@ -0,0 +1,371 @@
+// Copyright (c) 2026 Contributors to the Eclipse Foundation
+//
+// See the NOTICE file(s) distributed with this work for additional
+// information regarding copyright ownership.
+//
+// This program and the accompanying materials are made available under the
+// terms of the Eclipse Public License 2.0 which is available at
+// https://www.eclipse.org/legal/epl-2.0, or the Apache License, Version 2.0
+// which is available at https://www.apache.org/licenses/LICENSE-2.0.
+//
+// SPDX-License-Identifier: EPL-2.0 OR Apache-2.0
+
+package util
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/eclipse/ditto-clients-golang/model"
+	"github.com/eclipse/ditto-clients-golang/protocol"
+	"github.com/google/uuid"
+
+	MQTT "github.com/eclipse/paho.mqtt.golang"
+)
+
+const (
+	// localTwinsTopicFilter matches the responses and twin events sent by the local digital twins,
+	// with or without a tenant ID in the topic
+	localTwinsTopicFilter = "command/+/+/req/#"
+
+	localTwinsReplyToTemplate = "command/%s"
+	localTwinsResponseTimeout = 10 * time.Second
+
+	// localTwinsAllThingsID is the placeholder thing ID of the commands to multiple things
+	localTwinsAllThingsID = "_:_"
+
+	thingPath             = "/"
+	attributePathTemplate = "/attributes/%s"
+	featurePathTemplate   = "/features/%s"
+)
+
+// LocalTwins accesses the local digital twins of the device with Ditto protocol commands over the local broker.
+// The commands are sent as events of the device, the responses and the twin events are received as command requests.
+// A separate MQTT connection is used, so the command subscription of the suite Ditto client is kept.
+type LocalTwins struct {
+	cfg      *TestConfiguration
+	client   MQTT.Client
+	tenantID string
+	deviceID string
+
+	mutex         sync.Mutex
+	pending       map[string]chan *protocol.Envelope
+	subscriptions map[*localTwinEvents]bool
+}
+
+// NewLocalTwins connects to the local broker with a random client ID and subscribes for the responses
+// and the twin events of the local digital twins of the configured thing
+func NewLocalTwins(ctx context.Context, cfg *TestConfiguration, thingCfg *ThingConfiguration) (*LocalTwins, error) {
+	clientCfg := *cfg
+	clientCfg.MQTTClientID = ""
+	client, err := NewMQTTClient(ctx, &clientCfg)
+	if err != nil {
+		return nil, fmt.Errorf("unable to connect to the local broker: %v", err)
+	}
+
+	twins := &LocalTwins{
+		cfg:           cfg,
+		client:        client,
+		tenantID:      thingCfg.TenantID,
+		deviceID:      thingCfg.DeviceID,
+		pending:       map[string]chan *protocol.Envelope{},
+		subscriptions: map[*localTwinEvents]bool{},
+	}
+	subscribeCtx, cancel := context.WithTimeout(ctx, MillisToDuration(cfg.MQTTAcknowledgeTimeoutMS))
+	defer cancel()
+	if err := waitForToken(subscribeCtx, client.Subscribe(localTwinsTopicFilter, qosCommand, twins.handle)); err != nil {
+		client.Disconnect(uint(cfg.MQTTQuiesceMS))
+		return nil, fmt.Errorf("unable to subscribe to %s: %v", localTwinsTopicFilter, err)
+	}
+	return twins, nil
+}
+
+// Close disconnects from the local broker
+func (twins *LocalTwins) Close() {
+	twins.client.Disconnect(uint(twins.cfg.MQTTQuiesceMS))
+}
+
+// Thing returns a client of the local digital twin of the thing with the given ID
+func (twins *LocalTwins) Thing(thingID string) *LocalThingClient {
+	return &LocalThingClient{twins: twins, ThingID: thingID}
+}
+
+// RetrieveThings retrieves multiple things with a single command, e.g. the device and its containers thing
+func (twins *LocalTwins) RetrieveThings(ctx context.Context, thingIDs ...string) ([]map[string]interface{}, error) {
+	request := map[string][]string{"thingIds": thingIDs}
+	response, err := twins.execute(ctx, localTwinsAllThingsID, protocol.ActionRetrieve, thingPath, request)
+	if err != nil {
+		return nil, err
+	}
+	var things []map[string]interface{}
+	if err := Convert(response.Value, &things); err != nil {
+		return nil, fmt.Errorf("invalid things %v: %v", thingIDs, err)
+	}
+	return things, nil
+}
+
+// SubscribeForTwinEvents subscribes for the twin events of the thing sent by the local digital twins
+func (twins *LocalTwins) SubscribeForTwinEvents(thingID string) TwinEvents {
+	events := &localTwinEvents{
+		twins:    twins,
+		thingID:  thingID,
+		received: make(chan struct{}, 1),
+	}
+	twins.mutex.Lock()
+	defer twins.mutex.Unlock()
+	twins.subscriptions[events] = true
+	return events
+}
+
+// execute sends a twin command and waits for its response, unless the request options disable the response.
+// The request options are applied to the command headers.
+func (twins *LocalTwins) execute(ctx context.Context, thingID string, action protocol.TopicAction, path string,
+	value interface{}, opts ...RequestOption) (*protocol.Envelope, error) {
+	header := http.Header{}
+	for _, opt := range opts {
+		opt(header)
+	}
+	correlationID := header.Get(protocol.HeaderCorrelationID)
+	if correlationID == "" {
+		correlationID = uuid.New().String()
+	}
+	responseRequired := header.Get(protocol.HeaderResponseRequired) != "false"
+	header.Del(protocol.HeaderCorrelationID)
+	header.Del(protocol.HeaderResponseRequired)
+
+	headerOpts := []protocol.HeaderOpt{
+		protocol.WithCorrelationID(correlationID),
+		protocol.WithReplyTo(fmt.Sprintf(localTwinsReplyToTemplate, twins.tenantID)),
+		protocol.WithResponseRequired(responseRequired),
+	}
+	for name := range header {
+		headerOpts = append(headerOpts, protocol.WithGeneric(strings.ToLower(name), header.Get(name)))
+	}
+	command := (&protocol.Envelope{}).
+		WithTopic(newThingTopic(thingID, protocol.ChannelTwin, protocol.CriterionCommands, action)).
+		WithHeaders(protocol.NewHeaders(headerOpts...)).
+		WithPath(path).
+		WithValue(value)
+
+	if !responseRequired {
+		return nil, PublishEvent(ctx, twins.cfg, twins.client, twins.tenantID, twins.deviceID, command)
+	}
+
+	ch := make(chan *protocol.Envelope, 1)
+	twins.mutex.Lock()
+	twins.pending[correlationID] = ch
+	twins.mutex.Unlock()
+	defer func() {
+		twins.mutex.Lock()
+		delete(twins.pending, correlationID)
+		twins.mutex.Unlock()
+	}()
+
+	if err := PublishEvent(ctx, twins.cfg, twins.client, twins.tenantID, twins.deviceID, command); err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, localTwinsResponseTimeout)
+	defer cancel()
+	select {
+	case response := <-ch:
+		if response.Status < http.StatusOK || response.Status >= http.StatusMultipleChoices {
+			message := response.Value
+			if object, ok := response.Value.(map[string]interface{}); ok && object["message"] != nil {
+				message = object["message"]
+			}
+			return response, fmt.Errorf("%s %s of thing %s failed with status %d: %v",
+				action, path, thingID, response.Status, message)
+		}
+		return response, nil
+	case <-ctx.Done():
+		return nil, fmt.Errorf("no response to %s %s of thing %s: %v", action, path, thingID, ctx.Err())
+	}
+}
+
+// handle delivers the responses to the pending commands and the twin events to the subscriptions
+func (twins *LocalTwins) handle(client MQTT.Client, message MQTT.Message) {
+	envelope := &protocol.Envelope{}
+	if err := json.Unmarshal(message.Payload(), envelope); err != nil || envelope.Topic == nil {
+		return
+	}
+
+	twins.mutex.Lock()
+	defer twins.mutex.Unlock()
+
+	if envelope.Status != 0 {
+		if envelope.Headers == nil {
+			return
+		}
+		if ch, ok := twins.pending[envelope.Headers.CorrelationID()]; ok {
+			select {
+			case ch <- envelope:
+			default:
+			}
+		}
+		return
+	}
+
+	topic := envelope.Topic
+	if topic.Channel != protocol.ChannelTwin || topic.Criterion != protocol.CriterionEvents {
+		return
+	}
+	thingID := topic.Namespace + ":" + topic.EntityName
+	for events := range twins.subscriptions {
+		if events.thingID == thingID {
+			events.add(envelope)
+		}
+	}
+}
+
+// localTwinEvents queues the twin events of a thing received from the local digital twins
+type localTwinEvents struct {
+	twins   *LocalTwins
+	thingID string
+
+	mutex    sync.Mutex
+	queue    []*protocol.Envelope
+	received chan struct{}
+}
+
+func (events *localTwinEvents) add(envelope *protocol.Envelope) {
+	events.mutex.Lock()
+	events.queue = append(events.queue, envelope)
+	events.mutex.Unlock()
+
+	select {
+	case events.received <- struct{}{}:
+	default:
+	}
+}
+
+func (events *localTwinEvents) next() (*protocol.Envelope, bool) {
+	events.mutex.Lock()
+	defer events.mutex.Unlock()
+	if len(events.queue) == 0 {
+		return nil, false
+	}
+	envelope := events.queue[0]
+	events.queue = events.queue[1:]
+	return envelope, true
+}
+
+func (events *localTwinEvents) Process(ctx context.Context, process func(*protocol.Envelope) (bool, error)) error {
+	timeout := MillisToDuration(events.twins.cfg.WSEventTimeoutMS)
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	var err error
+	for {
+		if envelope, ok := events.next(); ok {
+			var finished bool
+			if finished, err = process(envelope); finished {
+				return err
+			}
+			continue
+		}
+
+		select {
+		case <-events.received:
+		case <-timer.C:
+			return fmt.Errorf("not finished, expected twin event not received in %v, last error: %v", timeout, err)
+		case <-ctx.Done():
+			return fmt.Errorf("error waiting for twin events: %v, last error: %v", ctx.Err(), err)
+		}
+	}
+}
+
+func (events *localTwinEvents) Close() error {
+	events.twins.mutex.Lock()
+	defer events.twins.mutex.Unlock()
+	delete(events.twins.subscriptions, events)
+	return nil
+}
+
+// LocalThingClient reads and modifies the local digital twin of a single thing
+type LocalThingClient struct {
+	twins *LocalTwins
+
+	ThingID string
+}
+
+// GetThing retrieves the whole thing
+func (client *LocalThingClient) GetThing(ctx context.Context) (map[string]interface{}, error) {
+	thing := map[string]interface{}{}
+	if err := client.retrieve(ctx, thingPath, &thing); err != nil {
+		return nil, err
+	}
+	return thing, nil
+}
+
+// GetAttribute retrieves an attribute of the thing and unmarshals it to the given value
+func (client *LocalThingClient) GetAttribute(ctx context.Context, attribute string, value interface{}) error {
+	return client.retrieve(ctx, fmt.Sprintf(attributePathTemplate, attribute), value)
+}
+
+// PutAttribute creates or modifies an attribute of the thing
+func (client *LocalThingClient) PutAttribute(ctx context.Context, attribute string, value interface{},
+	opts ...RequestOption) error {
+	return client.modify(ctx, fmt.Sprintf(attributePathTemplate, attribute), value, opts...)
+}
+
+// GetFeature retrieves a feature of the thing
+func (client *LocalThingClient) GetFeature(ctx context.Context, featureID string) (*model.Feature, error) {
+	feature := &model.Feature{}
+	if err := client.retrieve(ctx, fmt.Sprintf(featurePathTemplate, featureID), feature); err != nil {
+		return nil, err
+	}
+	return feature, nil
+}
+
+// PutFeature creates or modifies a feature of the thing
+func (client *LocalThingClient) PutFeature(ctx context.Context, featureID string, feature *model.Feature,
+	opts ...RequestOption) error {
+	return client.modify(ctx, fmt.Sprintf(featurePathTemplate, featureID), feature, opts...)
+}
+
+// DeleteFeature deletes a feature of the thing
+func (client *LocalThingClient) DeleteFeature(ctx context.Context, featureID string) error {
+	return client.delete(ctx, fmt.Sprintf(featurePathTemplate, featureID))
+}
+
+// GetFeatureProperty retrieves a property of a feature and unmarshals it to the given value
+func (client *LocalThingClient) GetFeatureProperty(ctx context.Context, featureID string, property string,
+	value interface{}) error {
+	return client.retrieve(ctx, GetFeaturePropertyPath(featureID, property), value)
+}
+
+// PutFeatureProperty creates or modifies a property of a feature
+func (client *LocalThingClient) PutFeatureProperty(ctx context.Context, featureID string, property string,
+	value interface{}, opts ...RequestOption) error {
+	return client.modify(ctx, GetFeaturePropertyPath(featureID, property), value, opts...)
+}
+
+// DeleteFeatureProperty deletes a property of a feature
+func (client *LocalThingClient) DeleteFeatureProperty(ctx context.Context, featureID string, property string) error {
+	return client.delete(ctx, GetFeaturePropertyPath(featureID, property))
+}
+
+func (client *LocalThingClient) retrieve(ctx context.Context, path string, value interface{}) error {
+	response, err := client.twins.execute(ctx, client.ThingID, protocol.ActionRetrieve, path, nil)
+	if err != nil {
+		return err
+	}
+	return Convert(response.Value, value)
+}
+
+func (client *LocalThingClient) modify(ctx context.Context, path string, value interface{},
+	opts ...RequestOption) error {
+	_, err := client.twins.execute(ctx, client.ThingID, protocol.ActionModify, path, value, opts...)
+	return err
+}
+
+func (client *LocalThingClient) delete(ctx context.Context, path string) error {
+	_, err := client.twins.execute(ctx, client.ThingID, protocol.ActionDelete, path, nil)
+	return err
+}
@@ -0,0 +1,281 @@
+// Copyright (c) 2026 Contributors to the Eclipse Foundation
+//
+// See the NOTICE file(s) distributed with this work for additional
+// information regarding copyright ownership.
+//
+// This program and the accompanying materials are made available under the
+// terms of the Eclipse Public License 2.0 which is available at
+// https://www.eclipse.org/legal/epl-2.0, or the Apache License, Version 2.0
+// which is available at https://www.apache.org/licenses/LICENSE-2.0.
+//
+// SPDX-License-Identifier: EPL-2.0 OR Apache-2.0
+
+package util
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/eclipse/ditto-clients-golang/model"
+	"github.com/eclipse/ditto-clients-golang/protocol"
+	"github.com/stretchr/testify/require"
+)
+
+// testTwinStore plays the local digital twins on the local broker. It receives the twin commands
+// sent as events of the device and publishes the responses and the twin events to their reply-to topic.
+type testTwinStore struct {
+	broker *LocalBroker
+
+	mutex    sync.Mutex
+	things   map[string]map[string]interface{}
+	commands []string
+}
+
+func startTestTwinStore(t *testing.T, broker *LocalBroker, thingIDs ...string) *testTwinStore {
+	store := &testTwinStore{broker: broker, things: map[string]map[string]interface{}{}}
+	for _, thingID := range thingIDs {
+		store.things[thingID] = map[string]interface{}{"thingId": thingID}
+	}
+	unsubscribe, err := broker.Subscribe(GetEventTopic(testTenantID, testThingID), store.handle)
+	require.NoError(t, err)
+	t.Cleanup(unsubscribe)
+	return store
+}
+
+// startTestLocalTwins starts a local broker with a twin store of the given things
+// and returns the local twins of the test device connected to it
+func startTestLocalTwins(t *testing.T, thingIDs ...string) (*LocalTwins, *testTwinStore) {
+	broker, cfg := startTestBroker(t)
+	store := startTestTwinStore(t, broker, thingIDs...)
+	ctx, cancel := NewTestContext(t)
+	defer cancel()
+
+	twins, err := NewLocalTwins(ctx, cfg, &ThingConfiguration{DeviceID: testThingID, TenantID: testTenantID})
+	require.NoError(t, err)
+	t.Cleanup(twins.Close)
+	return twins, store
+}
+
+func (store *testTwinStore) handle(topic string, payload []byte) {
+	command := &protocol.Envelope{}
+	if err := json.Unmarshal(payload, command); err != nil || command.Topic == nil || command.Headers == nil {
+		return
+	}
+	thingID := command.Topic.Namespace + ":" + command.Topic.EntityName
+
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+	store.commands = append(store.commands,
+		fmt.Sprintf("%s %s %s reply-to %s", command.Topic.Action, thingID, command.Path, command.Headers.ReplyTo()))
+
+	status, value := http.StatusOK, interface{}(nil)
+	var event *protocol.Envelope
+	switch {
+	case thingID == localTwinsAllThingsID:
+		request := struct {
+			ThingIDs []string `json:"thingIds"`
+		}{}
+		if err := Convert(command.Value, &request); err != nil {
+			status = http.StatusBadRequest
+			break
+		}
+		things := []interface{}{}
+		for _, id := range request.ThingIDs {
+			if thing, ok := store.things[id]; ok {
+				things = append(things, thing)
+			}
+		}
+		value = things
+	case store.things[thingID] == nil:
+		status, value = http.StatusNotFound, map[string]interface{}{"message": "thing not found"}
+	case command.Topic.Action == protocol.ActionRetrieve:
+		var ok bool
+		if value, ok = store.get(thingID, command.Path); !ok {
+			status, value = http.StatusNotFound, map[string]interface{}{"message": "path not found"}
+		}
+	case command.Topic.Action == protocol.ActionModify:
+		store.set(thingID, command.Path, command.Value)
+		status = http.StatusNoContent
+		event = (&protocol.Envelope{}).
+			WithTopic(newThingTopic(thingID, protocol.ChannelTwin, protocol.CriterionEvents, protocol.ActionModified)).
+			WithHeaders(protocol.NewHeaders()).
+			WithPath(command.Path).
+			WithValue(command.Value)
+	default:
+		status = http.StatusBadRequest
+	}
+
+	replyTo := command.Headers.ReplyTo()
+	if command.Headers.IsResponseRequired() {
+		response := (&protocol.Envelope{}).
+			WithTopic(command.Topic).
+			WithHeaders(protocol.NewHeaders(protocol.WithCorrelationID(command.Headers.CorrelationID()))).
+			WithPath(command.Path).
+			WithValue(value).
+			WithStatus(status)
+		store.publish(replyTo, response)
+	}
+	if event != nil {
+		store.publish(replyTo, event)
+	}
+}
+
+// publish sends the envelope as a command of the device to the reply-to topic of the twin command
+func (store *testTwinStore) publish(replyTo string, envelope *protocol.Envelope) {
+	data, err := json.Marshal(envelope)
+	if err != nil {
+		return
+	}
+	// The broker calls the handler synchronously, so the message is published asynchronously
+	go store.broker.Publish(fmt.Sprintf("%s/%s/req//%s", replyTo, testThingID, envelope.Topic.Action), data, 1)
+}
+
+func (store *testTwinStore) get(thingID string, path string) (interface{}, bool) {
+	var value interface{} = store.things[thingID]
+	for _, segment := range strings.FieldsFunc(path, func(r rune) bool { return r == '/' }) {
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		if value, ok = object[segment]; !ok {
+			return nil, false
+		}
+	}
+	return value, true
+}
+
+func (store *testTwinStore) set(thingID string, path string, value interface{}) {
+	object := store.things[thingID]
+	segments := strings.FieldsFunc(path, func(r rune) bool { return r == '/' })
+	for i, segment := range segments {
+		if i == len(segments)-1 {
+			object[segment] = value
+			return
+		}
+		child, ok := object[segment].(map[string]interface{})
+		if !ok {
+			child = map[string]interface{}{}
+			object[segment] = child
+		}
+		object = child
+	}
+}
+
+func (store *testTwinStore) receivedCommands() []string {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+	return append([]string(nil), store.commands...)
+}
+
+func TestLocalThingClientModifyAndRetrieve(t *testing.T) {
+	twins, store := startTestLocalTwins(t, testThingID)
+	ctx, cancel := NewTestContext(t)
+	defer cancel()
+
+	client := twins.Thing(testThingID)
+	require.NoError(t, client.PutAttribute(ctx, "location", "lab"))
+	require.NoError(t, client.PutFeature(ctx, "test", (&model.Feature{}).WithProperty("status", "idle")))
+	require.NoError(t, client.PutFeatureProperty(ctx, "test", "status", "running"))
+
+	var location string
+	require.NoError(t, client.GetAttribute(ctx, "location", &location))
+	require.Equal(t, "lab", location)
+	var status string
+	require.NoError(t, client.GetFeatureProperty(ctx, "test", "status", &status))
+	require.Equal(t, "running", status)
+	feature, err := client.GetFeature(ctx, "test")
+	require.NoError(t, err)
+	require.Equal(t, map[string]interface{}{"status": "running"}, feature.Properties)
+	thing, err := client.GetThing(ctx)
+	require.NoError(t, err)
+	require.Equal(t, testThingID, thing["thingId"])
+
+	require.Equal(t, []string{
+		"modify test:device /attributes/location reply-to command/test",
+		"modify test:device /features/test reply-to command/test",
+		"modify test:device /features/test/properties/status reply-to command/test",
+		"retrieve test:device /attributes/location reply-to command/test",
+		"retrieve test:device /features/test/properties/status reply-to command/test",
+		"retrieve test:device /features/test reply-to command/test",
+		"retrieve test:device / reply-to command/test",
+	}, store.receivedCommands())
+}
+
+func TestLocalThingClientFailedResponse(t *testing.T) {
+	twins, _ := startTestLocalTwins(t, testThingID)
+	ctx, cancel := NewTestContext(t)
+	defer cancel()
+
+	var location string
+	err := twins.Thing(testThingID).GetAttribute(ctx, "location", &location)
+	require.EqualError(t, err, "retrieve /attributes/location of thing test:device failed with status 404: path not found")
+
+	_, err = twins.Thing("test:unknown").GetThing(ctx)
+	require.EqualError(t, err, "retrieve / of thing test:unknown failed with status 404: thing not found")
+}
+
+func TestLocalThingClientWithoutResponse(t *testing.T) {
+	twins, store := startTestLocalTwins(t, testThingID)
+	ctx, cancel := NewTestContext(t)
+	defer cancel()
+
+	client := twins.Thing(testThingID)
+	require.NoError(t, client.PutAttribute(ctx, "location", "lab", WithResponseRequired(false)))
+	// The next command is handled after the previous one, so the attribute is already modified
+	var location string
+	require.NoError(t, client.GetAttribute(ctx, "location", &location))
+	require.Equal(t, "lab", location)
+	require.Equal(t, []string{
+		"modify test:device /attributes/location reply-to command/test",
+		"retrieve test:device /attributes/location reply-to command/test",
+	}, store.receivedCommands())
+}
+
+func TestLocalTwinsRetrieveThings(t *testing.T) {
+	containersThingID := GetContainersThingID(testThingID)
+	twins, store := startTestLocalTwins(t, testThingID, containersThingID)
+	ctx, cancel := NewTestContext(t)
+	defer cancel()
+
+	things, err := twins.RetrieveThings(ctx, testThingID, containersThingID, "test:unknown")
+	require.NoError(t, err)
+	require.Len(t, things, 2)
+	require.Equal(t, testThingID, things[0]["thingId"])
+	require.Equal(t, containersThingID, things[1]["thingId"])
+	require.Equal(t, []string{"retrieve _:_ / reply-to command/test"}, store.receivedCommands())
+}
+
+func TestLocalTwinsSubscribeForTwinEvents(t *testing.T) {
+	otherThingID := "test:other"
+	twins, _ := startTestLocalTwins(t, testThingID, otherThingID)
+	ctx, cancel := NewTestContext(t)
+	defer cancel()
+
+	events := twins.SubscribeForTwinEvents(testThingID)
+	defer events.Close()
+
+	require.NoError(t, twins.Thing(otherThingID).PutAttribute(ctx, "location", "office"))
+	require.NoError(t, twins.Thing(testThingID).PutAttribute(ctx, "location", "lab"))
+	require.NoError(t, twins.Thing(testThingID).PutAttribute(ctx, "floor", 1))
+
+	var received []string
+	err := events.Process(ctx, func(event *protocol.Envelope) (bool, error) {
+		thingID := event.Topic.Namespace + ":" + event.Topic.EntityName
+		received = append(received, fmt.Sprintf("%s %s %s %v", event.Topic.Action, thingID, event.Path, event.Value))
+		return len(received) == 2, nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		"modified test:device /attributes/location lab",
+		"modified test:device /attributes/floor 1",
+	}, received)
+
+	require.NoError(t, events.Close())
+	twins.mutex.Lock()
+	defer twins.mutex.Unlock()
+	require.Empty(t, twins.subscriptions)
+}
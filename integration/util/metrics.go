@@ -0,0 +1,283 @@
+// Copyright (c) 2026 Contributors to the Eclipse Foundation
+//
+// See the NOTICE file(s) distributed with this work for additional
+// information regarding copyright ownership.
+//
+// This program and the accompanying materials are made available under the
+// terms of the Eclipse Public License 2.0 which is available at
+// https://www.eclipse.org/legal/epl-2.0, or the Apache License, Version 2.0
+// which is available at https://www.apache.org/licenses/LICENSE-2.0.
+//
+// SPDX-License-Identifier: EPL-2.0 OR Apache-2.0
+
+package util
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/eclipse/ditto-clients-golang/protocol"
+)
+
+const (
+	// MetricsFeatureID is the ID of the Metrics feature
+	MetricsFeatureID = "Metrics"
+
+	// MetricsOriginatorSystem is the originator of the metrics of the whole device
+	MetricsOriginatorSystem = "SYSTEM"
+
+	// MetricCPUUtilization is the CPU utilization in percent
+	MetricCPUUtilization = "cpu.utilization"
+	// MetricCPULoad1 is the CPU load average for 1 minute
+	MetricCPULoad1 = "cpu.load1"
+	// MetricCPULoad5 is the CPU load average for 5 minutes
+	MetricCPULoad5 = "cpu.load5"
+	// MetricCPULoad15 is the CPU load average for 15 minutes
+	MetricCPULoad15 = "cpu.load15"
+	// MetricMemoryUtilization is the memory utilization in percent
+	MetricMemoryUtilization = "memory.utilization"
+	// MetricMemoryTotal is the total memory in bytes
+	MetricMemoryTotal = "memory.total"
+	// MetricMemoryAvailable is the available memory in bytes
+	MetricMemoryAvailable = "memory.available"
+	// MetricMemoryUsed is the used memory in bytes
+	MetricMemoryUsed = "memory.used"
+	// MetricIOReadBytes is the count of bytes read from the disks
+	MetricIOReadBytes = "io.readBytes"
+	// MetricIOWriteBytes is the count of bytes written to the disks
+	MetricIOWriteBytes = "io.writeBytes"
+	// MetricNetReadBytes is the count of bytes received over the network
+	MetricNetReadBytes = "net.readBytes"
+	// MetricNetWriteBytes is the count of bytes sent over the network
+	MetricNetWriteBytes = "net.writeBytes"
+	// MetricPIDs is the count of processes
+	MetricPIDs = "pids"
+
+	// MetricsCPU selects all CPU metrics in a filter
+	MetricsCPU = "cpu.*"
+	// MetricsMemory selects all memory metrics in a filter
+	MetricsMemory = "memory.*"
+	// MetricsIO selects all disk IO metrics in a filter
+	MetricsIO = "io.*"
+	// MetricsNet selects all network metrics in a filter
+	MetricsNet = "net.*"
+
+	metricsMessageRequest = "request"
+	metricsMessageData    = "data"
+)
+
+// MetricsFilter selects the metrics of an originator to be reported. The metric IDs may contain wildcards, e.g. "cpu.*".
+type MetricsFilter struct {
+	ID         []string `json:"id,omitempty"`
+	Originator string   `json:"originator,omitempty"`
+}
+
+// MetricsRequest is the value of the request message, which starts or stops the metrics reporting
+type MetricsRequest struct {
+	// Frequency is the reporting interval as a duration string, e.g. "5s", zero stops the reporting
+	Frequency string           `json:"frequency"`
+	Filter    []*MetricsFilter `json:"filter,omitempty"`
+}
+
+// NewMetricsFilter creates a filter of the metrics of the originator, all originators are selected if empty
+func NewMetricsFilter(originator string, ids ...string) *MetricsFilter {
+	return &MetricsFilter{ID: ids, Originator: originator}
+}
+
+// NewMetricsRequest creates a request of the metrics selected by the filters, all metrics are reported if none
+func NewMetricsRequest(frequency time.Duration, filters ...*MetricsFilter) *MetricsRequest {
+	return &MetricsRequest{Frequency: frequency.String(), Filter: filters}
+}
+
+// MetricsMeasurement is a measured value of a metric
+type MetricsMeasurement struct {
+	ID    string  `json:"id"`
+	Value float64 `json:"value"`
+}
+
+// MetricsSnapshot holds the measurements of an originator collected at the same time
+type MetricsSnapshot struct {
+	Originator   string                `json:"originator"`
+	Measurements []*MetricsMeasurement `json:"measurements"`
+}
+
+// MetricsData is the value of the data message, which is sent from the feature outbox on each report
+type MetricsData struct {
+	Snapshot []*MetricsSnapshot `json:"snapshot"`
+	// Timestamp is the Unix time in milliseconds of the report
+	Timestamp int64 `json:"timestamp"`
+}
+
+// MetricSample is a value of a metric reported at a time
+type MetricSample struct {
+	Time  time.Time
+	Value float64
+}
+
+// MetricSeries holds the reported values of a metric of an originator in the order of their arrival
+type MetricSeries struct {
+	Originator string
+	ID         string
+	Samples    []MetricSample
+}
+
+// MetricStats are the aggregated values of a metric series
+type MetricStats struct {
+	Count int
+	Min   float64
+	Max   float64
+	Avg   float64
+	Last  float64
+}
+
+// Stats aggregates the values of the series, nil is returned if the series has no values
+func (series *MetricSeries) Stats() *MetricStats {
+	if len(series.Samples) == 0 {
+		return nil
+	}
+	stats := &MetricStats{
+		Count: len(series.Samples),
+		Min:   series.Samples[0].Value,
+		Max:   series.Samples[0].Value,
+		Last:  series.Samples[len(series.Samples)-1].Value,
+	}
+	sum := 0.0
+	for _, sample := range series.Samples {
+		if sample.Value < stats.Min {
+			stats.Min = sample.Value
+		}
+		if sample.Value > stats.Max {
+			stats.Max = sample.Value
+		}
+		sum += sample.Value
+	}
+	stats.Avg = sum / float64(stats.Count)
+	return stats
+}
+
+// MetricsCollection collects the reported metrics data into series per originator and metric ID
+type MetricsCollection struct {
+	// Reports is the count of the collected data messages
+	Reports int
+
+	series map[string]*MetricSeries
+}
+
+// NewMetricsCollection creates an empty metrics collection
+func NewMetricsCollection() *MetricsCollection {
+	return &MetricsCollection{series: map[string]*MetricSeries{}}
+}
+
+// Add adds the measurements of the data to the series. The receiving time is used if the data has no timestamp.
+func (collection *MetricsCollection) Add(data *MetricsData) {
+	timestamp := time.Now()
+	if data.Timestamp > 0 {
+		timestamp = time.UnixMilli(data.Timestamp)
+	}
+	for _, snapshot := range data.Snapshot {
+		for _, measurement := range snapshot.Measurements {
+			key := snapshot.Originator + "/" + measurement.ID
+			series, ok := collection.series[key]
+			if !ok {
+				series = &MetricSeries{Originator: snapshot.Originator, ID: measurement.ID}
+				collection.series[key] = series
+			}
+			series.Samples = append(series.Samples, MetricSample{Time: timestamp, Value: measurement.Value})
+		}
+	}
+	collection.Reports++
+}
+
+// Series returns the series of a metric of an originator, nil is returned if the metric is not reported
+func (collection *MetricsCollection) Series(originator string, id string) *MetricSeries {
+	return collection.series[originator+"/"+id]
+}
+
+// AllSeries returns all series ordered by originator and metric ID
+func (collection *MetricsCollection) AllSeries() []*MetricSeries {
+	var result []*MetricSeries
+	for _, series := range collection.series {
+		result = append(result, series)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Originator != result[j].Originator {
+			return result[i].Originator < result[j].Originator
+		}
+		return result[i].ID < result[j].ID
+	})
+	return result
+}
+
+// Stats aggregates the values of a metric of an originator. An error is returned if the metric is not reported.
+func (collection *MetricsCollection) Stats(originator string, id string) (*MetricStats, error) {
+	series := collection.Series(originator, id)
+	if series == nil || len(series.Samples) == 0 {
+		return nil, fmt.Errorf("metric %s of originator %s is not reported", id, originator)
+	}
+	return series.Stats(), nil
+}
+
+// MetricsClient starts and stops the metrics reporting of the Metrics feature and collects the reported data
+type MetricsClient struct {
+	*FeatureClient
+}
+
+// NewMetricsClient creates a client for the Metrics feature of the thing.
+// The live messages of the thing are subscribed as well, so the reported data is received.
+func NewMetricsClient(ctx context.Context, cfg *TestConfiguration, thingID string) (*MetricsClient, error) {
+	client, err := NewFeatureClient(ctx, cfg, thingID, MetricsFeatureID)
+	if err != nil {
+		return nil, err
+	}
+	if err := client.subscribeForMessages(ctx); err != nil {
+		client.Close()
+		return nil, err
+	}
+	return &MetricsClient{FeatureClient: client}, nil
+}
+
+// Start starts or changes the metrics reporting
+func (client *MetricsClient) Start(ctx context.Context, request *MetricsRequest) error {
+	_, err := client.ExecuteOperation(ctx, metricsMessageRequest, request)
+	return err
+}
+
+// Stop stops the metrics reporting
+func (client *MetricsClient) Stop(ctx context.Context) error {
+	return client.Start(ctx, NewMetricsRequest(0))
+}
+
+// Collect adds the reported data to the collection until the count of data messages is received
+// or the context is done, as collecting depends on the reporting period rather than the WebSocket event timeout
+func (client *MetricsClient) Collect(ctx context.Context, count int, collection *MetricsCollection) error {
+	received := 0
+	return WatchWSMessages(ctx, client.ws, func(envelope *protocol.Envelope) (bool, error) {
+		value, ok := client.outboxMessageValue(envelope, metricsMessageData)
+		if !ok {
+			return false, nil
+		}
+		data := &MetricsData{}
+		if err := Convert(value, data); err != nil {
+			return false, fmt.Errorf("invalid metrics data: %v", err)
+		}
+		collection.Add(data)
+		received++
+		return received >= count, nil
+	})
+}
+
+// Measure starts the metrics reporting, collects the count of reports and stops the reporting
+func (client *MetricsClient) Measure(ctx context.Context, request *MetricsRequest,
+	count int) (*MetricsCollection, error) {
+	if err := client.Start(ctx, request); err != nil {
+		return nil, err
+	}
+	collection := NewMetricsCollection()
+	err := client.Collect(ctx, count, collection)
+	if stopErr := client.Stop(ctx); err == nil && stopErr != nil {
+		err = fmt.Errorf("unable to stop the metrics reporting: %v", stopErr)
+	}
+	return collection, err
+}
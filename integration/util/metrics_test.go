@@ -0,0 +1,182 @@
+// Copyright (c) 2026 Contributors to the Eclipse Foundation
+//
+// See the NOTICE file(s) distributed with this work for additional
+// information regarding copyright ownership.
+//
+// This program and the accompanying materials are made available under the
+// terms of the Eclipse Public License 2.0 which is available at
+// https://www.eclipse.org/legal/epl-2.0, or the Apache License, Version 2.0
+// which is available at https://www.apache.org/licenses/LICENSE-2.0.
+//
+// SPDX-License-Identifier: EPL-2.0 OR Apache-2.0
+
+package util
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetricSeriesStats(t *testing.T) {
+	tests := []struct {
+		name     string
+		values   []float64
+		expected *MetricStats
+	}{
+		{
+			name: "empty",
+		},
+		{
+			name:     "single value",
+			values:   []float64{42},
+			expected: &MetricStats{Count: 1, Min: 42, Max: 42, Avg: 42, Last: 42},
+		},
+		{
+			name:     "increasing",
+			values:   []float64{1, 2, 3, 6},
+			expected: &MetricStats{Count: 4, Min: 1, Max: 6, Avg: 3, Last: 6},
+		},
+		{
+			name:     "last is neither min nor max",
+			values:   []float64{5, -1, 9, 3},
+			expected: &MetricStats{Count: 4, Min: -1, Max: 9, Avg: 4, Last: 3},
+		},
+		{
+			name:     "fractions",
+			values:   []float64{0.5, 0.25, 0.75},
+			expected: &MetricStats{Count: 3, Min: 0.25, Max: 0.75, Avg: 0.5, Last: 0.75},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			series := &MetricSeries{Originator: "SYSTEM", ID: "cpu.utilization"}
+			for _, value := range test.values {
+				series.Samples = append(series.Samples, MetricSample{Time: time.Now(), Value: value})
+			}
+			require.Equal(t, test.expected, series.Stats())
+		})
+	}
+}
+
+func TestMetricsCollectionAdd(t *testing.T) {
+	collection := NewMetricsCollection()
+	reported := time.UnixMilli(1700000000000)
+
+	collection.Add(&MetricsData{
+		Timestamp: reported.UnixMilli(),
+		Snapshot: []*MetricsSnapshot{
+			{Originator: "SYSTEM", Measurements: []*MetricsMeasurement{
+				{ID: "cpu.utilization", Value: 10},
+				{ID: "memory.utilization", Value: 50},
+			}},
+			{Originator: "influxdb", Measurements: []*MetricsMeasurement{{ID: "cpu.utilization", Value: 2}}},
+		},
+	})
+	// The receiving time is used without a timestamp
+	before := time.Now()
+	collection.Add(&MetricsData{
+		Snapshot: []*MetricsSnapshot{
+			{Originator: "SYSTEM", Measurements: []*MetricsMeasurement{{ID: "cpu.utilization", Value: 30}}},
+		},
+	})
+	after := time.Now()
+	collection.Add(&MetricsData{})
+
+	require.Equal(t, 3, collection.Reports)
+
+	cpu := collection.Series("SYSTEM", "cpu.utilization")
+	require.NotNil(t, cpu)
+	require.Len(t, cpu.Samples, 2)
+	require.True(t, reported.Equal(cpu.Samples[0].Time), "unexpected sample time %v", cpu.Samples[0].Time)
+	require.Equal(t, 10.0, cpu.Samples[0].Value)
+	require.False(t, cpu.Samples[1].Time.Before(before), "sample time %v before %v", cpu.Samples[1].Time, before)
+	require.False(t, cpu.Samples[1].Time.After(after), "sample time %v after %v", cpu.Samples[1].Time, after)
+	require.Equal(t, 30.0, cpu.Samples[1].Value)
+	require.Nil(t, collection.Series("SYSTEM", "io.reads"))
+
+	var names []string
+	for _, series := range collection.AllSeries() {
+		names = append(names, series.Originator+"/"+series.ID)
+	}
+	require.Equal(t, []string{"SYSTEM/cpu.utilization", "SYSTEM/memory.utilization", "influxdb/cpu.utilization"}, names)
+}
+
+func TestMetricsCollectionStats(t *testing.T) {
+	collection := NewMetricsCollection()
+	for _, value := range []float64{20, 10, 30} {
+		collection.Add(&MetricsData{Snapshot: []*MetricsSnapshot{
+			{Originator: "SYSTEM", Measurements: []*MetricsMeasurement{{ID: "cpu.utilization", Value: value}}},
+		}})
+	}
+
+	tests := []struct {
+		name       string
+		originator string
+		id         string
+		expected   *MetricStats
+		err        string
+	}{
+		{
+			name:       "reported",
+			originator: "SYSTEM",
+			id:         "cpu.utilization",
+			expected:   &MetricStats{Count: 3, Min: 10, Max: 30, Avg: 20, Last: 30},
+		},
+		{
+			name:       "unknown metric",
+			originator: "SYSTEM",
+			id:         "io.reads",
+			err:        "metric io.reads of originator SYSTEM is not reported",
+		},
+		{
+			name:       "unknown originator",
+			originator: "influxdb",
+			id:         "cpu.utilization",
+			err:        "metric cpu.utilization of originator influxdb is not reported",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			stats, err := collection.Stats(test.originator, test.id)
+			if test.err != "" {
+				require.EqualError(t, err, test.err)
+				require.Nil(t, stats)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, test.expected, stats)
+		})
+	}
+
+	// A series without samples has no stats
+	collection.series["SYSTEM/empty"] = &MetricSeries{Originator: "SYSTEM", ID: "empty"}
+	_, err := collection.Stats("SYSTEM", "empty")
+	require.EqualError(t, err, "metric empty of originator SYSTEM is not reported")
+}
+
+func TestMetricsCollectionAllSeriesOrder(t *testing.T) {
+	collection := NewMetricsCollection()
+	require.Empty(t, collection.AllSeries())
+
+	for _, originator := range []string{"telegraf", "SYSTEM", "influxdb"} {
+		collection.Add(&MetricsData{Snapshot: []*MetricsSnapshot{{Originator: originator, Measurements: []*MetricsMeasurement{
+			{ID: "memory.utilization", Value: 1},
+			{ID: "cpu.utilization", Value: 1},
+		}}}})
+	}
+
+	var names []string
+	for _, series := range collection.AllSeries() {
+		names = append(names, series.Originator+"/"+series.ID)
+	}
+	require.Equal(t, []string{
+		"SYSTEM/cpu.utilization",
+		"SYSTEM/memory.utilization",
+		"influxdb/cpu.utilization",
+		"influxdb/memory.utilization",
+		"telegraf/cpu.utilization",
+		"telegraf/memory.utilization",
+	}, names)
+}
@@ -0,0 +1,291 @@
+// Copyright (c) 2026 Contributors to the Eclipse Foundation
+//
+// See the NOTICE file(s) distributed with this work for additional
+// information regarding copyright ownership.
+//
+// This program and the accompanying materials are made available under the
+// terms of the Eclipse Public License 2.0 which is available at
+// https://www.eclipse.org/legal/epl-2.0, or the Apache License, Version 2.0
+// which is available at https://www.apache.org/licenses/LICENSE-2.0.
+//
+// SPDX-License-Identifier: EPL-2.0 OR Apache-2.0
+
+package util
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/eclipse/paho.golang/packets"
+	"github.com/eclipse/paho.golang/paho"
+	"github.com/google/uuid"
+)
+
+const (
+	// MQTTVersion311 selects the MQTT 3.1.1 protocol for the local broker connection.
+	MQTTVersion311 = "3.1.1"
+
+	// MQTTVersion5 selects the MQTT 5 protocol for the local broker connection.
+	MQTTVersion5 = "5"
+
+	// persistentSessionExpiry keeps the session as long as possible when a persistent session is requested
+	persistentSessionExpiry = 0xFFFFFFFF
+
+	sharedMQTT5UnsubscribeTimeout = 3 * time.Second
+)
+
+// MQTT5MessageProperties holds the MQTT 5 specific properties of a published message
+type MQTT5MessageProperties struct {
+	ResponseTopic   string
+	CorrelationData []byte
+	UserProperties  map[string]string
+	MessageExpiry   time.Duration
+}
+
+// NewMQTT5Client creates a new MQTT 5 client and connects it to the broker from the test configuration.
+// If no client ID is configured, a random one is generated.
+func NewMQTT5Client(ctx context.Context, cfg *TestConfiguration) (*paho.Client, error) {
+	keepAlive, err := cfg.mqttKeepAlive()
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, MillisToDuration(cfg.MQTTConnectMS))
+	defer cancel()
+
+	conn, err := dialBroker(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	clientID := cfg.MQTTClientID
+	if clientID == "" {
+		clientID = uuid.New().String()
+	}
+
+	client := paho.NewClient(paho.ClientConfig{
+		ClientID: clientID,
+		Conn:     packets.NewThreadSafeConn(conn),
+	})
+
+	connect := &paho.Connect{
+		ClientID:     clientID,
+		KeepAlive:    keepAlive,
+		CleanStart:   cfg.MQTTCleanSession,
+		Username:     cfg.LocalBrokerUsername,
+		UsernameFlag: cfg.LocalBrokerUsername != "",
+		Password:     []byte(cfg.LocalBrokerPassword),
+		PasswordFlag: cfg.LocalBrokerPassword != "",
+	}
+	if !cfg.MQTTCleanSession {
+		connect.Properties = &paho.ConnectProperties{
+			SessionExpiryInterval: paho.Uint32(persistentSessionExpiry),
+		}
+	}
+
+	if _, err := client.Connect(ctx, connect); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return client, nil
+}
+
+func dialBroker(ctx context.Context, cfg *TestConfiguration) (net.Conn, error) {
+	brokerURL, err := url.Parse(cfg.LocalBroker)
+	if err != nil {
+		return nil, err
+	}
+
+	switch brokerURL.Scheme {
+	case "tcp", "mqtt":
+		return (&net.Dialer{}).DialContext(ctx, "tcp", brokerURL.Host)
+	case "ssl", "tls", "mqtts", "tcps":
+		tlsConfig, err := NewTLSConfig(cfg.LocalBrokerCACert, cfg.LocalBrokerCert, cfg.LocalBrokerKey)
+		if err != nil {
+			return nil, err
+		}
+		dialer := &tls.Dialer{Config: tlsConfig}
+		return dialer.DialContext(ctx, "tcp", brokerURL.Host)
+	default:
+		return nil, fmt.Errorf("unsupported broker scheme %s", brokerURL.Scheme)
+	}
+}
+
+// DisconnectMQTT5Client disconnects the MQTT 5 client from the broker
+func DisconnectMQTT5Client(client *paho.Client) error {
+	return client.Disconnect(&paho.Disconnect{ReasonCode: 0})
+}
+
+// SendMQTT5Message sends a message to a topic using specified MQTT 5 client. The message is serialized to JSON format.
+// The properties are optional and can be nil.
+func SendMQTT5Message(ctx context.Context, cfg *TestConfiguration, client *paho.Client, topic string,
+	message interface{}, props *MQTT5MessageProperties) error {
+	payload, err := json.Marshal(message)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(ctx, MillisToDuration(cfg.MQTTAcknowledgeTimeoutMS))
+	defer cancel()
+
+	_, err = client.Publish(ctx, &paho.Publish{
+		QoS:        1,
+		Topic:      topic,
+		Payload:    payload,
+		Properties: toPublishProperties(props),
+	})
+	return err
+}
+
+func toPublishProperties(props *MQTT5MessageProperties) *paho.PublishProperties {
+	if props == nil {
+		return nil
+	}
+	publishProps := &paho.PublishProperties{
+		ContentType:     "application/json",
+		ResponseTopic:   props.ResponseTopic,
+		CorrelationData: props.CorrelationData,
+	}
+	for key, value := range props.UserProperties {
+		publishProps.User.Add(key, value)
+	}
+	if props.MessageExpiry > 0 {
+		publishProps.MessageExpiry = paho.Uint32(uint32(props.MessageExpiry / time.Second))
+	}
+	return publishProps
+}
+
+// GetThingConfigurationMQTT5 retrieves information about the configured thing using MQTT 5 request/response.
+// The request carries a response topic and correlation data. Responses with different correlation data are ignored.
+func GetThingConfigurationMQTT5(ctx context.Context, cfg *TestConfiguration,
+	client *paho.Client) (*ThingConfiguration, error) {
+	correlationData := []byte(uuid.New().String())
+	ch := make(chan []byte, 1)
+
+	removeHandler := client.AddOnPublishReceived(func(received paho.PublishReceived) (bool, error) {
+		msg := received.Packet
+		if msg.Topic != topicThingCfgResponse {
+			return false, nil
+		}
+		if msg.Properties != nil && len(msg.Properties.CorrelationData) > 0 &&
+			!bytes.Equal(msg.Properties.CorrelationData, correlationData) {
+			return false, nil
+		}
+		select {
+		case ch <- msg.Payload:
+		default:
+		}
+		return true, nil
+	})
+	defer removeHandler()
+
+	ctx, cancel := context.WithTimeout(ctx, MillisToDuration(cfg.MQTTAcknowledgeTimeoutMS)+thingCfgResponseTimeout)
+	defer cancel()
+
+	unsubscribe, err := subscribeSharedMQTT5(ctx, client, topicThingCfgResponse, 1)
+	if err != nil {
+		return nil, fmt.Errorf("unable to subscribe to thing configuration response: %v", err)
+	}
+	defer unsubscribe()
+
+	if _, err := client.Publish(ctx, &paho.Publish{
+		QoS:   1,
+		Topic: topicThingCfgRequest,
+		Properties: &paho.PublishProperties{
+			ResponseTopic:   topicThingCfgResponse,
+			CorrelationData: correlationData,
+		},
+	}); err != nil {
+		return nil, fmt.Errorf("unable to publish thing configuration request: %v", err)
+	}
+
+	select {
+	case payload := <-ch:
+		thingCfg := &ThingConfiguration{}
+		if err := json.Unmarshal(payload, thingCfg); err != nil {
+			return nil, err
+		}
+		return thingCfg, nil
+	case <-ctx.Done():
+		return nil, fmt.Errorf("thing config not received: %v", ctx.Err())
+	}
+}
+
+// sharedMQTT5Subscriptions counts the users of the topic filters subscribed by the helpers of this package.
+// A paho client calls all its publish handlers, but unsubscribing a filter would stop the messages
+// of a concurrent helper on the same filter.
+var sharedMQTT5Subscriptions = struct {
+	sync.Mutex
+	entries map[sharedMQTT5SubscriptionKey]*sharedMQTT5Subscription
+}{entries: map[sharedMQTT5SubscriptionKey]*sharedMQTT5Subscription{}}
+
+type sharedMQTT5SubscriptionKey struct {
+	client *paho.Client
+	topic  string
+}
+
+type sharedMQTT5Subscription struct {
+	users int
+	// subscribed is closed when the subscribe request of the first user completes with err
+	subscribed chan struct{}
+	err        error
+}
+
+// subscribeSharedMQTT5 subscribes the topic filter of the client. The filter is only subscribed on the broker
+// for the first user and unsubscribed when the last user calls the returned function.
+// The messages are received by the publish handlers of the client.
+func subscribeSharedMQTT5(ctx context.Context, client *paho.Client, topic string, qos byte) (func(), error) {
+	key := sharedMQTT5SubscriptionKey{client: client, topic: topic}
+
+	sharedMQTT5Subscriptions.Lock()
+	entry, ok := sharedMQTT5Subscriptions.entries[key]
+	if !ok {
+		entry = &sharedMQTT5Subscription{subscribed: make(chan struct{})}
+		sharedMQTT5Subscriptions.entries[key] = entry
+	}
+	entry.users++
+	sharedMQTT5Subscriptions.Unlock()
+
+	unsubscribe := func() {
+		sharedMQTT5Subscriptions.Lock()
+		defer sharedMQTT5Subscriptions.Unlock()
+		entry.users--
+		if entry.users == 0 && sharedMQTT5Subscriptions.entries[key] == entry {
+			delete(sharedMQTT5Subscriptions.entries, key)
+			if entry.err == nil {
+				// Unsubscribe while locked, so that a new user subscribes after the filter is removed
+				ctx, cancel := context.WithTimeout(context.Background(), sharedMQTT5UnsubscribeTimeout)
+				defer cancel()
+				client.Unsubscribe(ctx, &paho.Unsubscribe{Topics: []string{topic}})
+			}
+		}
+	}
+
+	if !ok {
+		_, err := client.Subscribe(ctx, &paho.Subscribe{
+			Subscriptions: []paho.SubscribeOptions{{Topic: topic, QoS: qos}},
+		})
+		sharedMQTT5Subscriptions.Lock()
+		entry.err = err
+		sharedMQTT5Subscriptions.Unlock()
+		close(entry.subscribed)
+	}
+
+	select {
+	case <-entry.subscribed:
+		if entry.err != nil {
+			unsubscribe()
+			return nil, entry.err
+		}
+		return unsubscribe, nil
+	case <-ctx.Done():
+		unsubscribe()
+		return nil, ctx.Err()
+	}
+}
@@ -13,9 +13,10 @@
 package util
 
 import (
+	"context"
 	"encoding/json"
-	"errors"
 	"fmt"
+	"sync"
 	"time"
 
 	"github.com/google/uuid"
@@ -28,40 +29,78 @@ const (
 
 	topicThingCfgResponse = "edge/thing/response"
 
-	keepAliveTimeout = 20 * time.Second
+	thingCfgResponseTimeout = 5 * time.Second
+
+	thingCfgRequestInterval = time.Second
 )
 
-// NewMQTTClient creates a new MQTT client and connects it to the broker from the test configuration
-func NewMQTTClient(cfg *TestConfiguration) (MQTT.Client, error) {
+// NewMQTTClient creates a new MQTT client and connects it to the broker from the test configuration.
+// If no client ID is configured, a random one is generated.
+// If the context carries a traffic recorder, the published and received messages of the client are recorded.
+func NewMQTTClient(ctx context.Context, cfg *TestConfiguration) (MQTT.Client, error) {
+	clientID := cfg.MQTTClientID
+	if clientID == "" {
+		clientID = uuid.New().String()
+	}
+
 	opts := MQTT.NewClientOptions().
 		AddBroker(cfg.LocalBroker).
-		SetClientID(uuid.New().String()).
+		SetClientID(clientID).
+		SetUsername(cfg.LocalBrokerUsername).
+		SetPassword(cfg.LocalBrokerPassword).
 		SetConnectTimeout(MillisToDuration(cfg.MQTTConnectMS)).
-		SetKeepAlive(keepAliveTimeout).
-		SetCleanSession(true).
+		SetKeepAlive(MillisToDuration(cfg.MQTTKeepAliveMS)).
+		SetCleanSession(cfg.MQTTCleanSession).
 		SetAutoReconnect(true)
 
+	// A client certificate or key on its own is rejected by NewTLSConfig instead of being ignored
+	if cfg.LocalBrokerCACert != "" || cfg.LocalBrokerCert != "" || cfg.LocalBrokerKey != "" {
+		tlsConfig, err := NewTLSConfig(cfg.LocalBrokerCACert, cfg.LocalBrokerCert, cfg.LocalBrokerKey)
+		if err != nil {
+			return nil, err
+		}
+		opts.SetTLSConfig(tlsConfig)
+	}
+
+	recorder := TrafficRecorderFromContext(ctx)
+	if recorder != nil {
+		opts.SetDefaultPublishHandler(func(client MQTT.Client, message MQTT.Message) {
+			recorder.recordMQTT(TrafficIn, message.Topic(), message.Payload())
+		})
+	}
+
 	mqttClient := MQTT.NewClient(opts)
 
-	if token := mqttClient.Connect(); token.Wait() && token.Error() != nil {
-		return nil, token.Error()
+	if err := waitForToken(ctx, mqttClient.Connect()); err != nil {
+		mqttClient.Disconnect(0)
+		return nil, err
 	}
 
+	if recorder != nil {
+		return &recordingMQTTClient{Client: mqttClient, recorder: recorder}, nil
+	}
 	return mqttClient, nil
 }
 
 // SendMQTTMessage sends a message to a topic using specified client. The message is serialized to JSON format.
-func SendMQTTMessage(cfg *TestConfiguration, client MQTT.Client, topic string, message interface{}) error {
+func SendMQTTMessage(ctx context.Context, cfg *TestConfiguration, client MQTT.Client, topic string,
+	message interface{}) error {
+	return publishJSON(ctx, cfg, client, topic, 1, message)
+}
+
+func publishJSON(ctx context.Context, cfg *TestConfiguration, client MQTT.Client, topic string, qos byte,
+	message interface{}) error {
 	payload, err := json.Marshal(message)
 	if err != nil {
 		return err
 	}
-	token := client.Publish(topic, 1, false, payload)
-	timeout := MillisToDuration(cfg.MQTTAcknowledgeTimeoutMS)
-	if !token.WaitTimeout(timeout) {
-		return errors.New("timeout while sending MQTT message")
+	ctx, cancel := context.WithTimeout(ctx, MillisToDuration(cfg.MQTTAcknowledgeTimeoutMS))
+	defer cancel()
+
+	if err := waitForToken(ctx, client.Publish(topic, qos, false, payload)); err != nil {
+		return fmt.Errorf("unable to send MQTT message: %v", err)
 	}
-	return token.Error()
+	return nil
 }
 
 // ThingConfiguration represents information about the configured thing
@@ -72,43 +111,219 @@ type ThingConfiguration struct {
 }
 
 // GetThingConfiguration retrieves information about the configured thing
-func GetThingConfiguration(cfg *TestConfiguration, mqttClient MQTT.Client) (*ThingConfiguration, error) {
-	type result struct {
-		cfg *ThingConfiguration
-		err error
+func GetThingConfiguration(ctx context.Context, cfg *TestConfiguration,
+	mqttClient MQTT.Client) (*ThingConfiguration, error) {
+	timeout := MillisToDuration(cfg.MQTTAcknowledgeTimeoutMS) + thingCfgResponseTimeout
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	msg, err := MQTTRequest(ctx, mqttClient, topicThingCfgRequest, topicThingCfgResponse, "", nil)
+	if err != nil {
+		return nil, fmt.Errorf("thing config not received: %v", err)
 	}
 
-	ch := make(chan result)
+	thingCfg := &ThingConfiguration{}
+	if err := json.Unmarshal(msg.Payload(), thingCfg); err != nil {
+		return nil, err
+	}
+	return thingCfg, nil
+}
+
+// WatchThingConfiguration subscribes for thing configuration updates and delivers them on the returned channel.
+// A thing configuration request is sent initially to retrieve the current configuration.
+// Only the latest update is kept if the channel is not read fast enough.
+// The subscription is removed and the channel is closed when the context is done.
+func WatchThingConfiguration(ctx context.Context, cfg *TestConfiguration,
+	mqttClient MQTT.Client) (<-chan *ThingConfiguration, error) {
+	var (
+		mutex  sync.Mutex
+		closed bool
+	)
+	ch := make(chan *ThingConfiguration, 1)
+
+	subscribeCtx, cancel := context.WithTimeout(ctx, MillisToDuration(cfg.MQTTAcknowledgeTimeoutMS))
+	defer cancel()
+	unsubscribe, err := subscribeShared(subscribeCtx, mqttClient, topicThingCfgResponse, 1,
+		func(client MQTT.Client, message MQTT.Message) {
+			thingCfg := &ThingConfiguration{}
+			if err := json.Unmarshal(message.Payload(), thingCfg); err != nil {
+				return
+			}
+
+			mutex.Lock()
+			defer mutex.Unlock()
+			if closed {
+				return
+			}
+			// Replace a pending update, only the latest configuration is relevant
+			select {
+			case <-ch:
+			default:
+			}
+			ch <- thingCfg
+		})
+	if err != nil {
+		return nil, fmt.Errorf("unable to subscribe to thing configuration response: %v", err)
+	}
 
-	token := mqttClient.Subscribe(topicThingCfgResponse, 1, func(client MQTT.Client, message MQTT.Message) {
-		var cfg ThingConfiguration
-		if err := json.Unmarshal(message.Payload(), &cfg); err != nil {
-			ch <- result{nil, err}
+	if err := waitForToken(subscribeCtx, mqttClient.Publish(topicThingCfgRequest, 1, false, "")); err != nil {
+		unsubscribe()
+		return nil, fmt.Errorf("unable to publish thing configuration request: %v", err)
+	}
+
+	go func() {
+		<-ctx.Done()
+		unsubscribe()
+
+		mutex.Lock()
+		defer mutex.Unlock()
+		closed = true
+		close(ch)
+	}()
+	return ch, nil
+}
+
+// WaitForThingConfiguration waits until the thing configuration reports the expected device ID.
+// Thing configuration requests are sent periodically until a matching configuration is received
+// or the context is done.
+func WaitForThingConfiguration(ctx context.Context, cfg *TestConfiguration, mqttClient MQTT.Client,
+	deviceID string) (*ThingConfiguration, error) {
+	watchCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	updates, err := WatchThingConfiguration(watchCtx, cfg, mqttClient)
+	if err != nil {
+		return nil, err
+	}
+
+	ticker := time.NewTicker(thingCfgRequestInterval)
+	defer ticker.Stop()
+
+	var last *ThingConfiguration
+	for {
+		select {
+		case thingCfg, ok := <-updates:
+			if !ok {
+				return nil, fmt.Errorf("thing configuration with device ID %s not received, last received: %+v",
+					deviceID, last)
+			}
+			if thingCfg.DeviceID == deviceID {
+				return thingCfg, nil
+			}
+			last = thingCfg
+		case <-ticker.C:
+			mqttClient.Publish(topicThingCfgRequest, 1, false, "")
+		}
+	}
+}
+
+// MQTTRequest subscribes to the response topic, publishes the payload to the request topic and waits for
+// the first response accepted by the match function. A nil match function accepts any response.
+// The response topic may contain wildcards. The request is canceled when the context is done.
+// Other helpers of this package may use the same response topic on the client concurrently,
+// but it must not be subscribed with the client directly, e.g. by a Ditto client, as that replaces the handler.
+func MQTTRequest(ctx context.Context, client MQTT.Client, reqTopic, respTopic string, payload interface{},
+	match func(MQTT.Message) bool) (MQTT.Message, error) {
+	ch := make(chan MQTT.Message, 1)
+
+	unsubscribe, err := subscribeShared(ctx, client, respTopic, 1, func(client MQTT.Client, message MQTT.Message) {
+		if match != nil && !match(message) {
+			return
+		}
+		// Never block the client's callback, only the first matching response is of interest
+		select {
+		case ch <- message:
+		default:
 		}
-		ch <- result{&cfg, nil}
 	})
-	if !token.WaitTimeout(MillisToDuration(cfg.MQTTAcknowledgeTimeoutMS)) {
-		return nil, errors.New("timeout subscribing to thing configuration response")
+	if err != nil {
+		return nil, fmt.Errorf("unable to subscribe to %s: %v", respTopic, err)
 	}
-	if token.Error() != nil {
-		return nil, token.Error()
+	defer unsubscribe()
+
+	token := client.Publish(reqTopic, 1, false, payload)
+	if err := waitForToken(ctx, token); err != nil {
+		return nil, fmt.Errorf("unable to publish to %s: %v", reqTopic, err)
 	}
 
-	defer mqttClient.Unsubscribe(topicThingCfgResponse)
+	select {
+	case msg := <-ch:
+		return msg, nil
+	case <-ctx.Done():
+		return nil, fmt.Errorf("no response on %s: %v", respTopic, ctx.Err())
+	}
+}
 
-	token = mqttClient.Publish(topicThingCfgRequest, 1, false, "")
-	if !token.WaitTimeout(MillisToDuration(cfg.MQTTAcknowledgeTimeoutMS)) {
-		return nil, errors.New("timeout publishing thing configuration request")
+func waitForToken(ctx context.Context, token MQTT.Token) error {
+	select {
+	case <-token.Done():
+		return token.Error()
+	case <-ctx.Done():
+		return ctx.Err()
 	}
-	if token.Error() != nil {
-		return nil, token.Error()
+}
+
+// sharedSubscriptions multiplexes the subscriptions made by the helpers of this package. A paho client keeps
+// a single handler per topic filter, so subscribing again would replace the handler of a concurrent helper
+// and unsubscribing would remove it.
+var sharedSubscriptions = struct {
+	sync.Mutex
+	lastID  int
+	entries map[sharedSubscriptionKey]*sharedSubscription
+}{entries: map[sharedSubscriptionKey]*sharedSubscription{}}
+
+type sharedSubscriptionKey struct {
+	client MQTT.Client
+	topic  string
+}
+
+type sharedSubscription struct {
+	token    MQTT.Token
+	handlers map[int]MQTT.MessageHandler
+}
+
+// subscribeShared adds a handler for the topic filter of the client. The filter is only subscribed on the broker
+// for the first handler and unsubscribed when the last handler is removed with the returned function.
+func subscribeShared(ctx context.Context, client MQTT.Client, topic string, qos byte,
+	handler MQTT.MessageHandler) (func(), error) {
+	key := sharedSubscriptionKey{client: client, topic: topic}
+
+	sharedSubscriptions.Lock()
+	entry, ok := sharedSubscriptions.entries[key]
+	if !ok {
+		entry = &sharedSubscription{handlers: map[int]MQTT.MessageHandler{}}
+		sharedSubscriptions.entries[key] = entry
+		entry.token = client.Subscribe(topic, qos, func(client MQTT.Client, message MQTT.Message) {
+			sharedSubscriptions.Lock()
+			handlers := make([]MQTT.MessageHandler, 0, len(entry.handlers))
+			for _, handler := range entry.handlers {
+				handlers = append(handlers, handler)
+			}
+			sharedSubscriptions.Unlock()
+
+			for _, handler := range handlers {
+				handler(client, message)
+			}
+		})
 	}
+	sharedSubscriptions.lastID++
+	id := sharedSubscriptions.lastID
+	entry.handlers[id] = handler
+	token := entry.token
+	sharedSubscriptions.Unlock()
 
-	timeout := 5 * time.Second
-	select {
-	case result := <-ch:
-		return result.cfg, result.err
-	case <-time.After(timeout):
-		return nil, fmt.Errorf("thing config not received in %v", timeout)
+	unsubscribe := func() {
+		sharedSubscriptions.Lock()
+		defer sharedSubscriptions.Unlock()
+		delete(entry.handlers, id)
+		if len(entry.handlers) == 0 && sharedSubscriptions.entries[key] == entry {
+			delete(sharedSubscriptions.entries, key)
+			client.Unsubscribe(topic)
+		}
+	}
+	if err := waitForToken(ctx, token); err != nil {
+		unsubscribe()
+		return nil, err
 	}
+	return unsubscribe, nil
 }
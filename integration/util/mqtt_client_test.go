@@ -0,0 +1,181 @@
+// Copyright (c) 2026 Contributors to the Eclipse Foundation
+//
+// See the NOTICE file(s) distributed with this work for additional
+// information regarding copyright ownership.
+//
+// This program and the accompanying materials are made available under the
+// terms of the Eclipse Public License 2.0 which is available at
+// https://www.eclipse.org/legal/epl-2.0, or the Apache License, Version 2.0
+// which is available at https://www.apache.org/licenses/LICENSE-2.0.
+//
+// SPDX-License-Identifier: EPL-2.0 OR Apache-2.0
+
+package util
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/eclipse/paho.golang/paho"
+	MQTT "github.com/eclipse/paho.mqtt.golang"
+	"github.com/stretchr/testify/require"
+)
+
+// startTestBroker starts a local broker, which is closed on test cleanup,
+// and returns a test configuration connecting to it
+func startTestBroker(t *testing.T) (*LocalBroker, *TestConfiguration) {
+	broker, err := StartLocalBroker()
+	require.NoError(t, err)
+	t.Cleanup(func() { broker.Close() })
+
+	return broker, &TestConfiguration{
+		LocalBroker:              broker.URL(),
+		MQTTVersion:              MQTTVersion311,
+		MQTTCleanSession:         true,
+		MQTTKeepAliveMS:          20000,
+		MQTTQuiesceMS:            100,
+		MQTTAcknowledgeTimeoutMS: 3000,
+		MQTTConnectMS:            3000,
+		WSEventTimeoutMS:         3000,
+	}
+}
+
+func TestMQTTRequestKeepsConcurrentSubscription(t *testing.T) {
+	broker, cfg := startTestBroker(t)
+	responder, err := broker.StartEdgeThingResponder(&ThingConfiguration{DeviceID: "test:device", TenantID: "test"})
+	require.NoError(t, err)
+	defer responder.Stop()
+
+	ctx, cancel := NewTestContext(t)
+	defer cancel()
+
+	client, err := NewMQTTClient(ctx, cfg)
+	require.NoError(t, err)
+	defer client.Disconnect(uint(cfg.MQTTQuiesceMS))
+
+	watchCtx, stopWatch := context.WithCancel(ctx)
+	defer stopWatch()
+	updates, err := WatchThingConfiguration(watchCtx, cfg, client)
+	require.NoError(t, err)
+
+	thingCfg, err := GetThingConfiguration(ctx, cfg, client)
+	require.NoError(t, err)
+	require.Equal(t, "test:device", thingCfg.DeviceID)
+
+	// The watch subscription must survive the request, which used the same topic
+	require.NoError(t, responder.SetThingConfiguration(&ThingConfiguration{DeviceID: "test:changed", TenantID: "test"}))
+	timeout := time.After(3 * time.Second)
+	for {
+		select {
+		case update := <-updates:
+			if update.DeviceID == "test:changed" {
+				return
+			}
+		case <-timeout:
+			require.Fail(t, "thing configuration update not received after a request on the same topic")
+		}
+	}
+}
+
+func TestMQTT5RequestKeepsConcurrentSubscription(t *testing.T) {
+	broker, cfg := startTestBroker(t)
+	responder, err := broker.StartEdgeThingResponder(&ThingConfiguration{DeviceID: "test:device", TenantID: "test"})
+	require.NoError(t, err)
+	defer responder.Stop()
+
+	ctx, cancel := NewTestContext(t)
+	defer cancel()
+
+	client, err := NewMQTT5Client(ctx, cfg)
+	require.NoError(t, err)
+	defer DisconnectMQTT5Client(client)
+
+	updates := make(chan *ThingConfiguration, 1)
+	removeHandler := client.AddOnPublishReceived(func(received paho.PublishReceived) (bool, error) {
+		thingCfg := &ThingConfiguration{}
+		if received.Packet.Topic == topicThingCfgResponse && json.Unmarshal(received.Packet.Payload, thingCfg) == nil {
+			select {
+			case updates <- thingCfg:
+			default:
+			}
+		}
+		return false, nil
+	})
+	defer removeHandler()
+	unsubscribe, err := subscribeSharedMQTT5(ctx, client, topicThingCfgResponse, 1)
+	require.NoError(t, err)
+	defer unsubscribe()
+
+	thingCfg, err := GetThingConfigurationMQTT5(ctx, cfg, client)
+	require.NoError(t, err)
+	require.Equal(t, "test:device", thingCfg.DeviceID)
+
+	// The concurrent subscription must survive the request, which used the same topic
+	require.NoError(t, responder.SetThingConfiguration(&ThingConfiguration{DeviceID: "test:changed", TenantID: "test"}))
+	timeout := time.After(3 * time.Second)
+	for {
+		select {
+		case update := <-updates:
+			if update.DeviceID == "test:changed" {
+				return
+			}
+		case <-timeout:
+			require.Fail(t, "thing configuration update not received after an MQTT 5 request on the same topic")
+		}
+	}
+}
+
+// failedPublishClient fails all publishes of the wrapped client
+type failedPublishClient struct {
+	MQTT.Client
+}
+
+func (client *failedPublishClient) Publish(topic string, qos byte, retained bool, payload interface{}) MQTT.Token {
+	return &failedToken{err: errors.New("publish failed")}
+}
+
+type failedToken struct {
+	err error
+}
+
+func (token *failedToken) Wait() bool {
+	return true
+}
+
+func (token *failedToken) WaitTimeout(time.Duration) bool {
+	return true
+}
+
+func (token *failedToken) Done() <-chan struct{} {
+	done := make(chan struct{})
+	close(done)
+	return done
+}
+
+func (token *failedToken) Error() error {
+	return token.err
+}
+
+func TestWatchThingConfigurationFailedRequest(t *testing.T) {
+	_, cfg := startTestBroker(t)
+	ctx, cancel := NewTestContext(t)
+	defer cancel()
+
+	connected, err := NewMQTTClient(ctx, cfg)
+	require.NoError(t, err)
+	defer connected.Disconnect(uint(cfg.MQTTQuiesceMS))
+	client := &failedPublishClient{Client: connected}
+
+	updates, err := WatchThingConfiguration(ctx, cfg, client)
+	require.EqualError(t, err, "unable to publish thing configuration request: publish failed")
+	require.Nil(t, updates)
+
+	// The response subscription is removed
+	sharedSubscriptions.Lock()
+	defer sharedSubscriptions.Unlock()
+	_, ok := sharedSubscriptions.entries[sharedSubscriptionKey{client: client, topic: topicThingCfgResponse}]
+	require.False(t, ok, "thing configuration response subscription not removed")
+}
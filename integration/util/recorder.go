@@ -0,0 +1,356 @@
+// Copyright (c) 2026 Contributors to the Eclipse Foundation
+//
+// See the NOTICE file(s) distributed with this work for additional
+// information regarding copyright ownership.
+//
+// This program and the accompanying materials are made available under the
+// terms of the Eclipse Public License 2.0 which is available at
+// https://www.eclipse.org/legal/epl-2.0, or the Apache License, Version 2.0
+// which is available at https://www.apache.org/licenses/LICENSE-2.0.
+//
+// SPDX-License-Identifier: EPL-2.0 OR Apache-2.0
+
+package util
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	MQTT "github.com/eclipse/paho.mqtt.golang"
+)
+
+const (
+	// TrafficMQTT marks traffic exchanged with the local MQTT broker.
+	TrafficMQTT = "mqtt"
+
+	// TrafficHTTP marks traffic exchanged with the Ditto and device registry REST APIs.
+	TrafficHTTP = "http"
+
+	// TrafficWS marks traffic exchanged with a Ditto WebSocket session.
+	TrafficWS = "ws"
+
+	// TrafficOut marks traffic sent by the test.
+	TrafficOut = "out"
+
+	// TrafficIn marks traffic received by the test.
+	TrafficIn = "in"
+
+	// TrafficFileExtension is the extension of the recorded traffic files
+	TrafficFileExtension = ".jsonl"
+
+	redacted = "***"
+)
+
+var (
+	sensitiveKeys = regexp.MustCompile(`(?i)(pass(word)?|pwd|secret|token|authorization|credential|private)`)
+
+	fileNameInvalidChars = regexp.MustCompile("[^a-zA-Z0-9_-]+")
+)
+
+// TrafficEntry is a single recorded message or request
+type TrafficEntry struct {
+	Time      time.Time `json:"time"`
+	Protocol  string    `json:"protocol"`
+	Direction string    `json:"direction"`
+
+	// Topic is only set for MQTT messages
+	Topic string `json:"topic,omitempty"`
+
+	// Method and URL are only set for HTTP requests and responses
+	Method string `json:"method,omitempty"`
+	URL    string `json:"url,omitempty"`
+	// Status is only set for HTTP responses
+	Status int `json:"status,omitempty"`
+
+	Headers map[string]string `json:"headers,omitempty"`
+	// Payload is the redacted JSON payload or a JSON string, if the payload is not JSON
+	Payload json.RawMessage `json:"payload,omitempty"`
+	Error   string          `json:"error,omitempty"`
+}
+
+// TrafficRecorder writes the traffic of the tests to a JSONL file per test in a directory.
+// A recorder returned by Start is bound to a test and records to its file, the recorder returned by
+// NewTrafficRecorder is not bound to any test and records nothing. The recorders bound to different tests
+// can be used concurrently, e.g. by parallel subtests.
+// Secrets in headers, URLs and JSON payloads are redacted.
+// All methods are safe to call on a nil recorder, in which case nothing is recorded.
+type TrafficRecorder struct {
+	dir   string
+	test  string
+	files *trafficFiles
+}
+
+// trafficFiles are the open traffic files shared by the recorders of a directory, keyed by test file name
+type trafficFiles struct {
+	mutex    sync.Mutex
+	encoders map[string]*json.Encoder
+}
+
+type trafficRecorderKey struct{}
+
+// NewTrafficRecorder creates a new traffic recorder, which writes to the given directory.
+// The directory is created if it doesn't exist.
+func NewTrafficRecorder(dir string) (*TrafficRecorder, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("unable to create traffic record directory %s: %v", dir, err)
+	}
+	return &TrafficRecorder{dir: dir, files: &trafficFiles{encoders: map[string]*json.Encoder{}}}, nil
+}
+
+// WithTrafficRecorder returns a context, which carries the traffic recorder.
+// Requests made with the context and MQTT clients created with it record their traffic.
+func WithTrafficRecorder(ctx context.Context, recorder *TrafficRecorder) context.Context {
+	if recorder == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, trafficRecorderKey{}, recorder)
+}
+
+// TrafficRecorderFromContext returns the traffic recorder of the context or nil, if there is none
+func TrafficRecorderFromContext(ctx context.Context) *TrafficRecorder {
+	recorder, _ := ctx.Value(trafficRecorderKey{}).(*TrafficRecorder)
+	return recorder
+}
+
+// Start opens a file named after the test and returns a recorder bound to it. The file is closed when the test
+// completes, the traffic recorded afterwards with the returned recorder is dropped. Starting the recording again
+// for the same test returns a recorder bound to the already open file.
+func (recorder *TrafficRecorder) Start(t *testing.T) (*TrafficRecorder, error) {
+	if recorder == nil {
+		return nil, nil
+	}
+	name := testFileName(t.Name())
+	bound := &TrafficRecorder{dir: recorder.dir, test: name, files: recorder.files}
+
+	recorder.files.mutex.Lock()
+	defer recorder.files.mutex.Unlock()
+
+	if _, ok := recorder.files.encoders[name]; ok {
+		return bound, nil
+	}
+	path := filepath.Join(recorder.dir, name+TrafficFileExtension)
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("unable to open traffic record file %s: %v", path, err)
+	}
+	recorder.files.encoders[name] = json.NewEncoder(file)
+
+	t.Cleanup(func() {
+		recorder.files.mutex.Lock()
+		defer recorder.files.mutex.Unlock()
+
+		delete(recorder.files.encoders, name)
+		file.Close()
+	})
+	return bound, nil
+}
+
+// Record writes an entry to the file of the test, which the recorder is bound to. The entry time is set, if missing.
+func (recorder *TrafficRecorder) Record(entry *TrafficEntry) {
+	if recorder == nil || recorder.test == "" {
+		return
+	}
+	if entry.Time.IsZero() {
+		entry.Time = time.Now()
+	}
+
+	recorder.files.mutex.Lock()
+	defer recorder.files.mutex.Unlock()
+
+	if encoder, ok := recorder.files.encoders[recorder.test]; ok {
+		// Recording is best effort, it must not fail the test
+		encoder.Encode(entry)
+	}
+}
+
+func (recorder *TrafficRecorder) recordMQTT(direction string, topic string, payload interface{}) {
+	if recorder == nil {
+		return
+	}
+	var data []byte
+	switch value := payload.(type) {
+	case []byte:
+		data = value
+	case string:
+		data = []byte(value)
+	case bytes.Buffer:
+		data = value.Bytes()
+	case *bytes.Buffer:
+		data = value.Bytes()
+	}
+	recorder.Record(&TrafficEntry{
+		Protocol:  TrafficMQTT,
+		Direction: direction,
+		Topic:     topic,
+		Payload:   redactPayload(data),
+	})
+}
+
+func (recorder *TrafficRecorder) recordWS(direction string, payload []byte, err error) {
+	if recorder == nil {
+		return
+	}
+	entry := &TrafficEntry{
+		Protocol:  TrafficWS,
+		Direction: direction,
+		Payload:   redactPayload(payload),
+	}
+	if err != nil {
+		entry.Error = err.Error()
+	}
+	recorder.Record(entry)
+}
+
+func (recorder *TrafficRecorder) recordHTTPRequest(req *http.Request) {
+	if recorder == nil {
+		return
+	}
+	var payload []byte
+	if req.GetBody != nil {
+		if body, err := req.GetBody(); err == nil {
+			payload, _ = io.ReadAll(body)
+			body.Close()
+		}
+	}
+	recorder.Record(&TrafficEntry{
+		Protocol:  TrafficHTTP,
+		Direction: TrafficOut,
+		Method:    req.Method,
+		URL:       redactURL(req.URL),
+		Headers:   redactHeaders(req.Header),
+		Payload:   redactPayload(payload),
+	})
+}
+
+func (recorder *TrafficRecorder) recordHTTPResponse(req *http.Request, resp *http.Response, body []byte,
+	err error) {
+	if recorder == nil {
+		return
+	}
+	entry := &TrafficEntry{
+		Protocol:  TrafficHTTP,
+		Direction: TrafficIn,
+		Method:    req.Method,
+		URL:       redactURL(req.URL),
+		Payload:   redactPayload(body),
+	}
+	if resp != nil {
+		entry.Status = resp.StatusCode
+		entry.Headers = redactHeaders(resp.Header)
+	}
+	if err != nil {
+		entry.Error = err.Error()
+	}
+	recorder.Record(entry)
+}
+
+// testFileName returns a file name without extension for the test
+func testFileName(name string) string {
+	return strings.Trim(fileNameInvalidChars.ReplaceAllString(name, "_"), "_")
+}
+
+func redactHeaders(header http.Header) map[string]string {
+	if len(header) == 0 {
+		return nil
+	}
+	result := map[string]string{}
+	for name := range header {
+		if sensitiveKeys.MatchString(name) {
+			result[name] = redacted
+		} else {
+			result[name] = header.Get(name)
+		}
+	}
+	return result
+}
+
+func redactURL(u *url.URL) string {
+	if u.User == nil {
+		return u.String()
+	}
+	redactedURL := *u
+	redactedURL.User = url.User(u.User.Username())
+	return redactedURL.String()
+}
+
+func redactPayload(payload []byte) json.RawMessage {
+	if len(payload) == 0 {
+		return nil
+	}
+	var value interface{}
+	if err := json.Unmarshal(payload, &value); err != nil {
+		text, _ := json.Marshal(string(payload))
+		return text
+	}
+	data, err := json.Marshal(redactValue(value))
+	if err != nil {
+		return nil
+	}
+	return data
+}
+
+func redactValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, item := range v {
+			if sensitiveKeys.MatchString(key) {
+				v[key] = redacted
+			} else {
+				v[key] = redactValue(item)
+			}
+		}
+	case []interface{}:
+		for i, item := range v {
+			v[i] = redactValue(item)
+		}
+	}
+	return value
+}
+
+// recordingMQTTClient records the published and received messages of an MQTT client
+type recordingMQTTClient struct {
+	MQTT.Client
+	recorder *TrafficRecorder
+}
+
+func (client *recordingMQTTClient) Publish(topic string, qos byte, retained bool,
+	payload interface{}) MQTT.Token {
+	client.recorder.recordMQTT(TrafficOut, topic, payload)
+	return client.Client.Publish(topic, qos, retained, payload)
+}
+
+func (client *recordingMQTTClient) Subscribe(topic string, qos byte, callback MQTT.MessageHandler) MQTT.Token {
+	return client.Client.Subscribe(topic, qos, client.record(callback))
+}
+
+func (client *recordingMQTTClient) SubscribeMultiple(filters map[string]byte,
+	callback MQTT.MessageHandler) MQTT.Token {
+	return client.Client.SubscribeMultiple(filters, client.record(callback))
+}
+
+func (client *recordingMQTTClient) AddRoute(topic string, callback MQTT.MessageHandler) {
+	client.Client.AddRoute(topic, client.record(callback))
+}
+
+func (client *recordingMQTTClient) record(callback MQTT.MessageHandler) MQTT.MessageHandler {
+	if callback == nil {
+		// Messages are delivered to the default handler, which records them
+		return nil
+	}
+	return func(mqttClient MQTT.Client, message MQTT.Message) {
+		client.recorder.recordMQTT(TrafficIn, message.Topic(), message.Payload())
+		callback(client, message)
+	}
+}
@@ -0,0 +1,106 @@
+// Copyright (c) 2026 Contributors to the Eclipse Foundation
+//
+// See the NOTICE file(s) distributed with this work for additional
+// information regarding copyright ownership.
+//
+// This program and the accompanying materials are made available under the
+// terms of the Eclipse Public License 2.0 which is available at
+// https://www.eclipse.org/legal/epl-2.0, or the Apache License, Version 2.0
+// which is available at https://www.apache.org/licenses/LICENSE-2.0.
+//
+// SPDX-License-Identifier: EPL-2.0 OR Apache-2.0
+
+package util
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func readTrafficFile(t *testing.T, dir string, testName string) []*TrafficEntry {
+	file, err := os.Open(filepath.Join(dir, testFileName(testName)+TrafficFileExtension))
+	require.NoError(t, err)
+	defer file.Close()
+
+	var entries []*TrafficEntry
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		entry := &TrafficEntry{}
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), entry))
+		entries = append(entries, entry)
+	}
+	require.NoError(t, scanner.Err())
+	return entries
+}
+
+func TestTrafficRecorderParallelTests(t *testing.T) {
+	dir := t.TempDir()
+	recorder, err := NewTrafficRecorder(dir)
+	require.NoError(t, err)
+
+	suiteRecorder, err := recorder.Start(t)
+	require.NoError(t, err)
+
+	topics := []string{"first", "second", "third"}
+	t.Run("group", func(t *testing.T) {
+		for _, topic := range topics {
+			topic := topic
+			t.Run(topic, func(t *testing.T) {
+				t.Parallel()
+				testRecorder, err := suiteRecorder.Start(t)
+				require.NoError(t, err)
+				for i := 0; i < 100; i++ {
+					testRecorder.Record(&TrafficEntry{Protocol: TrafficMQTT, Direction: TrafficOut, Topic: topic})
+				}
+			})
+		}
+	})
+	suiteRecorder.Record(&TrafficEntry{Protocol: TrafficMQTT, Direction: TrafficOut, Topic: "suite"})
+
+	for _, topic := range topics {
+		entries := readTrafficFile(t, dir, t.Name()+"/group/"+topic)
+		require.Len(t, entries, 100)
+		for _, entry := range entries {
+			require.Equal(t, topic, entry.Topic)
+		}
+	}
+	entries := readTrafficFile(t, dir, t.Name())
+	require.Len(t, entries, 1)
+	require.Equal(t, "suite", entries[0].Topic)
+}
+
+func TestTrafficRecorderStartAgain(t *testing.T) {
+	dir := t.TempDir()
+	recorder, err := NewTrafficRecorder(dir)
+	require.NoError(t, err)
+
+	// The recorder, which is not bound to a test, records nothing
+	recorder.Record(&TrafficEntry{Protocol: TrafficHTTP, Direction: TrafficOut})
+
+	var bound *TrafficRecorder
+	t.Run("test", func(t *testing.T) {
+		first, err := recorder.Start(t)
+		require.NoError(t, err)
+		second, err := recorder.Start(t)
+		require.NoError(t, err)
+		first.Record(&TrafficEntry{Protocol: TrafficHTTP, Direction: TrafficOut, Method: "first"})
+		second.Record(&TrafficEntry{Protocol: TrafficHTTP, Direction: TrafficOut, Method: "second"})
+		bound = second
+	})
+	// The file is closed when the test completes
+	bound.Record(&TrafficEntry{Protocol: TrafficHTTP, Direction: TrafficOut, Method: "after"})
+
+	entries := readTrafficFile(t, dir, t.Name()+"/test")
+	require.Len(t, entries, 2)
+	require.Equal(t, "first", entries[0].Method)
+	require.Equal(t, "second", entries[1].Method)
+
+	files, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+}
@@ -13,6 +13,7 @@
 package util
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -89,12 +90,12 @@ func getCredentialsBody(authID, pass string) string {
 }
 
 // RegisterDeviceResources registers all given resources. In case of error all resources registered by this function will be deleted.
-func RegisterDeviceResources(cfg *TestConfiguration,
+func RegisterDeviceResources(ctx context.Context, cfg *TestConfiguration,
 	resources []*Resource, deviceID, url, user, pass string) error {
 	for i, r := range resources {
-		if _, err := SendDeviceRegistryRequest(([]byte)(r.Body), r.Method, r.URL, r.User, r.Pass); err != nil {
+		if _, err := SendDeviceRegistryRequest(ctx, ([]byte)(r.Body), r.Method, r.URL, r.User, r.Pass); err != nil {
 			if i > 0 {
-				DeleteResources(cfg, resources[:i], deviceID, url, user, pass)
+				DeleteResources(ctx, cfg, resources[:i], deviceID, url, user, pass)
 			}
 			return err
 		}
@@ -103,9 +104,10 @@ func RegisterDeviceResources(cfg *TestConfiguration,
 }
 
 // DeleteResources deletes all given resources and all related devices.
-func DeleteResources(cfg *TestConfiguration, resources []*Resource, deviceID, url, user, pass string) error {
+func DeleteResources(ctx context.Context, cfg *TestConfiguration, resources []*Resource,
+	deviceID, url, user, pass string) error {
 	var errors []error
-	if err := deleteRelatedDevices(cfg, deviceID, url, user, pass); err != nil {
+	if err := deleteRelatedDevices(ctx, cfg, deviceID, url, user, pass); err != nil {
 		errors = append(errors, err)
 	}
 
@@ -114,7 +116,7 @@ func DeleteResources(cfg *TestConfiguration, resources []*Resource, deviceID, ur
 		r := resources[i]
 
 		if r.Delete {
-			if _, err := SendDeviceRegistryRequest(nil, http.MethodDelete, r.URL, r.User, r.Pass); err != nil {
+			if _, err := SendDeviceRegistryRequest(ctx, nil, http.MethodDelete, r.URL, r.User, r.Pass); err != nil {
 				errors = append(errors, err)
 			}
 		}
@@ -123,25 +125,25 @@ func DeleteResources(cfg *TestConfiguration, resources []*Resource, deviceID, ur
 	return CombineErrors(errors)
 }
 
-func deleteRelatedDevices(cfg *TestConfiguration, viaDeviceID, url, user, pass string) error {
-	devicesVia, err := findDeviceRegistryDevicesVia(viaDeviceID, url, user, pass)
+func deleteRelatedDevices(ctx context.Context, cfg *TestConfiguration, viaDeviceID, url, user, pass string) error {
+	devicesVia, err := findDeviceRegistryDevicesVia(ctx, viaDeviceID, url, user, pass)
 	if err != nil {
 		return err
 	}
 
 	var errors []error
 	// Digital Twin API things are created after Device Registry devices, so delete them first
-	if err = deleteDigitalTwinThings(cfg, devicesVia); err != nil {
+	if err = deleteDigitalTwinThings(ctx, cfg, devicesVia); err != nil {
 		errors = append(errors, err)
 	}
 	// Then delete Device Registry devices
-	if err = deleteRegistryDevices(devicesVia, url, user, pass); err != nil {
+	if err = deleteRegistryDevices(ctx, devicesVia, url, user, pass); err != nil {
 		errors = append(errors, err)
 	}
 	return CombineErrors(errors)
 }
 
-func findDeviceRegistryDevicesVia(viaDeviceID, url, user, pass string) ([]string, error) {
+func findDeviceRegistryDevicesVia(ctx context.Context, viaDeviceID, url, user, pass string) ([]string, error) {
 	type registryDevice struct {
 		ID  string   `json:"id"`
 		Via []string `json:"via"`
@@ -149,7 +151,7 @@ func findDeviceRegistryDevicesVia(viaDeviceID, url, user, pass string) ([]string
 	type registryDevices struct {
 		Devices []*registryDevice `json:"result"`
 	}
-	devicesJSON, err := SendDeviceRegistryRequest(nil, http.MethodGet, url, user, pass)
+	devicesJSON, err := SendDeviceRegistryRequest(ctx, nil, http.MethodGet, url, user, pass)
 	if err != nil {
 		return nil, err
 	}
@@ -171,23 +173,61 @@ func findDeviceRegistryDevicesVia(viaDeviceID, url, user, pass string) ([]string
 	return devicesVia, nil
 }
 
-func deleteDigitalTwinThings(cfg *TestConfiguration, things []string) error {
+func deleteDigitalTwinThings(ctx context.Context, cfg *TestConfiguration, things []string) error {
 	var errors []error
 	for _, thingID := range things {
 		if _, err := SendDigitalTwinRequest(
-			cfg, http.MethodDelete, GetThingURL(cfg.DigitalTwinAPIAddress, thingID), nil); err != nil {
+			ctx, cfg, http.MethodDelete, GetThingURL(cfg.DigitalTwinAPIAddress, thingID), nil); err != nil {
 			errors = append(errors, err)
 		}
 	}
 	return CombineErrors(errors)
 }
 
-func deleteRegistryDevices(devices []string, tenantURL, user, pass string) error {
+func deleteRegistryDevices(ctx context.Context, devices []string, tenantURL, user, pass string) error {
 	var errors []error
 	for _, device := range devices {
-		if _, err := SendDeviceRegistryRequest(nil, http.MethodDelete, tenantURL+device, user, pass); err != nil {
+		if _, err := SendDeviceRegistryRequest(ctx, nil, http.MethodDelete, tenantURL+device, user, pass); err != nil {
 			errors = append(errors, err)
 		}
 	}
 	return CombineErrors(errors)
 }
+
+// EnsureTenant creates the tenant with the given Hono messaging type, e.g. amqp or kafka, if it does not exist yet.
+// Existing tenants are not modified. The messaging type of the tenant is returned along with whether it is created.
+// An empty messaging type is returned for existing tenants, which use the default messaging type of Hono.
+// The tenant is only created if the registry responds that it is not found, other errors are returned.
+func EnsureTenant(ctx context.Context, registryAPI, tenantID, messagingType, user, pass string) (string, bool, error) {
+	type tenantExt struct {
+		MessagingType string `json:"messaging-type,omitempty"`
+	}
+	type tenant struct {
+		Ext *tenantExt `json:"ext,omitempty"`
+	}
+
+	tenantURL := registryAPI + "/tenants/" + tenantID
+	tenantJSON, err := SendDeviceRegistryRequest(ctx, nil, http.MethodGet, tenantURL, user, pass)
+	if err == nil {
+		existing := &tenant{}
+		if err := json.Unmarshal(tenantJSON, existing); err != nil {
+			return "", false, fmt.Errorf("invalid tenant %s: %v", tenantID, err)
+		}
+		if existing.Ext == nil {
+			return "", false, nil
+		}
+		return existing.Ext.MessagingType, false, nil
+	}
+	if !IsHTTPStatus(err, http.StatusNotFound) {
+		return "", false, fmt.Errorf("unable to get tenant %s: %v", tenantID, err)
+	}
+
+	body, err := json.Marshal(&tenant{Ext: &tenantExt{MessagingType: messagingType}})
+	if err != nil {
+		return "", false, err
+	}
+	if _, err := SendDeviceRegistryRequest(ctx, body, http.MethodPost, tenantURL, user, pass); err != nil {
+		return "", false, err
+	}
+	return messagingType, true, nil
+}
@@ -0,0 +1,395 @@
+// Copyright (c) 2026 Contributors to the Eclipse Foundation
+//
+// See the NOTICE file(s) distributed with this work for additional
+// information regarding copyright ownership.
+//
+// This program and the accompanying materials are made available under the
+// terms of the Eclipse Public License 2.0 which is available at
+// https://www.eclipse.org/legal/epl-2.0, or the Apache License, Version 2.0
+// which is available at https://www.apache.org/licenses/LICENSE-2.0.
+//
+// SPDX-License-Identifier: EPL-2.0 OR Apache-2.0
+
+package util
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+)
+
+const (
+	fakeRegistryDevicesPrefix     = "/v1/devices/"
+	fakeRegistryCredentialsPrefix = "/v1/credentials/"
+	fakeRegistryTenantsPrefix     = "/v1/tenants/"
+)
+
+// FakeRegistryDevice is a device stored by the fake device registry
+type FakeRegistryDevice struct {
+	ID          string                 `json:"id,omitempty"`
+	Via         []string               `json:"via,omitempty"`
+	Authorities []string               `json:"authorities,omitempty"`
+	Ext         map[string]interface{} `json:"ext,omitempty"`
+}
+
+// FakeDeviceRegistry is an in-memory stand-in for the Hono device registry management API,
+// which allows running tests without a real Hono. It serves the devices, credentials and tenants endpoints.
+// Devices are not required to belong to an existing tenant.
+type FakeDeviceRegistry struct {
+	server *httptest.Server
+
+	username string
+	password string
+
+	mutex       sync.Mutex
+	devices     map[string]map[string]*FakeRegistryDevice
+	credentials map[string]map[string]interface{}
+	tenants     map[string]interface{}
+	faults      []*fakeRegistryFault
+	requests    []string
+}
+
+type fakeRegistryFault struct {
+	method     string
+	pathPrefix string
+	status     int
+	remaining  int
+}
+
+// StartFakeDeviceRegistry starts a fake device registry, which accepts only the given basic authentication credentials
+func StartFakeDeviceRegistry(username string, password string) *FakeDeviceRegistry {
+	registry := &FakeDeviceRegistry{
+		username:    username,
+		password:    password,
+		devices:     map[string]map[string]*FakeRegistryDevice{},
+		credentials: map[string]map[string]interface{}{},
+		tenants:     map[string]interface{}{},
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(fakeRegistryDevicesPrefix, registry.handleDevices)
+	mux.HandleFunc(fakeRegistryCredentialsPrefix, registry.handleCredentials)
+	mux.HandleFunc(fakeRegistryTenantsPrefix, registry.handleTenants)
+
+	registry.server = httptest.NewServer(registry.intercept(mux))
+	return registry
+}
+
+// URL returns the address to be used as device registry API address, without the API version
+func (registry *FakeDeviceRegistry) URL() string {
+	return registry.server.URL
+}
+
+// Close stops the fake device registry
+func (registry *FakeDeviceRegistry) Close() {
+	registry.server.Close()
+}
+
+// InjectFault makes the registry respond with the given status to the next requests with the given method
+// and a path starting with the given prefix, e.g. http.MethodPut and "/v1/credentials/".
+// The fault is applied to the given number of requests, a count of zero or less applies it to all requests.
+func (registry *FakeDeviceRegistry) InjectFault(method string, pathPrefix string, status int, count int) {
+	registry.mutex.Lock()
+	defer registry.mutex.Unlock()
+	registry.faults = append(registry.faults, &fakeRegistryFault{
+		method:     method,
+		pathPrefix: pathPrefix,
+		status:     status,
+		remaining:  count,
+	})
+}
+
+// ClearFaults removes all injected faults
+func (registry *FakeDeviceRegistry) ClearFaults() {
+	registry.mutex.Lock()
+	defer registry.mutex.Unlock()
+	registry.faults = nil
+}
+
+// Requests returns all received requests in the order of their arrival, formatted as "<method> <path>"
+func (registry *FakeDeviceRegistry) Requests() []string {
+	registry.mutex.Lock()
+	defer registry.mutex.Unlock()
+	return append([]string{}, registry.requests...)
+}
+
+// AddDevice adds a device to the registry, optionally connected via the given gateway devices
+func (registry *FakeDeviceRegistry) AddDevice(tenantID string, deviceID string, via ...string) {
+	registry.mutex.Lock()
+	defer registry.mutex.Unlock()
+	registry.tenantDevices(tenantID)[deviceID] = &FakeRegistryDevice{Via: via}
+}
+
+// GetDevice returns a copy of a registered device
+func (registry *FakeDeviceRegistry) GetDevice(tenantID string, deviceID string) (*FakeRegistryDevice, bool) {
+	registry.mutex.Lock()
+	defer registry.mutex.Unlock()
+	device, ok := registry.devices[tenantID][deviceID]
+	if !ok {
+		return nil, false
+	}
+	result := *device
+	result.ID = deviceID
+	return &result, true
+}
+
+// GetCredentials returns the credentials of a registered device
+func (registry *FakeDeviceRegistry) GetCredentials(tenantID string, deviceID string) (interface{}, bool) {
+	registry.mutex.Lock()
+	defer registry.mutex.Unlock()
+	credentials, ok := registry.credentials[tenantID][deviceID]
+	return credentials, ok
+}
+
+// GetTenant returns the configuration of a registered tenant
+func (registry *FakeDeviceRegistry) GetTenant(tenantID string) (interface{}, bool) {
+	registry.mutex.Lock()
+	defer registry.mutex.Unlock()
+	tenant, ok := registry.tenants[tenantID]
+	return tenant, ok
+}
+
+func (registry *FakeDeviceRegistry) intercept(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		registry.mutex.Lock()
+		registry.requests = append(registry.requests, r.Method+" "+r.URL.Path)
+		registry.mutex.Unlock()
+
+		if username, password, ok := r.BasicAuth(); !ok || username != registry.username || password != registry.password {
+			writeFakeRegistryError(w, http.StatusUnauthorized, "invalid credentials")
+			return
+		}
+
+		// Faults are injected only for authenticated requests, as a real registry validates the credentials first
+		registry.mutex.Lock()
+		status := registry.nextFault(r.Method, r.URL.Path)
+		registry.mutex.Unlock()
+		if status != 0 {
+			writeFakeRegistryError(w, status, "injected fault")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// nextFault must be called with locked mutex
+func (registry *FakeDeviceRegistry) nextFault(method string, path string) int {
+	for i, fault := range registry.faults {
+		if fault.method != method || !strings.HasPrefix(path, fault.pathPrefix) {
+			continue
+		}
+		if fault.remaining > 0 {
+			fault.remaining--
+			if fault.remaining == 0 {
+				registry.faults = append(registry.faults[:i], registry.faults[i+1:]...)
+			}
+		}
+		return fault.status
+	}
+	return 0
+}
+
+// tenantDevices must be called with locked mutex
+func (registry *FakeDeviceRegistry) tenantDevices(tenantID string) map[string]*FakeRegistryDevice {
+	devices, ok := registry.devices[tenantID]
+	if !ok {
+		devices = map[string]*FakeRegistryDevice{}
+		registry.devices[tenantID] = devices
+	}
+	return devices
+}
+
+func (registry *FakeDeviceRegistry) handleDevices(w http.ResponseWriter, r *http.Request) {
+	tenantID, deviceID := splitRegistryPath(r.URL.Path, fakeRegistryDevicesPrefix)
+	if tenantID == "" {
+		writeFakeRegistryError(w, http.StatusNotFound, "tenant ID not specified")
+		return
+	}
+
+	registry.mutex.Lock()
+	defer registry.mutex.Unlock()
+
+	devices := registry.tenantDevices(tenantID)
+	if deviceID == "" {
+		if r.Method != http.MethodGet {
+			writeFakeRegistryError(w, http.StatusMethodNotAllowed, r.Method+" not supported")
+			return
+		}
+		registry.writeDevices(w, devices)
+		return
+	}
+
+	device, exists := devices[deviceID]
+	switch r.Method {
+	case http.MethodGet:
+		if !exists {
+			writeFakeRegistryError(w, http.StatusNotFound, fmt.Sprintf("device %s not found", deviceID))
+			return
+		}
+		writeFakeRegistryJSON(w, http.StatusOK, device)
+	case http.MethodPost, http.MethodPut:
+		if r.Method == http.MethodPost && exists {
+			writeFakeRegistryError(w, http.StatusConflict, fmt.Sprintf("device %s already exists", deviceID))
+			return
+		}
+		if r.Method == http.MethodPut && !exists {
+			writeFakeRegistryError(w, http.StatusNotFound, fmt.Sprintf("device %s not found", deviceID))
+			return
+		}
+		newDevice := &FakeRegistryDevice{}
+		if !readFakeRegistryBody(w, r, newDevice) {
+			return
+		}
+		newDevice.ID = ""
+		devices[deviceID] = newDevice
+		if r.Method == http.MethodPost {
+			writeFakeRegistryJSON(w, http.StatusCreated, map[string]string{"id": deviceID})
+		} else {
+			w.WriteHeader(http.StatusNoContent)
+		}
+	case http.MethodDelete:
+		if !exists {
+			writeFakeRegistryError(w, http.StatusNotFound, fmt.Sprintf("device %s not found", deviceID))
+			return
+		}
+		delete(devices, deviceID)
+		delete(registry.credentials[tenantID], deviceID)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		writeFakeRegistryError(w, http.StatusMethodNotAllowed, r.Method+" not supported")
+	}
+}
+
+func (registry *FakeDeviceRegistry) writeDevices(w http.ResponseWriter, devices map[string]*FakeRegistryDevice) {
+	type searchResult struct {
+		Total  int                   `json:"total"`
+		Result []*FakeRegistryDevice `json:"result"`
+	}
+
+	result := &searchResult{Result: []*FakeRegistryDevice{}}
+	for id, device := range devices {
+		found := *device
+		found.ID = id
+		result.Result = append(result.Result, &found)
+	}
+	sort.Slice(result.Result, func(i, j int) bool {
+		return result.Result[i].ID < result.Result[j].ID
+	})
+	result.Total = len(result.Result)
+	writeFakeRegistryJSON(w, http.StatusOK, result)
+}
+
+func (registry *FakeDeviceRegistry) handleCredentials(w http.ResponseWriter, r *http.Request) {
+	tenantID, deviceID := splitRegistryPath(r.URL.Path, fakeRegistryCredentialsPrefix)
+
+	registry.mutex.Lock()
+	defer registry.mutex.Unlock()
+
+	if _, ok := registry.devices[tenantID][deviceID]; !ok {
+		writeFakeRegistryError(w, http.StatusNotFound, fmt.Sprintf("device %s not found", deviceID))
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		writeFakeRegistryJSON(w, http.StatusOK, registry.credentials[tenantID][deviceID])
+	case http.MethodPut:
+		var credentials []interface{}
+		if !readFakeRegistryBody(w, r, &credentials) {
+			return
+		}
+		if registry.credentials[tenantID] == nil {
+			registry.credentials[tenantID] = map[string]interface{}{}
+		}
+		registry.credentials[tenantID][deviceID] = credentials
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		writeFakeRegistryError(w, http.StatusMethodNotAllowed, r.Method+" not supported")
+	}
+}
+
+func (registry *FakeDeviceRegistry) handleTenants(w http.ResponseWriter, r *http.Request) {
+	tenantID, _ := splitRegistryPath(r.URL.Path, fakeRegistryTenantsPrefix)
+	if tenantID == "" {
+		writeFakeRegistryError(w, http.StatusNotFound, "tenant ID not specified")
+		return
+	}
+
+	registry.mutex.Lock()
+	defer registry.mutex.Unlock()
+
+	tenant, exists := registry.tenants[tenantID]
+	switch r.Method {
+	case http.MethodGet:
+		if !exists {
+			writeFakeRegistryError(w, http.StatusNotFound, fmt.Sprintf("tenant %s not found", tenantID))
+			return
+		}
+		writeFakeRegistryJSON(w, http.StatusOK, tenant)
+	case http.MethodPost, http.MethodPut:
+		if r.Method == http.MethodPost && exists {
+			writeFakeRegistryError(w, http.StatusConflict, fmt.Sprintf("tenant %s already exists", tenantID))
+			return
+		}
+		if r.Method == http.MethodPut && !exists {
+			writeFakeRegistryError(w, http.StatusNotFound, fmt.Sprintf("tenant %s not found", tenantID))
+			return
+		}
+		newTenant := map[string]interface{}{}
+		if !readFakeRegistryBody(w, r, &newTenant) {
+			return
+		}
+		registry.tenants[tenantID] = newTenant
+		if r.Method == http.MethodPost {
+			writeFakeRegistryJSON(w, http.StatusCreated, map[string]string{"id": tenantID})
+		} else {
+			w.WriteHeader(http.StatusNoContent)
+		}
+	case http.MethodDelete:
+		if !exists {
+			writeFakeRegistryError(w, http.StatusNotFound, fmt.Sprintf("tenant %s not found", tenantID))
+			return
+		}
+		delete(registry.tenants, tenantID)
+		delete(registry.devices, tenantID)
+		delete(registry.credentials, tenantID)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		writeFakeRegistryError(w, http.StatusMethodNotAllowed, r.Method+" not supported")
+	}
+}
+
+func splitRegistryPath(path string, prefix string) (string, string) {
+	elements := strings.SplitN(strings.TrimPrefix(path, prefix), "/", 2)
+	if len(elements) == 1 {
+		return elements[0], ""
+	}
+	return elements[0], strings.TrimSuffix(elements[1], "/")
+}
+
+func readFakeRegistryBody(w http.ResponseWriter, r *http.Request, value interface{}) bool {
+	body, err := io.ReadAll(r.Body)
+	if err == nil && len(body) > 0 {
+		err = json.Unmarshal(body, value)
+	}
+	if err != nil {
+		writeFakeRegistryError(w, http.StatusBadRequest, fmt.Sprintf("invalid request body: %v", err))
+		return false
+	}
+	return true
+}
+
+func writeFakeRegistryJSON(w http.ResponseWriter, status int, value interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(value)
+}
+
+func writeFakeRegistryError(w http.ResponseWriter, status int, message string) {
+	writeFakeRegistryJSON(w, status, map[string]string{"error": message})
+}
@@ -0,0 +1,416 @@
+// Copyright (c) 2026 Contributors to the Eclipse Foundation
+//
+// See the NOTICE file(s) distributed with this work for additional
+// information regarding copyright ownership.
+//
+// This program and the accompanying materials are made available under the
+// terms of the Eclipse Public License 2.0 which is available at
+// https://www.eclipse.org/legal/epl-2.0, or the Apache License, Version 2.0
+// which is available at https://www.apache.org/licenses/LICENSE-2.0.
+//
+// SPDX-License-Identifier: EPL-2.0 OR Apache-2.0
+
+package util
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+const (
+	// TestPassed is the status of a passed test.
+	TestPassed = "passed"
+
+	// TestFailed is the status of a failed test.
+	TestFailed = "failed"
+
+	// TestSkipped is the status of a skipped test.
+	TestSkipped = "skipped"
+
+	unknownVersion = "unknown"
+
+	junitPropertyPrefix = "kanto."
+
+	fingerprintTimeout = 10 * time.Second
+)
+
+// kantoPackages are the Kanto packages, which versions are collected in the environment fingerprint
+var kantoPackages = []string{
+	"kanto",
+	"suite-connector",
+	"local-digital-twins",
+	"container-management",
+	"software-update",
+	"update-manager",
+	"file-upload",
+	"file-backup",
+	"system-metrics",
+	"aws-connector",
+	"azure-connector",
+}
+
+// dittoVersionPaths are requested in order until a Ditto version is found
+var dittoVersionPaths = []string{"/status", "/health"}
+
+// EnvironmentFingerprint describes the environment the tests are executed against.
+// It contains no secrets.
+type EnvironmentFingerprint struct {
+	Time time.Time `json:"time"`
+
+	LocalBroker           string `json:"localBroker"`
+	MQTTVersion           string `json:"mqttVersion"`
+	DigitalTwinAPIAddress string `json:"digitalTwinApiAddress"`
+	DittoVersion          string `json:"dittoVersion"`
+
+	DeviceID string `json:"deviceId"`
+	TenantID string `json:"tenantId"`
+
+	// Packages holds the versions of the installed Kanto packages
+	Packages map[string]string `json:"packages,omitempty"`
+
+	// Configuration holds the test configuration environment variables with redacted secrets
+	Configuration map[string]string `json:"configuration"`
+}
+
+// TestResult is the result of a single test in a test report
+type TestResult struct {
+	Name     string        `json:"name"`
+	Status   string        `json:"status"`
+	Duration time.Duration `json:"duration"`
+}
+
+// TestReport is a JSON test report of a suite, which can also be written as JUnit XML
+type TestReport struct {
+	Suite       string                  `json:"suite"`
+	Status      string                  `json:"status"`
+	Started     time.Time               `json:"started"`
+	Duration    time.Duration           `json:"duration"`
+	Environment *EnvironmentFingerprint `json:"environment"`
+	Tests       []*TestResult           `json:"tests"`
+
+	mutex   sync.Mutex
+	tracked map[string]bool
+}
+
+// CollectEnvironmentFingerprint collects the environment fingerprint for the test configuration and the thing.
+// Failures to retrieve the Ditto version or the installed packages are not fatal, the values are left unknown.
+func CollectEnvironmentFingerprint(ctx context.Context, cfg *TestConfiguration,
+	thingCfg *ThingConfiguration) *EnvironmentFingerprint {
+	ctx, cancel := context.WithTimeout(ctx, fingerprintTimeout)
+	defer cancel()
+
+	fingerprint := &EnvironmentFingerprint{
+		Time:                  time.Now(),
+		LocalBroker:           redactAddress(cfg.LocalBroker),
+		MQTTVersion:           cfg.MQTTVersion,
+		DigitalTwinAPIAddress: redactAddress(cfg.DigitalTwinAPIAddress),
+		DittoVersion:          GetDittoVersion(ctx, cfg),
+		Packages:              GetKantoPackageVersions(ctx),
+		Configuration:         RedactConfiguration(cfg),
+	}
+	if thingCfg != nil {
+		fingerprint.DeviceID = thingCfg.DeviceID
+		fingerprint.TenantID = thingCfg.TenantID
+	}
+	return fingerprint
+}
+
+// GetDittoVersion retrieves the Ditto version from its status or health resources.
+// If the version cannot be retrieved, "unknown" is returned.
+func GetDittoVersion(ctx context.Context, cfg *TestConfiguration) string {
+	address := strings.TrimSuffix(cfg.DigitalTwinAPIAddress, "/")
+	for _, path := range dittoVersionPaths {
+		body, err := SendDigitalTwinRequest(ctx, cfg, http.MethodGet, address+path, nil)
+		if err != nil {
+			continue
+		}
+		var value interface{}
+		if err := json.Unmarshal(body, &value); err != nil {
+			continue
+		}
+		if version := findVersion(value); version != "" {
+			return version
+		}
+	}
+	return unknownVersion
+}
+
+// findVersion returns the first version found in a Ditto status or health JSON
+func findVersion(value interface{}) string {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if version, ok := v["version"].(string); ok && version != "" {
+			return version
+		}
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			if version := findVersion(v[key]); version != "" {
+				return version
+			}
+		}
+	case []interface{}:
+		for _, item := range v {
+			if version := findVersion(item); version != "" {
+				return version
+			}
+		}
+	}
+	return ""
+}
+
+// GetKantoPackageVersions returns the versions of the installed Kanto packages using the package manager.
+// Packages, which are not installed, are omitted.
+func GetKantoPackageVersions(ctx context.Context) map[string]string {
+	args := append([]string{"-W", "-f", "${Package} ${Version} ${db:Status-Abbrev}\n"}, kantoPackages...)
+	// dpkg-query fails if any of the packages is not installed, but still prints the installed ones
+	output, _ := exec.CommandContext(ctx, "dpkg-query", args...).Output()
+
+	versions := map[string]string{}
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) >= 3 && strings.HasPrefix(fields[2], "i") {
+			versions[fields[0]] = fields[1]
+		}
+	}
+	return versions
+}
+
+// RedactConfiguration returns the environment variables of the test configuration with redacted secrets
+func RedactConfiguration(cfg *TestConfiguration) map[string]string {
+	result := map[string]string{}
+	value := reflect.ValueOf(cfg).Elem()
+	for i := 0; i < value.NumField(); i++ {
+		name, ok := value.Type().Field(i).Tag.Lookup("env")
+		if !ok {
+			continue
+		}
+		fieldValue := fmt.Sprint(value.Field(i).Interface())
+		switch {
+		case sensitiveKeys.MatchString(name) && fieldValue != "":
+			result[name] = redacted
+		case strings.HasSuffix(name, "_ADDRESS") || name == "LOCAL_BROKER":
+			result[name] = redactAddress(fieldValue)
+		default:
+			result[name] = fieldValue
+		}
+	}
+	return result
+}
+
+func redactAddress(address string) string {
+	u, err := url.Parse(address)
+	if err != nil {
+		return address
+	}
+	return redactURL(u)
+}
+
+// NewTestReport creates a new report for the suite test with the environment fingerprint
+func NewTestReport(t *testing.T, fingerprint *EnvironmentFingerprint) *TestReport {
+	return &TestReport{
+		Suite:       t.Name(),
+		Started:     time.Now(),
+		Environment: fingerprint,
+		tracked:     map[string]bool{},
+	}
+}
+
+// Track adds the result of the test to the report, when the test completes.
+// Tracking the same test again has no effect.
+func (report *TestReport) Track(t *testing.T) {
+	if report == nil {
+		return
+	}
+
+	report.mutex.Lock()
+	defer report.mutex.Unlock()
+
+	if report.tracked[t.Name()] {
+		return
+	}
+	report.tracked[t.Name()] = true
+
+	started := time.Now()
+	t.Cleanup(func() {
+		report.mutex.Lock()
+		defer report.mutex.Unlock()
+
+		report.Tests = append(report.Tests, &TestResult{
+			Name:     t.Name(),
+			Status:   testStatus(t),
+			Duration: time.Since(started),
+		})
+	})
+}
+
+func testStatus(t *testing.T) string {
+	if t.Failed() {
+		return TestFailed
+	}
+	if t.Skipped() {
+		return TestSkipped
+	}
+	return TestPassed
+}
+
+// Complete sets the status and the duration of the suite
+func (report *TestReport) Complete(t *testing.T) {
+	report.mutex.Lock()
+	defer report.mutex.Unlock()
+
+	report.Status = testStatus(t)
+	report.Duration = time.Since(report.Started)
+}
+
+// WriteJSON writes the report as JSON to a file
+func (report *TestReport) WriteJSON(path string) error {
+	report.mutex.Lock()
+	defer report.mutex.Unlock()
+
+	data, err := json.MarshalIndent(report, "", "\t")
+	if err != nil {
+		return fmt.Errorf("unable to marshal test report: %v", err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("unable to save test report %s: %v", path, err)
+	}
+	return nil
+}
+
+type junitTestSuite struct {
+	XMLName    xml.Name         `xml:"testsuite"`
+	Name       string           `xml:"name,attr"`
+	Tests      int              `xml:"tests,attr"`
+	Failures   int              `xml:"failures,attr"`
+	Skipped    int              `xml:"skipped,attr"`
+	Time       string           `xml:"time,attr"`
+	Timestamp  string           `xml:"timestamp,attr"`
+	Properties []junitProperty  `xml:"properties>property"`
+	TestCases  []*junitTestCase `xml:"testcase"`
+}
+
+type junitProperty struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:"value,attr"`
+}
+
+type junitTestCase struct {
+	Name    string        `xml:"name,attr"`
+	Class   string        `xml:"classname,attr"`
+	Time    string        `xml:"time,attr"`
+	Failure *junitMessage `xml:"failure,omitempty"`
+	Skipped *junitMessage `xml:"skipped,omitempty"`
+}
+
+type junitMessage struct {
+	Message string `xml:"message,attr"`
+}
+
+// WriteJUnit writes the report as JUnit XML to a file. The environment fingerprint is written as properties.
+func (report *TestReport) WriteJUnit(path string) error {
+	report.mutex.Lock()
+	defer report.mutex.Unlock()
+
+	suite := &junitTestSuite{
+		Name:       report.Suite,
+		Tests:      len(report.Tests),
+		Time:       formatSeconds(report.Duration),
+		Timestamp:  report.Started.Format(time.RFC3339),
+		Properties: report.Environment.junitProperties(),
+	}
+	for _, test := range report.Tests {
+		testCase := &junitTestCase{
+			Name:  test.Name,
+			Class: report.Suite,
+			Time:  formatSeconds(test.Duration),
+		}
+		switch test.Status {
+		case TestFailed:
+			suite.Failures++
+			testCase.Failure = &junitMessage{Message: "test failed"}
+		case TestSkipped:
+			suite.Skipped++
+			testCase.Skipped = &junitMessage{Message: "test skipped"}
+		}
+		suite.TestCases = append(suite.TestCases, testCase)
+	}
+
+	data, err := xml.MarshalIndent(suite, "", "\t")
+	if err != nil {
+		return fmt.Errorf("unable to marshal JUnit report: %v", err)
+	}
+	data = append([]byte(xml.Header), data...)
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("unable to save JUnit report %s: %v", path, err)
+	}
+	return nil
+}
+
+func (fingerprint *EnvironmentFingerprint) junitProperties() []junitProperty {
+	if fingerprint == nil {
+		return nil
+	}
+	properties := []junitProperty{
+		{Name: junitPropertyPrefix + "localBroker", Value: fingerprint.LocalBroker},
+		{Name: junitPropertyPrefix + "mqttVersion", Value: fingerprint.MQTTVersion},
+		{Name: junitPropertyPrefix + "digitalTwinApiAddress", Value: fingerprint.DigitalTwinAPIAddress},
+		{Name: junitPropertyPrefix + "dittoVersion", Value: fingerprint.DittoVersion},
+		{Name: junitPropertyPrefix + "deviceId", Value: fingerprint.DeviceID},
+		{Name: junitPropertyPrefix + "tenantId", Value: fingerprint.TenantID},
+	}
+	packages := make([]string, 0, len(fingerprint.Packages))
+	for name := range fingerprint.Packages {
+		packages = append(packages, name)
+	}
+	sort.Strings(packages)
+	for _, name := range packages {
+		properties = append(properties, junitProperty{
+			Name:  junitPropertyPrefix + "package." + name,
+			Value: fingerprint.Packages[name],
+		})
+	}
+	return properties
+}
+
+func formatSeconds(duration time.Duration) string {
+	return fmt.Sprintf("%.3f", duration.Seconds())
+}
+
+// writeReports writes the JSON and optionally the JUnit report of the suite to the report directory
+func (report *TestReport) writeReports(dir string, junit bool) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("unable to create test report directory %s: %v", dir, err)
+	}
+	name := testFileName(report.Suite)
+
+	var errors []error
+	if err := report.WriteJSON(filepath.Join(dir, name+".json")); err != nil {
+		errors = append(errors, err)
+	}
+	if junit {
+		if err := report.WriteJUnit(filepath.Join(dir, name+".xml")); err != nil {
+			errors = append(errors, err)
+		}
+	}
+	return CombineErrors(errors)
+}
@@ -0,0 +1,385 @@
+// Copyright (c) 2026 Contributors to the Eclipse Foundation
+//
+// See the NOTICE file(s) distributed with this work for additional
+// information regarding copyright ownership.
+//
+// This program and the accompanying materials are made available under the
+// terms of the Eclipse Public License 2.0 which is available at
+// https://www.eclipse.org/legal/epl-2.0, or the Apache License, Version 2.0
+// which is available at https://www.apache.org/licenses/LICENSE-2.0.
+//
+// SPDX-License-Identifier: EPL-2.0 OR Apache-2.0
+
+package util
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// LogLevel is the level of a service log entry
+type LogLevel string
+
+const (
+	// LogLevelTrace is the level of trace log entries.
+	LogLevelTrace LogLevel = "TRACE"
+
+	// LogLevelDebug is the level of debug log entries.
+	LogLevelDebug LogLevel = "DEBUG"
+
+	// LogLevelInfo is the level of info log entries.
+	LogLevelInfo LogLevel = "INFO"
+
+	// LogLevelWarn is the level of warning log entries.
+	LogLevelWarn LogLevel = "WARN"
+
+	// LogLevelError is the level of error log entries.
+	LogLevelError LogLevel = "ERROR"
+
+	// LogLevelUnknown is the level of log entries, which are not in a known format.
+	LogLevelUnknown LogLevel = "UNKNOWN"
+
+	// logLinePrefixes match the optional bracketed prefixes between the timestamp and the level,
+	// e.g. the component name of the Kanto services
+	logLinePrefixes = `\s+(?:\[[^\]]*\]\s*)*`
+
+	// logLineLevel matches the level, optionally in brackets, followed by the message
+	logLineLevel = `\[?(TRACE|DEBUG|INFO|WARN|WARNING|ERROR|FATAL|PANIC)\]?(?:\s+(.*))?$`
+
+	logPollInterval = 200 * time.Millisecond
+
+	// maxAttachedLogLines limits the log lines attached to a failed test
+	maxAttachedLogLines = 200
+)
+
+var (
+	// logLineFormats match the Go standard log and the RFC 3339 timestamps followed by the level and the message,
+	// e.g. the suite connector log line "2024/02/01 09:41:07.123456 [suite-connector] INFO  Connected"
+	logLineFormats = []struct {
+		pattern *regexp.Regexp
+		layout  string
+	}{
+		{
+			pattern: regexp.MustCompile(`^(\d{4}/\d{2}/\d{2} \d{2}:\d{2}:\d{2}(?:\.\d+)?)` +
+				logLinePrefixes + logLineLevel),
+			layout: "2006/01/02 15:04:05.999999999",
+		},
+		{
+			pattern: regexp.MustCompile(`^(\d{4}-\d{2}-\d{2}[T ]\d{2}:\d{2}:\d{2}(?:\.\d+)?(?:Z|[+-]\d{2}:?\d{2})?)` +
+				logLinePrefixes + logLineLevel),
+			layout: time.RFC3339Nano,
+		},
+	}
+
+	logLevelAliases = map[string]LogLevel{
+		"WARNING": LogLevelWarn,
+		"FATAL":   LogLevelError,
+		"PANIC":   LogLevelError,
+	}
+)
+
+// LogEntry is a parsed service log entry. Indented lines, which don't start with a timestamp and a level,
+// e.g. stack traces, are appended to the message of the previous entry. Other lines in an unknown format
+// are entries on their own with LogLevelUnknown.
+type LogEntry struct {
+	Time    time.Time
+	Level   LogLevel
+	Message string
+
+	// Line is the raw log line
+	Line string
+}
+
+// ParseLogLine parses a Kanto service log line into its timestamp, level and message.
+// False is returned if the line is not in a known format.
+func ParseLogLine(line string) (*LogEntry, bool) {
+	for _, format := range logLineFormats {
+		match := format.pattern.FindStringSubmatch(line)
+		if match == nil {
+			continue
+		}
+		entry := &LogEntry{
+			Level:   LogLevel(match[2]),
+			Message: strings.TrimSpace(match[3]),
+			Line:    line,
+		}
+		if alias, ok := logLevelAliases[match[2]]; ok {
+			entry.Level = alias
+		}
+		entry.Time = parseLogTime(format.layout, match[1])
+		return entry, true
+	}
+	return nil, false
+}
+
+func parseLogTime(layout string, value string) time.Time {
+	if layout == time.RFC3339Nano {
+		value = strings.Replace(value, " ", "T", 1)
+		if parsed, err := time.Parse(layout, value); err == nil {
+			return parsed
+		}
+		// Timestamps without zone are in local time
+		layout = "2006-01-02T15:04:05.999999999"
+	}
+	parsed, _ := time.ParseInLocation(layout, value, time.Local)
+	return parsed
+}
+
+// LogTail reads the entries appended to a service log file after a given offset.
+// The file is reopened on each read, so rotated or truncated files are read from their beginning.
+type LogTail struct {
+	path string
+
+	mutex   sync.Mutex
+	offset  int64
+	partial []byte
+	entries []*LogEntry
+}
+
+// NewLogTail creates a tail of the log file starting at its current end.
+// The file does not have to exist yet.
+func NewLogTail(path string) (*LogTail, error) {
+	var offset int64
+	info, err := os.Stat(path)
+	if err == nil {
+		offset = info.Size()
+	} else if !os.IsNotExist(err) {
+		return nil, fmt.Errorf("unable to access log file %s: %v", path, err)
+	}
+	return NewLogTailAt(path, offset), nil
+}
+
+// NewLogTailAt creates a tail of the log file starting at the given offset
+func NewLogTailAt(path string, offset int64) *LogTail {
+	return &LogTail{path: path, offset: offset}
+}
+
+// Offset returns the offset in the log file up to which the entries are read
+func (tail *LogTail) Offset() int64 {
+	tail.mutex.Lock()
+	defer tail.mutex.Unlock()
+
+	return tail.offset
+}
+
+// Entries reads the new entries from the log file and returns all entries read by the tail
+func (tail *LogTail) Entries() ([]*LogEntry, error) {
+	tail.mutex.Lock()
+	defer tail.mutex.Unlock()
+
+	err := tail.read()
+	entries := make([]*LogEntry, len(tail.entries))
+	copy(entries, tail.entries)
+	return entries, err
+}
+
+// read reads the complete lines appended to the log file.
+// The last entry read before may be extended with continuation lines.
+func (tail *LogTail) read() error {
+	file, err := os.Open(tail.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("unable to open log file %s: %v", tail.path, err)
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return fmt.Errorf("unable to access log file %s: %v", tail.path, err)
+	}
+	if info.Size() < tail.offset {
+		// The log file is rotated or truncated
+		tail.offset = 0
+		tail.partial = nil
+	}
+	if _, err := file.Seek(tail.offset, io.SeekStart); err != nil {
+		return fmt.Errorf("unable to read log file %s: %v", tail.path, err)
+	}
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return fmt.Errorf("unable to read log file %s: %v", tail.path, err)
+	}
+	tail.offset += int64(len(data))
+
+	data = append(tail.partial, data...)
+	end := bytes.LastIndexByte(data, '\n')
+	if end < 0 {
+		tail.partial = data
+		return nil
+	}
+	tail.partial = append([]byte(nil), data[end+1:]...)
+
+	for _, line := range strings.Split(string(data[:end]), "\n") {
+		line = strings.TrimRight(line, "\r")
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		if entry, ok := ParseLogLine(line); ok {
+			tail.entries = append(tail.entries, entry)
+		} else if len(tail.entries) > 0 && (line[0] == ' ' || line[0] == '\t') {
+			// The returned entries are not modified, so the last one is replaced by the extended copy
+			last := *tail.entries[len(tail.entries)-1]
+			last.Message += "\n" + line
+			last.Line += "\n" + line
+			tail.entries[len(tail.entries)-1] = &last
+		} else {
+			tail.entries = append(tail.entries, &LogEntry{Level: LogLevelUnknown, Message: line, Line: line})
+		}
+	}
+	return nil
+}
+
+// WaitFor waits for an entry with a message matching the pattern to be appended to the log file.
+// Entries read before the call are also checked.
+func (tail *LogTail) WaitFor(ctx context.Context, pattern *regexp.Regexp) (*LogEntry, error) {
+	ticker := time.NewTicker(logPollInterval)
+	defer ticker.Stop()
+
+	checked := 0
+	for {
+		entry, err := tail.match(pattern, &checked)
+		if entry != nil || err != nil {
+			return entry, err
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("no log entry matching %s in %s: %v", pattern, tail.path, ctx.Err())
+		case <-ticker.C:
+		}
+	}
+}
+
+// match reads the new entries and checks them for a message matching the pattern, starting at the checked index.
+// The entries are checked while locked, as reading extends the last entry with continuation lines.
+func (tail *LogTail) match(pattern *regexp.Regexp, checked *int) (*LogEntry, error) {
+	tail.mutex.Lock()
+	defer tail.mutex.Unlock()
+
+	if err := tail.read(); err != nil {
+		return nil, err
+	}
+	// The last checked entry may be extended with continuation lines, so check it again
+	if *checked > 0 {
+		*checked--
+	}
+	for ; *checked < len(tail.entries); *checked++ {
+		if pattern.MatchString(tail.entries[*checked].Message) {
+			return tail.entries[*checked], nil
+		}
+	}
+	return nil, nil
+}
+
+// Filter reads the new entries from the log file and returns the entries with the given level
+func (tail *LogTail) Filter(level LogLevel) ([]*LogEntry, error) {
+	entries, err := tail.Entries()
+	var result []*LogEntry
+	for _, entry := range entries {
+		if entry.Level == level {
+			result = append(result, entry)
+		}
+	}
+	return result, err
+}
+
+// String returns the raw lines of the entries read by the tail
+func (tail *LogTail) String() string {
+	tail.mutex.Lock()
+	defer tail.mutex.Unlock()
+
+	return formatLogEntries(tail.entries)
+}
+
+func formatLogEntries(entries []*LogEntry) string {
+	lines := make([]string, len(entries))
+	for i, entry := range entries {
+		lines[i] = entry.Line
+	}
+	return strings.Join(lines, "\n")
+}
+
+// TailServiceLog creates a tail of a service log file starting at its current end.
+// If the test fails, the log entries appended during the test are attached to the test output.
+func TailServiceLog(t *testing.T, path string) *LogTail {
+	tail, err := NewLogTail(path)
+	require.NoError(t, err, "tail service log")
+
+	t.Cleanup(func() {
+		if !t.Failed() {
+			return
+		}
+		entries, err := tail.Entries()
+		if err != nil {
+			t.Logf("unable to read service log %s: %v", path, err)
+		}
+		if len(entries) > maxAttachedLogLines {
+			entries = entries[len(entries)-maxAttachedLogLines:]
+		}
+		t.Logf("service log %s during the test:\n%s", path, formatLogEntries(entries))
+	})
+	return tail
+}
+
+// RequireNoErrors fails the test if there are error entries in the log since the start of the tail.
+// As errors cannot be detected otherwise, it also fails if the log file does not exist or if none of the lines
+// since the start of the tail is in a known format.
+func (tail *LogTail) RequireNoErrors(t *testing.T) {
+	require.NoError(t, tail.checkNoErrors())
+}
+
+func (tail *LogTail) checkNoErrors() error {
+	if _, err := os.Stat(tail.path); err != nil {
+		return fmt.Errorf("unable to access service log %s: %v", tail.path, err)
+	}
+	entries, err := tail.Entries()
+	if err != nil {
+		return err
+	}
+
+	var errors []*LogEntry
+	parsed := false
+	for _, entry := range entries {
+		if entry.Level != LogLevelUnknown {
+			parsed = true
+		}
+		if entry.Level == LogLevelError {
+			errors = append(errors, entry)
+		}
+	}
+	if len(entries) > 0 && !parsed {
+		return fmt.Errorf("no line of service log %s is in a known format:\n%s", tail.path, formatLogEntries(entries))
+	}
+	if len(errors) > 0 {
+		return fmt.Errorf("error entries in service log %s:\n%s", tail.path, formatLogEntries(errors))
+	}
+	return nil
+}
+
+// RequireEntry fails the test if no entry with a message matching the pattern appears in the log
+// within the timeout. The matching entry is returned.
+func (tail *LogTail) RequireEntry(t *testing.T, pattern string, timeout time.Duration) *LogEntry {
+	expr, err := regexp.Compile(pattern)
+	require.NoError(t, err, "invalid log entry pattern")
+
+	ctx, cancel := NewTestContext(t)
+	defer cancel()
+	ctx, cancel = context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	entry, err := tail.WaitFor(ctx, expr)
+	require.NoError(t, err, "wait for service log entry")
+	return entry
+}
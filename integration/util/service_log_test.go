@@ -0,0 +1,180 @@
+// Copyright (c) 2026 Contributors to the Eclipse Foundation
+//
+// See the NOTICE file(s) distributed with this work for additional
+// information regarding copyright ownership.
+//
+// This program and the accompanying materials are made available under the
+// terms of the Eclipse Public License 2.0 which is available at
+// https://www.eclipse.org/legal/epl-2.0, or the Apache License, Version 2.0
+// which is available at https://www.apache.org/licenses/LICENSE-2.0.
+//
+// SPDX-License-Identifier: EPL-2.0 OR Apache-2.0
+
+package util
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseLogLine(t *testing.T) {
+	tests := []struct {
+		line    string
+		level   LogLevel
+		message string
+		time    time.Time
+	}{
+		{
+			line:    "2024/02/01 09:41:07.123456 [suite-connector] INFO  Connected to the local broker",
+			level:   LogLevelInfo,
+			message: "Connected to the local broker",
+			time:    time.Date(2024, 2, 1, 9, 41, 7, 123456000, time.Local),
+		},
+		{
+			line:    "2024/02/01 09:41:07 ERROR unable to connect",
+			level:   LogLevelError,
+			message: "unable to connect",
+			time:    time.Date(2024, 2, 1, 9, 41, 7, 0, time.Local),
+		},
+		{
+			line:    "2024-02-01T09:41:07.5Z [container-management][ctrd] [WARNING] container restarted",
+			level:   LogLevelWarn,
+			message: "container restarted",
+			time:    time.Date(2024, 2, 1, 9, 41, 7, 500000000, time.UTC),
+		},
+		{
+			line:  "2024-02-01 09:41:07+02:00 FATAL",
+			level: LogLevelError,
+			time:  time.Date(2024, 2, 1, 7, 41, 7, 0, time.UTC),
+		},
+	}
+	for _, test := range tests {
+		entry, ok := ParseLogLine(test.line)
+		require.True(t, ok, test.line)
+		require.Equal(t, test.level, entry.Level, test.line)
+		require.Equal(t, test.message, entry.Message, test.line)
+		require.True(t, test.time.Equal(entry.Time), "%s: unexpected time %v", test.line, entry.Time)
+	}
+
+	for _, line := range []string{
+		"INFO Connected",
+		"2024/02/01 09:41:07.123456 INFORMATION Connected",
+		"2024/02/01 09:41:07.123456 [suite-connector] Connected",
+	} {
+		_, ok := ParseLogLine(line)
+		require.False(t, ok, line)
+	}
+}
+
+func writeTestLog(t *testing.T, path string, lines ...string) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	require.NoError(t, err)
+	defer file.Close()
+	_, err = file.WriteString(strings.Join(lines, "\n") + "\n")
+	require.NoError(t, err)
+}
+
+func TestLogTailEntries(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "service.log")
+	writeTestLog(t, path, "2024/02/01 09:41:07 INFO before the tail")
+	tail, err := NewLogTail(path)
+	require.NoError(t, err)
+
+	writeTestLog(t, path,
+		"starting service",
+		"2024/02/01 09:41:08 [service] ERROR unable to connect",
+		"\tcaused by: connection refused",
+		"plain output")
+
+	entries, err := tail.Entries()
+	require.NoError(t, err)
+	require.Len(t, entries, 3)
+	require.Equal(t, LogLevelUnknown, entries[0].Level)
+	require.Equal(t, "starting service", entries[0].Message)
+	require.Equal(t, LogLevelError, entries[1].Level)
+	require.Equal(t, "unable to connect\n\tcaused by: connection refused", entries[1].Message)
+	require.Equal(t, LogLevelUnknown, entries[2].Level)
+	require.Equal(t, "plain output", entries[2].Line)
+}
+
+func TestLogTailEntriesNotModified(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "service.log")
+	tail := NewLogTailAt(path, 0)
+
+	writeTestLog(t, path, "2024/02/01 09:41:08 [service] ERROR unable to connect")
+	entries, err := tail.Entries()
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+
+	writeTestLog(t, path, "\tcaused by: connection refused")
+	extended, err := tail.Entries()
+	require.NoError(t, err)
+	require.Equal(t, "unable to connect\n\tcaused by: connection refused", extended[0].Message)
+	require.Equal(t, "unable to connect", entries[0].Message)
+}
+
+func TestLogTailWaitForWhileExtended(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "service.log")
+	tail := NewLogTailAt(path, 0)
+	writeTestLog(t, path, "2024/02/01 09:41:07 [service] INFO starting")
+
+	ctx, cancel := NewTestContext(t)
+	defer cancel()
+	type result struct {
+		entry *LogEntry
+		err   error
+	}
+	waited := make(chan result, 1)
+	go func() {
+		entry, err := tail.WaitFor(ctx, regexp.MustCompile("^ready$"))
+		waited <- result{entry: entry, err: err}
+	}()
+
+	// The entry checked by WaitFor is extended concurrently
+	deadline := time.Now().Add(3 * logPollInterval)
+	for i := 0; time.Now().Before(deadline); i++ {
+		writeTestLog(t, path, fmt.Sprintf("\tcontinuation %d", i))
+		_, err := tail.Entries()
+		require.NoError(t, err)
+	}
+	writeTestLog(t, path, "2024/02/01 09:41:08 [service] INFO ready")
+
+	received := <-waited
+	require.NoError(t, received.err)
+	require.Equal(t, "ready", received.entry.Message)
+}
+
+func TestLogTailCheckNoErrors(t *testing.T) {
+	dir := t.TempDir()
+
+	missing := NewLogTailAt(filepath.Join(dir, "missing.log"), 0)
+	require.Error(t, missing.checkNoErrors())
+
+	unknownPath := filepath.Join(dir, "unknown.log")
+	writeTestLog(t, unknownPath, "Connected", "Disconnected")
+	err := NewLogTailAt(unknownPath, 0).checkNoErrors()
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "known format")
+
+	errorPath := filepath.Join(dir, "error.log")
+	writeTestLog(t, errorPath, "2024/02/01 09:41:07 [service] INFO Connected", "2024/02/01 09:41:08 [service] ERROR Disconnected")
+	err = NewLogTailAt(errorPath, 0).checkNoErrors()
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "Disconnected")
+
+	validPath := filepath.Join(dir, "valid.log")
+	writeTestLog(t, validPath, "2024/02/01 09:41:07 [service] INFO Connected", "unknown output")
+	require.NoError(t, NewLogTailAt(validPath, 0).checkNoErrors())
+
+	// An empty log since the start of the tail has no errors
+	tail, err := NewLogTail(errorPath)
+	require.NoError(t, err)
+	require.NoError(t, tail.checkNoErrors())
+}
@@ -0,0 +1,300 @@
+// Copyright (c) 2026 Contributors to the Eclipse Foundation
+//
+// See the NOTICE file(s) distributed with this work for additional
+// information regarding copyright ownership.
+//
+// This program and the accompanying materials are made available under the
+// terms of the Eclipse Public License 2.0 which is available at
+// https://www.eclipse.org/legal/epl-2.0, or the Apache License, Version 2.0
+// which is available at https://www.apache.org/licenses/LICENSE-2.0.
+//
+// SPDX-License-Identifier: EPL-2.0 OR Apache-2.0
+
+package util
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"path"
+
+	"github.com/google/uuid"
+)
+
+// SoftwareStatus is the status of a software operation
+type SoftwareStatus string
+
+const (
+	// SoftwareUpdatableFeatureID is the ID of the SoftwareUpdatable feature
+	SoftwareUpdatableFeatureID = "SoftwareUpdatable"
+
+	// SoftwareStatusStarted is reported when an operation is started.
+	SoftwareStatusStarted SoftwareStatus = "STARTED"
+	// SoftwareStatusDownloading is reported while the artifacts are downloaded.
+	SoftwareStatusDownloading SoftwareStatus = "DOWNLOADING"
+	// SoftwareStatusDownloadingWaiting is reported while the download waits for a precondition.
+	SoftwareStatusDownloadingWaiting SoftwareStatus = "DOWNLOADING_WAITING"
+	// SoftwareStatusDownloaded is reported when the artifacts are downloaded.
+	SoftwareStatusDownloaded SoftwareStatus = "DOWNLOADED"
+	// SoftwareStatusInstalling is reported while the software is installed.
+	SoftwareStatusInstalling SoftwareStatus = "INSTALLING"
+	// SoftwareStatusInstallingWaiting is reported while the installation waits for a precondition.
+	SoftwareStatusInstallingWaiting SoftwareStatus = "INSTALLING_WAITING"
+	// SoftwareStatusInstalled is reported when the software is installed.
+	SoftwareStatusInstalled SoftwareStatus = "INSTALLED"
+	// SoftwareStatusRemoving is reported while the software is removed.
+	SoftwareStatusRemoving SoftwareStatus = "REMOVING"
+	// SoftwareStatusRemovingWaiting is reported while the removal waits for a precondition.
+	SoftwareStatusRemovingWaiting SoftwareStatus = "REMOVING_WAITING"
+	// SoftwareStatusRemoved is reported when the software is removed.
+	SoftwareStatusRemoved SoftwareStatus = "REMOVED"
+	// SoftwareStatusCanceled is reported when an operation is canceled.
+	SoftwareStatusCanceled SoftwareStatus = "CANCELED"
+	// SoftwareStatusCanceledRejected is reported when the cancellation of an operation is rejected.
+	SoftwareStatusCanceledRejected SoftwareStatus = "CANCELED_REJECTED"
+	// SoftwareStatusFinishedSuccess is reported when an operation completes successfully.
+	SoftwareStatusFinishedSuccess SoftwareStatus = "FINISHED_SUCCESS"
+	// SoftwareStatusFinishedError is reported when an operation fails.
+	SoftwareStatusFinishedError SoftwareStatus = "FINISHED_ERROR"
+	// SoftwareStatusFinishedRejected is reported when an operation is rejected.
+	SoftwareStatusFinishedRejected SoftwareStatus = "FINISHED_REJECTED"
+
+	softwareOperationInstall      = "install"
+	softwareOperationDownload     = "download"
+	softwareOperationRemove       = "remove"
+	softwareOperationCancel       = "cancel"
+	softwareOperationCancelRemove = "cancelRemove"
+
+	softwarePropertyLastOperation       = "status/lastOperation"
+	softwarePropertyLastFailedOperation = "status/lastFailedOperation"
+	softwarePropertyInstalled           = "status/installedDependencies"
+)
+
+// IsFinal returns true if no more progress is reported for an operation with the status
+func (status SoftwareStatus) IsFinal() bool {
+	switch status {
+	case SoftwareStatusFinishedSuccess, SoftwareStatusFinishedError, SoftwareStatusFinishedRejected,
+		SoftwareStatusCanceled, SoftwareStatusCanceledRejected:
+		return true
+	}
+	return false
+}
+
+// SoftwareModuleID identifies a software module
+type SoftwareModuleID struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+// SoftwareDependency describes an installed software
+type SoftwareDependency struct {
+	Group   string `json:"group,omitempty"`
+	Name    string `json:"name"`
+	Version string `json:"version"`
+	Type    string `json:"type,omitempty"`
+}
+
+// SoftwareLinks are the download links of an artifact for a protocol
+type SoftwareLinks struct {
+	URL    string `json:"url"`
+	MD5URL string `json:"md5url,omitempty"`
+}
+
+// SoftwareArtifactAction describes an artifact of a software module to be downloaded.
+// The download links are keyed by protocol, e.g. HTTPS, and the checksums by hash algorithm, e.g. SHA256.
+type SoftwareArtifactAction struct {
+	Filename  string                    `json:"filename"`
+	Download  map[string]*SoftwareLinks `json:"download"`
+	Checksums map[string]string         `json:"checksums"`
+	Size      int                       `json:"size"`
+}
+
+// SoftwareModuleAction describes a software module to be downloaded or installed with its artifacts
+type SoftwareModuleAction struct {
+	SoftwareModule *SoftwareModuleID         `json:"softwareModule"`
+	Artifacts      []*SoftwareArtifactAction `json:"artifacts,omitempty"`
+	Metadata       map[string]string         `json:"metadata,omitempty"`
+	Forced         bool                      `json:"forced,omitempty"`
+}
+
+// SoftwareUpdateAction is the parameter of the install and download operations
+type SoftwareUpdateAction struct {
+	CorrelationID   string                  `json:"correlationId"`
+	SoftwareModules []*SoftwareModuleAction `json:"softwareModules"`
+	Weight          int                     `json:"weight,omitempty"`
+	Metadata        map[string]string       `json:"metadata,omitempty"`
+	Forced          bool                    `json:"forced,omitempty"`
+}
+
+// SoftwareRemoveAction is the parameter of the remove and cancelRemove operations
+type SoftwareRemoveAction struct {
+	CorrelationID string                `json:"correlationId"`
+	Software      []*SoftwareDependency `json:"software"`
+	Weight        int                   `json:"weight,omitempty"`
+	Metadata      map[string]string     `json:"metadata,omitempty"`
+	Forced        bool                  `json:"forced,omitempty"`
+}
+
+// SoftwareCancelAction is the parameter of the cancel operation
+type SoftwareCancelAction struct {
+	CorrelationID  string            `json:"correlationId"`
+	SoftwareModule *SoftwareModuleID `json:"softwareModule,omitempty"`
+}
+
+// SoftwareOperationStatus is the progress of a software operation,
+// reported by the lastOperation and lastFailedOperation properties
+type SoftwareOperationStatus struct {
+	CorrelationID  string                `json:"correlationId"`
+	Status         SoftwareStatus        `json:"status"`
+	SoftwareModule *SoftwareModuleID     `json:"softwareModule,omitempty"`
+	Software       []*SoftwareDependency `json:"software,omitempty"`
+	Progress       int                   `json:"progress,omitempty"`
+	Message        string                `json:"message,omitempty"`
+	StatusCode     string                `json:"statusCode,omitempty"`
+}
+
+// SoftwareUpdatableClient executes the SoftwareUpdatable operations and follows their progress
+type SoftwareUpdatableClient struct {
+	*FeatureClient
+}
+
+// NewSoftwareUpdatableClient creates a client for the SoftwareUpdatable feature of the thing
+func NewSoftwareUpdatableClient(ctx context.Context, cfg *TestConfiguration,
+	thingID string) (*SoftwareUpdatableClient, error) {
+	client, err := NewFeatureClient(ctx, cfg, thingID, SoftwareUpdatableFeatureID)
+	if err != nil {
+		return nil, err
+	}
+	return &SoftwareUpdatableClient{FeatureClient: client}, nil
+}
+
+// NewSoftwareModuleAction creates a software module action with a single artifact downloaded over HTTPS.
+// The artifact file name is taken from the download URL.
+func NewSoftwareModuleAction(name string, version string, downloadURL string, sha256 string,
+	size int) *SoftwareModuleAction {
+	filename := name
+	if parsed, err := url.Parse(downloadURL); err == nil {
+		if base := path.Base(parsed.Path); base != "/" && base != "." {
+			filename = base
+		}
+	}
+	return &SoftwareModuleAction{
+		SoftwareModule: &SoftwareModuleID{Name: name, Version: version},
+		Artifacts: []*SoftwareArtifactAction{{
+			Filename:  filename,
+			Download:  map[string]*SoftwareLinks{"HTTPS": {URL: downloadURL}},
+			Checksums: map[string]string{"SHA256": sha256},
+			Size:      size,
+		}},
+	}
+}
+
+// Install installs the software modules and waits for the operation to finish.
+// The progress is reported to the optional callback. A random correlation ID is set if missing.
+// An error is returned along with the final status if the operation does not finish successfully.
+func (client *SoftwareUpdatableClient) Install(ctx context.Context, action *SoftwareUpdateAction,
+	progress func(*SoftwareOperationStatus)) (*SoftwareOperationStatus, error) {
+	action.CorrelationID = correlationIDOrRandom(action.CorrelationID)
+	return client.execute(ctx, softwareOperationInstall, action.CorrelationID, action,
+		SoftwareStatusFinishedSuccess, progress)
+}
+
+// Download downloads the software modules without installing them and waits for the operation to finish
+func (client *SoftwareUpdatableClient) Download(ctx context.Context, action *SoftwareUpdateAction,
+	progress func(*SoftwareOperationStatus)) (*SoftwareOperationStatus, error) {
+	action.CorrelationID = correlationIDOrRandom(action.CorrelationID)
+	return client.execute(ctx, softwareOperationDownload, action.CorrelationID, action,
+		SoftwareStatusFinishedSuccess, progress)
+}
+
+// Remove removes the installed software and waits for the operation to finish
+func (client *SoftwareUpdatableClient) Remove(ctx context.Context, action *SoftwareRemoveAction,
+	progress func(*SoftwareOperationStatus)) (*SoftwareOperationStatus, error) {
+	action.CorrelationID = correlationIDOrRandom(action.CorrelationID)
+	return client.execute(ctx, softwareOperationRemove, action.CorrelationID, action,
+		SoftwareStatusFinishedSuccess, progress)
+}
+
+// Cancel cancels the operation with the correlation ID of the action and waits for it to finish.
+// An error is returned if the operation is not canceled.
+func (client *SoftwareUpdatableClient) Cancel(ctx context.Context, action *SoftwareCancelAction,
+	progress func(*SoftwareOperationStatus)) (*SoftwareOperationStatus, error) {
+	if action.CorrelationID == "" {
+		return nil, errors.New("correlation ID of the operation to cancel is not specified")
+	}
+	return client.execute(ctx, softwareOperationCancel, action.CorrelationID, action, SoftwareStatusCanceled, progress)
+}
+
+// CancelRemove cancels the removal with the correlation ID of the action and waits for it to finish
+func (client *SoftwareUpdatableClient) CancelRemove(ctx context.Context, action *SoftwareRemoveAction,
+	progress func(*SoftwareOperationStatus)) (*SoftwareOperationStatus, error) {
+	if action.CorrelationID == "" {
+		return nil, errors.New("correlation ID of the removal to cancel is not specified")
+	}
+	return client.execute(ctx, softwareOperationCancelRemove, action.CorrelationID, action,
+		SoftwareStatusCanceled, progress)
+}
+
+// LastOperation retrieves the status of the last operation
+func (client *SoftwareUpdatableClient) LastOperation(ctx context.Context) (*SoftwareOperationStatus, error) {
+	status := &SoftwareOperationStatus{}
+	if err := client.GetProperty(ctx, softwarePropertyLastOperation, status); err != nil {
+		return nil, err
+	}
+	return status, nil
+}
+
+// LastFailedOperation retrieves the status of the last failed operation
+func (client *SoftwareUpdatableClient) LastFailedOperation(ctx context.Context) (*SoftwareOperationStatus, error) {
+	status := &SoftwareOperationStatus{}
+	if err := client.GetProperty(ctx, softwarePropertyLastFailedOperation, status); err != nil {
+		return nil, err
+	}
+	return status, nil
+}
+
+// InstalledDependencies retrieves the installed software keyed by the dependency IDs
+func (client *SoftwareUpdatableClient) InstalledDependencies(ctx context.Context) (
+	map[string]*SoftwareDependency, error) {
+	installed := map[string]*SoftwareDependency{}
+	if err := client.GetProperty(ctx, softwarePropertyInstalled, &installed); err != nil {
+		return nil, err
+	}
+	return installed, nil
+}
+
+func (client *SoftwareUpdatableClient) execute(ctx context.Context, operation string, correlationID string,
+	params interface{}, success SoftwareStatus, progress func(*SoftwareOperationStatus)) (*SoftwareOperationStatus, error) {
+	if _, err := client.ExecuteOperation(ctx, operation, params); err != nil {
+		return nil, err
+	}
+
+	var last *SoftwareOperationStatus
+	err := client.WatchProperty(ctx, softwarePropertyLastOperation, func(value interface{}) (bool, error) {
+		status := &SoftwareOperationStatus{}
+		if err := Convert(value, status); err != nil || status.CorrelationID != correlationID {
+			return false, nil
+		}
+		last = status
+		if progress != nil {
+			progress(status)
+		}
+		return status.Status.IsFinal(), nil
+	})
+	if err != nil {
+		return last, fmt.Errorf("%s operation %s not finished: %v", operation, correlationID, err)
+	}
+	if last.Status != success {
+		return last, fmt.Errorf("%s operation %s finished with status %s: %s",
+			operation, correlationID, last.Status, last.Message)
+	}
+	return last, nil
+}
+
+func correlationIDOrRandom(correlationID string) string {
+	if correlationID == "" {
+		return uuid.New().String()
+	}
+	return correlationID
+}
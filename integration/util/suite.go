@@ -13,7 +13,10 @@
 package util
 
 import (
+	"context"
+	"encoding/json"
 	"testing"
+	"time"
 
 	"github.com/caarlos0/env/v6"
 
@@ -21,9 +24,19 @@ import (
 
 	MQTT "github.com/eclipse/paho.mqtt.golang"
 
+	"github.com/eclipse/paho.golang/paho"
+
 	"github.com/stretchr/testify/require"
 )
 
+const (
+	// testDeadlineGrace is reserved before the test deadline for reporting failures and cleaning up
+	testDeadlineGrace = 5 * time.Second
+
+	// mqtt5ClientIDSuffix is appended to the configured client ID for the MQTT 5 client of the suite
+	mqtt5ClientIDSuffix = "-mqtt5"
+)
+
 // SuiteInitializer is testify Suite initialization helper
 type SuiteInitializer struct {
 	Cfg *TestConfiguration
@@ -32,18 +45,82 @@ type SuiteInitializer struct {
 
 	DittoClient *ditto.Client
 	MQTTClient  MQTT.Client
+
+	// MQTT5Client is only connected if MQTT 5 is selected by the test configuration.
+	// If a client ID is configured, it is used with the suffix "-mqtt5".
+	MQTT5Client *paho.Client
+
+	// LocalTwins is only connected if local digital twins are selected by the test configuration
+	LocalTwins *LocalTwins
+
+	// Recorder is only created if traffic recording is enabled by the test configuration.
+	// It is bound to the test running the setup, so the traffic of the shared connections is recorded to its file.
+	Recorder *TrafficRecorder
+
+	Fingerprint *EnvironmentFingerprint
+
+	// Report is only created if test reports are enabled by the test configuration
+	Report *TestReport
+}
+
+// NewTestContext returns a context, which expires shortly before the deadline of the test,
+// so operations fail with a proper error instead of hitting the go test timeout.
+// If the test has no deadline, the returned context can only be canceled.
+func NewTestContext(t *testing.T) (context.Context, context.CancelFunc) {
+	deadline, ok := t.Deadline()
+	if !ok {
+		return context.WithCancel(context.Background())
+	}
+	if time.Until(deadline) > 2*testDeadlineGrace {
+		deadline = deadline.Add(-testDeadlineGrace)
+	}
+	return context.WithDeadline(context.Background(), deadline)
+}
+
+// NewTestContext returns a context, which expires shortly before the deadline of the test.
+// If traffic recording is enabled, the traffic made with the context is recorded to a file of the test.
+// If test reports are enabled, the result of the test is added to the report of the suite.
+func (suite *SuiteInitializer) NewTestContext(t *testing.T) (context.Context, context.CancelFunc) {
+	suite.Report.Track(t)
+	ctx, cancel := NewTestContext(t)
+	recorder, err := suite.Recorder.Start(t)
+	if err != nil {
+		t.Logf("traffic of %s is not recorded: %v", t.Name(), err)
+	}
+	return WithTrafficRecorder(ctx, recorder), cancel
 }
 
-// Setup establishes connections to the local MQTT broker and Ditto
+// TailConnectorLog creates a tail of the suite connector log file from the test configuration.
+// If the test fails, the log entries appended during the test are attached to the test output.
+func (suite *SuiteInitializer) TailConnectorLog(t *testing.T) *LogTail {
+	return TailServiceLog(t, suite.Cfg.SuiteConnectorLogFile)
+}
+
+// Setup establishes connections to the local MQTT broker and Ditto.
+// If local digital twins are selected by the test configuration, the local twins of the device are connected as well.
 func (suite *SuiteInitializer) Setup(t *testing.T) {
+	ctx, cancel := NewTestContext(t)
+	defer cancel()
+
 	cfg := &TestConfiguration{}
 
 	opts := env.Options{RequiredIfNoDef: true}
 	require.NoError(t, env.Parse(cfg, opts), "failed to process environment variables")
 
-	t.Logf("%#v\n", cfg)
+	t.Logf("test configuration: %v", RedactConfiguration(cfg))
 
-	mqttClient, err := NewMQTTClient(cfg)
+	require.NoError(t, cfg.Validate(), "invalid test configuration")
+
+	if cfg.TrafficRecordDir != "" {
+		recorder, err := NewTrafficRecorder(cfg.TrafficRecordDir)
+		require.NoError(t, err, "initialize traffic recorder")
+		recorder, err = recorder.Start(t)
+		require.NoError(t, err, "start traffic recording")
+		suite.Recorder = recorder
+		ctx = WithTrafficRecorder(ctx, recorder)
+	}
+
+	mqttClient, err := NewMQTTClient(ctx, cfg)
 	require.NoError(t, err, "connect to MQTT broker")
 
 	dittoClient, err := ditto.NewClientMQTT(mqttClient, ditto.NewConfiguration())
@@ -59,15 +136,78 @@ func (suite *SuiteInitializer) Setup(t *testing.T) {
 	suite.Cfg = cfg
 	suite.DittoClient = dittoClient
 	suite.MQTTClient = mqttClient
-	suite.ThingCfg, err = GetThingConfiguration(cfg, mqttClient)
+
+	if cfg.MQTTVersion == MQTTVersion5 {
+		// Both clients stay connected, so a configured client ID must not be shared,
+		// otherwise the broker takes over the session of the other client on each reconnect
+		mqtt5Cfg := *cfg
+		if mqtt5Cfg.MQTTClientID != "" {
+			mqtt5Cfg.MQTTClientID += mqtt5ClientIDSuffix
+		}
+		suite.MQTT5Client, err = NewMQTT5Client(ctx, &mqtt5Cfg)
+		if err != nil {
+			defer suite.TearDown()
+			require.NoError(t, err, "connect to MQTT broker using MQTT 5")
+		}
+		suite.ThingCfg, err = GetThingConfigurationMQTT5(ctx, cfg, suite.MQTT5Client)
+	} else {
+		suite.ThingCfg, err = GetThingConfiguration(ctx, cfg, mqttClient)
+	}
 	if err != nil {
 		defer suite.TearDown()
 		require.NoError(t, err, "cannot get thing configuration")
 	}
+
+	if cfg.LocalDigitalTwins {
+		suite.LocalTwins, err = NewLocalTwins(ctx, cfg, suite.ThingCfg)
+		if err != nil {
+			defer suite.TearDown()
+			require.NoError(t, err, "connect to local digital twins")
+		}
+	}
+
+	suite.Fingerprint = CollectEnvironmentFingerprint(ctx, cfg, suite.ThingCfg)
+	if fingerprint, err := json.Marshal(suite.Fingerprint); err == nil {
+		t.Logf("environment: %s", fingerprint)
+	}
+
+	if cfg.TestReportDir != "" {
+		suite.Report = NewTestReport(t, suite.Fingerprint)
+		t.Cleanup(func() {
+			suite.Report.Complete(t)
+			if err := suite.Report.writeReports(cfg.TestReportDir, cfg.TestReportJUnit); err != nil {
+				t.Logf("unable to write test report: %v", err)
+			}
+		})
+	}
+}
+
+// NewTwinClient returns a client of the twin of the thing. If local digital twins are selected by the test
+// configuration, the local twin of the device is used instead of the Ditto one.
+func (suite *SuiteInitializer) NewTwinClient(thingID string) TwinClient {
+	if suite.LocalTwins != nil {
+		return suite.LocalTwins.Thing(thingID)
+	}
+	return NewThingClient(suite.Cfg, thingID)
+}
+
+// SubscribeForTwinEvents subscribes for the twin events of the thing. If local digital twins are selected
+// by the test configuration, the events are received from the local twin of the device instead of Ditto.
+func (suite *SuiteInitializer) SubscribeForTwinEvents(ctx context.Context, thingID string) (TwinEvents, error) {
+	if suite.LocalTwins != nil {
+		return suite.LocalTwins.SubscribeForTwinEvents(thingID), nil
+	}
+	return SubscribeForTwinEvents(ctx, suite.Cfg, thingID)
 }
 
 // TearDown closes all connections
 func (suite *SuiteInitializer) TearDown() {
+	if suite.LocalTwins != nil {
+		suite.LocalTwins.Close()
+	}
 	suite.DittoClient.Disconnect()
 	suite.MQTTClient.Disconnect(uint(suite.Cfg.MQTTQuiesceMS))
+	if suite.MQTT5Client != nil {
+		DisconnectMQTT5Client(suite.MQTT5Client)
+	}
 }
@@ -0,0 +1,127 @@
+// Copyright (c) 2026 Contributors to the Eclipse Foundation
+//
+// See the NOTICE file(s) distributed with this work for additional
+// information regarding copyright ownership.
+//
+// This program and the accompanying materials are made available under the
+// terms of the Eclipse Public License 2.0 which is available at
+// https://www.eclipse.org/legal/epl-2.0, or the Apache License, Version 2.0
+// which is available at https://www.apache.org/licenses/LICENSE-2.0.
+//
+// SPDX-License-Identifier: EPL-2.0 OR Apache-2.0
+
+package util
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/eclipse/ditto-clients-golang/model"
+)
+
+const (
+	attributeURLTemplate = "%s/attributes/%s"
+)
+
+// ThingClient is a Ditto REST API client bound to a single thing
+type ThingClient struct {
+	cfg *TestConfiguration
+
+	ThingID  string
+	ThingURL string
+}
+
+// NewThingClient creates a new client for the thing with the given ID
+func NewThingClient(cfg *TestConfiguration, thingID string) *ThingClient {
+	return &ThingClient{
+		cfg:      cfg,
+		ThingID:  thingID,
+		ThingURL: GetThingURL(cfg.DigitalTwinAPIAddress, thingID),
+	}
+}
+
+// FeatureURL returns the url of a feature of the thing
+func (client *ThingClient) FeatureURL(featureID string) string {
+	return GetFeatureURL(client.ThingURL, featureID)
+}
+
+// GetThing retrieves the whole thing
+func (client *ThingClient) GetThing(ctx context.Context) (map[string]interface{}, error) {
+	thing := map[string]interface{}{}
+	if err := client.get(ctx, client.ThingURL, &thing); err != nil {
+		return nil, err
+	}
+	return thing, nil
+}
+
+// GetAttribute retrieves an attribute of the thing and unmarshals it to the given value
+func (client *ThingClient) GetAttribute(ctx context.Context, attribute string, value interface{}) error {
+	return client.get(ctx, fmt.Sprintf(attributeURLTemplate, client.ThingURL, attribute), value)
+}
+
+// PutAttribute creates or modifies an attribute of the thing
+func (client *ThingClient) PutAttribute(ctx context.Context, attribute string, value interface{},
+	opts ...RequestOption) error {
+	url := fmt.Sprintf(attributeURLTemplate, client.ThingURL, attribute)
+	_, err := SendDigitalTwinRequest(ctx, client.cfg, http.MethodPut, url, value, opts...)
+	return err
+}
+
+// GetFeature retrieves a feature of the thing
+func (client *ThingClient) GetFeature(ctx context.Context, featureID string) (*model.Feature, error) {
+	feature := &model.Feature{}
+	if err := client.get(ctx, client.FeatureURL(featureID), feature); err != nil {
+		return nil, err
+	}
+	return feature, nil
+}
+
+// PutFeature creates or modifies a feature of the thing
+func (client *ThingClient) PutFeature(ctx context.Context, featureID string, feature *model.Feature,
+	opts ...RequestOption) error {
+	_, err := SendDigitalTwinRequest(ctx, client.cfg, http.MethodPut, client.FeatureURL(featureID), feature, opts...)
+	return err
+}
+
+// DeleteFeature deletes a feature of the thing
+func (client *ThingClient) DeleteFeature(ctx context.Context, featureID string) error {
+	_, err := SendDigitalTwinRequest(ctx, client.cfg, http.MethodDelete, client.FeatureURL(featureID), nil)
+	return err
+}
+
+// GetFeatureProperty retrieves a property of a feature and unmarshals it to the given value
+func (client *ThingClient) GetFeatureProperty(ctx context.Context, featureID string, property string,
+	value interface{}) error {
+	return client.get(ctx, fmt.Sprintf(featurePropertyURLTemplate, client.FeatureURL(featureID), property), value)
+}
+
+// PutFeatureProperty creates or modifies a property of a feature
+func (client *ThingClient) PutFeatureProperty(ctx context.Context, featureID string, property string,
+	value interface{}, opts ...RequestOption) error {
+	url := fmt.Sprintf(featurePropertyURLTemplate, client.FeatureURL(featureID), property)
+	_, err := SendDigitalTwinRequest(ctx, client.cfg, http.MethodPut, url, value, opts...)
+	return err
+}
+
+// DeleteFeatureProperty deletes a property of a feature
+func (client *ThingClient) DeleteFeatureProperty(ctx context.Context, featureID string, property string) error {
+	url := fmt.Sprintf(featurePropertyURLTemplate, client.FeatureURL(featureID), property)
+	_, err := SendDigitalTwinRequest(ctx, client.cfg, http.MethodDelete, url, nil)
+	return err
+}
+
+// ExecuteOperation executes an operation of a feature of the thing
+func (client *ThingClient) ExecuteOperation(ctx context.Context, featureID string, operation string,
+	params interface{}, opts ...RequestOption) ([]byte, error) {
+	return ExecuteOperation(ctx, client.cfg, client.FeatureURL(featureID), operation, params, opts...)
+}
+
+func (client *ThingClient) get(ctx context.Context, url string, value interface{}) error {
+	body, err := SendDigitalTwinRequest(ctx, client.cfg, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(body, value)
+}
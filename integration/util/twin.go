@@ -0,0 +1,75 @@
+// Copyright (c) 2026 Contributors to the Eclipse Foundation
+//
+// See the NOTICE file(s) distributed with this work for additional
+// information regarding copyright ownership.
+//
+// This program and the accompanying materials are made available under the
+// terms of the Eclipse Public License 2.0 which is available at
+// https://www.eclipse.org/legal/epl-2.0, or the Apache License, Version 2.0
+// which is available at https://www.apache.org/licenses/LICENSE-2.0.
+//
+// SPDX-License-Identifier: EPL-2.0 OR Apache-2.0
+
+package util
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/eclipse/ditto-clients-golang/model"
+	"github.com/eclipse/ditto-clients-golang/protocol"
+	"golang.org/x/net/websocket"
+)
+
+// TwinClient reads and modifies the twin of a single thing. It is implemented by ThingClient,
+// which uses the Ditto REST API, and by LocalThingClient, which uses the local digital twins of the device,
+// so the same assertions can be made online and offline.
+type TwinClient interface {
+	GetThing(ctx context.Context) (map[string]interface{}, error)
+	GetAttribute(ctx context.Context, attribute string, value interface{}) error
+	PutAttribute(ctx context.Context, attribute string, value interface{}, opts ...RequestOption) error
+	GetFeature(ctx context.Context, featureID string) (*model.Feature, error)
+	PutFeature(ctx context.Context, featureID string, feature *model.Feature, opts ...RequestOption) error
+	DeleteFeature(ctx context.Context, featureID string) error
+	GetFeatureProperty(ctx context.Context, featureID string, property string, value interface{}) error
+	PutFeatureProperty(ctx context.Context, featureID string, property string, value interface{},
+		opts ...RequestOption) error
+	DeleteFeatureProperty(ctx context.Context, featureID string, property string) error
+}
+
+// TwinEvents is a subscription for the twin events of a thing
+type TwinEvents interface {
+	// Process processes the twin events until the processing is finished,
+	// the WebSocket event timeout expires or the context is done
+	Process(ctx context.Context, process func(*protocol.Envelope) (bool, error)) error
+	// Close cancels the subscription
+	Close() error
+}
+
+// wsTwinEvents receives the twin events of a thing from a Ditto WebSocket session
+type wsTwinEvents struct {
+	cfg *TestConfiguration
+	ws  *websocket.Conn
+}
+
+// SubscribeForTwinEvents opens a WebSocket session to Ditto and subscribes for the twin events of the thing
+func SubscribeForTwinEvents(ctx context.Context, cfg *TestConfiguration, thingID string) (TwinEvents, error) {
+	ws, err := NewDigitalTwinWSConnection(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+	filter := fmt.Sprintf(thingEventsFilterTemplate, thingID)
+	if err := SubscribeForWSMessages(ctx, cfg, ws, StartSendEvents, filter); err != nil {
+		ws.Close()
+		return nil, fmt.Errorf("unable to subscribe for events of thing %s: %v", thingID, err)
+	}
+	return &wsTwinEvents{cfg: cfg, ws: ws}, nil
+}
+
+func (events *wsTwinEvents) Process(ctx context.Context, process func(*protocol.Envelope) (bool, error)) error {
+	return ProcessWSMessages(ctx, events.cfg, events.ws, process)
+}
+
+func (events *wsTwinEvents) Close() error {
+	return events.ws.Close()
+}
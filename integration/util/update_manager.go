@@ -0,0 +1,505 @@
+// Copyright (c) 2026 Contributors to the Eclipse Foundation
+//
+// See the NOTICE file(s) distributed with this work for additional
+// information regarding copyright ownership.
+//
+// This program and the accompanying materials are made available under the
+// terms of the Eclipse Public License 2.0 which is available at
+// https://www.eclipse.org/legal/epl-2.0, or the Apache License, Version 2.0
+// which is available at https://www.apache.org/licenses/LICENSE-2.0.
+//
+// SPDX-License-Identifier: EPL-2.0 OR Apache-2.0
+
+package util
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// UpdateStatus is the status of a desired state application
+type UpdateStatus string
+
+// UpdateActionStatus is the status of the action on a single component of a desired state
+type UpdateActionStatus string
+
+// UpdateCommandType is a command, which drives a phase of a desired state application
+type UpdateCommandType string
+
+const (
+	// UpdateManagerFeatureID is the ID of the UpdateManager feature
+	UpdateManagerFeatureID = "UpdateManager"
+
+	// UpdateStatusIdentifying is reported while the actions for a desired state are identified.
+	UpdateStatusIdentifying UpdateStatus = "IDENTIFYING"
+	// UpdateStatusIdentified is reported when the actions for a desired state are identified.
+	UpdateStatusIdentified UpdateStatus = "IDENTIFIED"
+	// UpdateStatusIdentificationFailed is reported when the actions for a desired state cannot be identified.
+	UpdateStatusIdentificationFailed UpdateStatus = "IDENTIFICATION_FAILED"
+	// UpdateStatusRunning is reported while the actions are executed.
+	UpdateStatusRunning UpdateStatus = "RUNNING"
+	// UpdateStatusCompleted is reported when all actions complete successfully.
+	UpdateStatusCompleted UpdateStatus = "COMPLETED"
+	// UpdateStatusIncomplete is reported when some actions fail and the previous state is restored.
+	UpdateStatusIncomplete UpdateStatus = "INCOMPLETE"
+	// UpdateStatusIncompleteInconsistent is reported when some actions fail and the state is inconsistent.
+	UpdateStatusIncompleteInconsistent UpdateStatus = "INCOMPLETE_INCONSISTENT"
+
+	// UpdateActionIdentified is reported when the action on a component is identified.
+	UpdateActionIdentified UpdateActionStatus = "IDENTIFIED"
+	// UpdateActionDownloading is reported while a component is downloaded.
+	UpdateActionDownloading UpdateActionStatus = "DOWNLOADING"
+	// UpdateActionDownloadSuccess is reported when a component is downloaded.
+	UpdateActionDownloadSuccess UpdateActionStatus = "DOWNLOAD_SUCCESS"
+	// UpdateActionDownloadFailure is reported when a component cannot be downloaded.
+	UpdateActionDownloadFailure UpdateActionStatus = "DOWNLOAD_FAILURE"
+	// UpdateActionUpdating is reported while a component is updated.
+	UpdateActionUpdating UpdateActionStatus = "UPDATING"
+	// UpdateActionUpdateSuccess is reported when a component is updated.
+	UpdateActionUpdateSuccess UpdateActionStatus = "UPDATE_SUCCESS"
+	// UpdateActionUpdateFailure is reported when a component cannot be updated.
+	UpdateActionUpdateFailure UpdateActionStatus = "UPDATE_FAILURE"
+	// UpdateActionActivating is reported while a component is activated.
+	UpdateActionActivating UpdateActionStatus = "ACTIVATING"
+	// UpdateActionActivationSuccess is reported when a component is activated.
+	UpdateActionActivationSuccess UpdateActionStatus = "ACTIVATION_SUCCESS"
+	// UpdateActionActivationFailure is reported when a component cannot be activated.
+	UpdateActionActivationFailure UpdateActionStatus = "ACTIVATION_FAILURE"
+	// UpdateActionRemoving is reported while a component is removed.
+	UpdateActionRemoving UpdateActionStatus = "REMOVING"
+	// UpdateActionRemovalSuccess is reported when a component is removed.
+	UpdateActionRemovalSuccess UpdateActionStatus = "REMOVAL_SUCCESS"
+	// UpdateActionRemovalFailure is reported when a component cannot be removed.
+	UpdateActionRemovalFailure UpdateActionStatus = "REMOVAL_FAILURE"
+
+	// UpdateCommandDownload starts downloading the components of a baseline.
+	UpdateCommandDownload UpdateCommandType = "DOWNLOAD"
+	// UpdateCommandUpdate starts updating the components of a baseline.
+	UpdateCommandUpdate UpdateCommandType = "UPDATE"
+	// UpdateCommandActivate starts activating the components of a baseline.
+	UpdateCommandActivate UpdateCommandType = "ACTIVATE"
+	// UpdateCommandRollback rolls back the components of a baseline.
+	UpdateCommandRollback UpdateCommandType = "ROLLBACK"
+	// UpdateCommandCancel cancels the application of a desired state.
+	UpdateCommandCancel UpdateCommandType = "CANCEL"
+	// UpdateCommandCleanup cleans up after the application of a desired state.
+	UpdateCommandCleanup UpdateCommandType = "CLEANUP"
+
+	updateOperationApply   = "apply"
+	updateOperationRefresh = "refresh"
+	updateOperationCommand = "command"
+
+	updatePropertyLastOperation = "lastOperation"
+	updatePropertyCurrentState  = "currentState"
+
+	// componentIDSeparator separates the domain from the component ID in the feedback, e.g. containers:influxdb
+	componentIDSeparator = ":"
+)
+
+// updatePhaseOutcomes are the action statuses, which complete the phase started by a command
+var updatePhaseOutcomes = map[UpdateCommandType][]UpdateActionStatus{
+	UpdateCommandDownload: {UpdateActionDownloadSuccess, UpdateActionDownloadFailure},
+	UpdateCommandUpdate:   {UpdateActionUpdateSuccess, UpdateActionUpdateFailure},
+	UpdateCommandActivate: {UpdateActionActivationSuccess, UpdateActionActivationFailure},
+}
+
+// IsFinal returns true if no more feedback is reported for a desired state with the status
+func (status UpdateStatus) IsFinal() bool {
+	switch status {
+	case UpdateStatusCompleted, UpdateStatusIncomplete, UpdateStatusIncompleteInconsistent,
+		UpdateStatusIdentificationFailed:
+		return true
+	}
+	return false
+}
+
+// IsFailure returns true if the action on a component has failed
+func (status UpdateActionStatus) IsFailure() bool {
+	return strings.HasSuffix(string(status), "_FAILURE")
+}
+
+// KeyValuePair is a configuration entry of a domain or a component
+type KeyValuePair struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+// UpdateComponent identifies a component with its version
+type UpdateComponent struct {
+	ID      string `json:"id"`
+	Version string `json:"version"`
+}
+
+// DesiredStateComponent is a component of a domain with its configuration
+type DesiredStateComponent struct {
+	ID      string          `json:"id"`
+	Version string          `json:"version"`
+	Config  []*KeyValuePair `json:"config,omitempty"`
+}
+
+// DesiredStateDomain is the desired state of a single domain, e.g. containers
+type DesiredStateDomain struct {
+	ID         string                   `json:"id"`
+	Config     []*KeyValuePair          `json:"config,omitempty"`
+	Components []*DesiredStateComponent `json:"components"`
+}
+
+// DesiredStateBaseline groups domain or cross-domain components, which are updated together.
+// The components are referenced as <domain-id>:<component-id>.
+type DesiredStateBaseline struct {
+	Title         string   `json:"title"`
+	Description   string   `json:"description,omitempty"`
+	Preconditions string   `json:"preconditions,omitempty"`
+	Components    []string `json:"components,omitempty"`
+}
+
+// DesiredState is the desired state document applied by the update manager
+type DesiredState struct {
+	Baselines []*DesiredStateBaseline `json:"baselines,omitempty"`
+	Domains   []*DesiredStateDomain   `json:"domains"`
+}
+
+// ParseDesiredState strictly decodes a desired state document and validates it.
+// Unknown fields are rejected, so misspelled fields are detected before the desired state is sent.
+func ParseDesiredState(data []byte) (*DesiredState, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+
+	desiredState := &DesiredState{}
+	if err := decoder.Decode(desiredState); err != nil {
+		return nil, fmt.Errorf("invalid desired state JSON: %v", err)
+	}
+	if decoder.More() {
+		return nil, errors.New("invalid desired state JSON: unexpected data after the document")
+	}
+	if err := desiredState.Validate(); err != nil {
+		return nil, err
+	}
+	return desiredState, nil
+}
+
+// Validate checks that the domains, the components and the configuration entries have unique non-empty IDs,
+// that the components have versions and that the baselines reference existing components.
+// All violations are returned together.
+func (desiredState *DesiredState) Validate() error {
+	var errs []error
+	components := map[string]bool{}
+
+	domains := map[string]bool{}
+	for i, domain := range desiredState.Domains {
+		if domain == nil || domain.ID == "" {
+			errs = append(errs, fmt.Errorf("domains[%d]: missing id", i))
+			continue
+		}
+		if domains[domain.ID] {
+			errs = append(errs, fmt.Errorf("domain %s: duplicate id", domain.ID))
+		}
+		domains[domain.ID] = true
+		errs = append(errs, validateKeyValuePairs("domain "+domain.ID, domain.Config)...)
+
+		for j, component := range domain.Components {
+			if component == nil || component.ID == "" {
+				errs = append(errs, fmt.Errorf("domain %s: components[%d]: missing id", domain.ID, j))
+				continue
+			}
+			name := domain.ID + componentIDSeparator + component.ID
+			if components[name] {
+				errs = append(errs, fmt.Errorf("component %s: duplicate id", name))
+			}
+			components[name] = true
+			if component.Version == "" {
+				errs = append(errs, fmt.Errorf("component %s: missing version", name))
+			}
+			errs = append(errs, validateKeyValuePairs("component "+name, component.Config)...)
+		}
+	}
+
+	baselines := map[string]bool{}
+	for i, baseline := range desiredState.Baselines {
+		if baseline == nil || baseline.Title == "" {
+			errs = append(errs, fmt.Errorf("baselines[%d]: missing title", i))
+			continue
+		}
+		if baselines[baseline.Title] {
+			errs = append(errs, fmt.Errorf("baseline %s: duplicate title", baseline.Title))
+		}
+		baselines[baseline.Title] = true
+		for _, component := range baseline.Components {
+			if !components[component] {
+				errs = append(errs, fmt.Errorf("baseline %s: unknown component %s", baseline.Title, component))
+			}
+		}
+	}
+
+	if len(errs) > 0 {
+		messages := make([]string, len(errs))
+		for i, err := range errs {
+			messages[i] = err.Error()
+		}
+		return fmt.Errorf("invalid desired state: %s", strings.Join(messages, "; "))
+	}
+	return nil
+}
+
+func validateKeyValuePairs(owner string, pairs []*KeyValuePair) []error {
+	var errs []error
+	keys := map[string]bool{}
+	for i, pair := range pairs {
+		if pair == nil || pair.Key == "" {
+			errs = append(errs, fmt.Errorf("%s: config[%d]: missing key", owner, i))
+			continue
+		}
+		if keys[pair.Key] {
+			errs = append(errs, fmt.Errorf("%s: config %s: duplicate key", owner, pair.Key))
+		}
+		keys[pair.Key] = true
+	}
+	return errs
+}
+
+// UpdateAction is the feedback for the action on a single component
+type UpdateAction struct {
+	Component *UpdateComponent   `json:"component"`
+	Status    UpdateActionStatus `json:"status"`
+	Progress  int                `json:"progress,omitempty"`
+	Message   string             `json:"message,omitempty"`
+}
+
+// DesiredStateFeedback is the progress of a desired state application
+type DesiredStateFeedback struct {
+	Status  UpdateStatus    `json:"status"`
+	Message string          `json:"message,omitempty"`
+	Actions []*UpdateAction `json:"actions,omitempty"`
+}
+
+// UpdateOperation is the last desired state feedback reported by the lastOperation property
+type UpdateOperation struct {
+	ActivityID           string                `json:"activityId"`
+	Timestamp            int64                 `json:"timestamp,omitempty"`
+	DesiredStateFeedback *DesiredStateFeedback `json:"desiredStateFeedback"`
+}
+
+// UpdateCurrentState is the inventory of the device reported by the currentState property
+type UpdateCurrentState struct {
+	ActivityID string                 `json:"activityId"`
+	Timestamp  int64                  `json:"timestamp,omitempty"`
+	Inventory  map[string]interface{} `json:"inventory,omitempty"`
+}
+
+// DesiredStateCommand drives a phase of the application of a desired state for a baseline
+type DesiredStateCommand struct {
+	Command  UpdateCommandType `json:"command"`
+	Baseline string            `json:"baseline,omitempty"`
+}
+
+// UpdateOutcome is the outcome of a desired state application with the last action on each component
+type UpdateOutcome struct {
+	ActivityID string
+	Status     UpdateStatus
+	Message    string
+
+	// Domains holds the last action of each component keyed by the domain ID and the component ID
+	Domains map[string]map[string]*UpdateAction
+}
+
+// Failures returns the actions, which have failed
+func (outcome *UpdateOutcome) Failures() []*UpdateAction {
+	var failures []*UpdateAction
+	for _, components := range outcome.Domains {
+		for _, action := range components {
+			if action.Status.IsFailure() {
+				failures = append(failures, action)
+			}
+		}
+	}
+	return failures
+}
+
+// String formats the outcome with a line per component
+func (outcome *UpdateOutcome) String() string {
+	var builder strings.Builder
+	fmt.Fprintf(&builder, "activity %s: %s", outcome.ActivityID, outcome.Status)
+	if outcome.Message != "" {
+		fmt.Fprintf(&builder, " (%s)", outcome.Message)
+	}
+	var lines []string
+	for domain, components := range outcome.Domains {
+		for component, action := range components {
+			line := fmt.Sprintf("\n  %s/%s: %s", domain, component, action.Status)
+			if action.Message != "" {
+				line += " " + action.Message
+			}
+			lines = append(lines, line)
+		}
+	}
+	sort.Strings(lines)
+	builder.WriteString(strings.Join(lines, ""))
+	return builder.String()
+}
+
+func newUpdateOutcome(operation *UpdateOperation) *UpdateOutcome {
+	outcome := &UpdateOutcome{
+		ActivityID: operation.ActivityID,
+		Domains:    map[string]map[string]*UpdateAction{},
+	}
+	if operation.DesiredStateFeedback == nil {
+		return outcome
+	}
+	outcome.Status = operation.DesiredStateFeedback.Status
+	outcome.Message = operation.DesiredStateFeedback.Message
+	for _, action := range operation.DesiredStateFeedback.Actions {
+		if action.Component == nil {
+			continue
+		}
+		domain, component := "", action.Component.ID
+		if i := strings.Index(component, componentIDSeparator); i >= 0 {
+			domain, component = component[:i], component[i+1:]
+		}
+		if outcome.Domains[domain] == nil {
+			outcome.Domains[domain] = map[string]*UpdateAction{}
+		}
+		outcome.Domains[domain][component] = action
+	}
+	return outcome
+}
+
+// UpdateManagerClient applies desired states with the UpdateManager feature and follows their feedback
+type UpdateManagerClient struct {
+	*FeatureClient
+}
+
+// NewUpdateManagerClient creates a client for the UpdateManager feature of the thing
+func NewUpdateManagerClient(ctx context.Context, cfg *TestConfiguration, thingID string) (*UpdateManagerClient, error) {
+	client, err := NewFeatureClient(ctx, cfg, thingID, UpdateManagerFeatureID)
+	if err != nil {
+		return nil, err
+	}
+	return &UpdateManagerClient{FeatureClient: client}, nil
+}
+
+// Apply validates and applies the desired state and waits for the application to finish.
+// The feedback is reported to the optional callback. A random activity ID is used if the given one is empty.
+// An error is returned along with the outcome if the desired state is not applied completely.
+func (client *UpdateManagerClient) Apply(ctx context.Context, activityID string, desiredState *DesiredState,
+	progress func(*UpdateOperation)) (*UpdateOutcome, error) {
+	if err := desiredState.Validate(); err != nil {
+		return nil, err
+	}
+	activityID = correlationIDOrRandom(activityID)
+	params := map[string]interface{}{"activityId": activityID, "desiredState": desiredState}
+	if _, err := client.ExecuteOperation(ctx, updateOperationApply, params); err != nil {
+		return nil, err
+	}
+
+	outcome, err := client.watchFeedback(ctx, activityID, progress, func(operation *UpdateOperation) bool {
+		return operation.DesiredStateFeedback.Status.IsFinal()
+	})
+	if err != nil {
+		return outcome, fmt.Errorf("desired state %s not applied: %v", activityID, err)
+	}
+	if outcome.Status != UpdateStatusCompleted {
+		return outcome, fmt.Errorf("desired state not applied completely, %v", outcome)
+	}
+	return outcome, nil
+}
+
+// Command sends a command for the desired state with the activity ID and waits for the phase started by it
+// to complete for all components. Rollback, cancel and cleanup commands wait for the application to finish.
+// An error is returned along with the outcome if an action fails.
+func (client *UpdateManagerClient) Command(ctx context.Context, activityID string, command *DesiredStateCommand,
+	progress func(*UpdateOperation)) (*UpdateOutcome, error) {
+	params := map[string]interface{}{"activityId": activityID, "command": command}
+	if _, err := client.ExecuteOperation(ctx, updateOperationCommand, params); err != nil {
+		return nil, err
+	}
+
+	outcomes := updatePhaseOutcomes[command.Command]
+	outcome, err := client.watchFeedback(ctx, activityID, progress, func(operation *UpdateOperation) bool {
+		feedback := operation.DesiredStateFeedback
+		if feedback.Status.IsFinal() {
+			return true
+		}
+		if len(outcomes) == 0 || len(feedback.Actions) == 0 {
+			return false
+		}
+		for _, action := range feedback.Actions {
+			if action.Status != outcomes[0] && action.Status != outcomes[1] {
+				return false
+			}
+		}
+		return true
+	})
+	if err != nil {
+		return outcome, fmt.Errorf("%s command for %s not completed: %v", command.Command, activityID, err)
+	}
+	if failures := outcome.Failures(); len(failures) > 0 ||
+		(outcome.Status.IsFinal() && outcome.Status != UpdateStatusCompleted) {
+		return outcome, fmt.Errorf("%s command failed, %v", command.Command, outcome)
+	}
+	return outcome, nil
+}
+
+// Refresh requests the current state of the device and waits for it to be reported.
+// A random activity ID is used if the given one is empty.
+func (client *UpdateManagerClient) Refresh(ctx context.Context, activityID string) (*UpdateCurrentState, error) {
+	activityID = correlationIDOrRandom(activityID)
+	params := map[string]interface{}{"activityId": activityID}
+	if _, err := client.ExecuteOperation(ctx, updateOperationRefresh, params); err != nil {
+		return nil, err
+	}
+
+	var currentState *UpdateCurrentState
+	err := client.WatchProperty(ctx, updatePropertyCurrentState, func(value interface{}) (bool, error) {
+		state := &UpdateCurrentState{}
+		if err := Convert(value, state); err != nil || state.ActivityID != activityID {
+			return false, nil
+		}
+		currentState = state
+		return true, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("current state for %s not reported: %v", activityID, err)
+	}
+	return currentState, nil
+}
+
+// LastOperation retrieves the last desired state feedback
+func (client *UpdateManagerClient) LastOperation(ctx context.Context) (*UpdateOperation, error) {
+	operation := &UpdateOperation{}
+	if err := client.GetProperty(ctx, updatePropertyLastOperation, operation); err != nil {
+		return nil, err
+	}
+	return operation, nil
+}
+
+// CurrentState retrieves the last reported current state of the device
+func (client *UpdateManagerClient) CurrentState(ctx context.Context) (*UpdateCurrentState, error) {
+	state := &UpdateCurrentState{}
+	if err := client.GetProperty(ctx, updatePropertyCurrentState, state); err != nil {
+		return nil, err
+	}
+	return state, nil
+}
+
+func (client *UpdateManagerClient) watchFeedback(ctx context.Context, activityID string,
+	progress func(*UpdateOperation), done func(*UpdateOperation) bool) (*UpdateOutcome, error) {
+	var outcome *UpdateOutcome
+	err := client.WatchProperty(ctx, updatePropertyLastOperation, func(value interface{}) (bool, error) {
+		operation := &UpdateOperation{}
+		if err := Convert(value, operation); err != nil || operation.ActivityID != activityID ||
+			operation.DesiredStateFeedback == nil {
+			return false, nil
+		}
+		outcome = newUpdateOutcome(operation)
+		if progress != nil {
+			progress(operation)
+		}
+		return done(operation), nil
+	})
+	return outcome, err
+}
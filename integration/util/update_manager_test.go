@@ -0,0 +1,298 @@
+// Copyright (c) 2026 Contributors to the Eclipse Foundation
+//
+// See the NOTICE file(s) distributed with this work for additional
+// information regarding copyright ownership.
+//
+// This program and the accompanying materials are made available under the
+// terms of the Eclipse Public License 2.0 which is available at
+// https://www.eclipse.org/legal/epl-2.0, or the Apache License, Version 2.0
+// which is available at https://www.apache.org/licenses/LICENSE-2.0.
+//
+// SPDX-License-Identifier: EPL-2.0 OR Apache-2.0
+
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const testDesiredState = `{
+	"baselines": [
+		{"title": "monitoring", "components": ["containers:influxdb", "containers:telegraf"]}
+	],
+	"domains": [
+		{
+			"id": "containers",
+			"config": [{"key": "source", "value": "registry"}],
+			"components": [
+				{"id": "influxdb", "version": "2.7.1", "config": [{"key": "image", "value": "influxdb:2.7.1"}]},
+				{"id": "telegraf", "version": "1.28"}
+			]
+		},
+		{"id": "self-update", "components": [{"id": "os", "version": "1.0"}]}
+	]
+}`
+
+func TestParseDesiredState(t *testing.T) {
+	desiredState, err := ParseDesiredState([]byte(testDesiredState))
+	require.NoError(t, err)
+	require.Len(t, desiredState.Domains, 2)
+	require.Equal(t, "containers", desiredState.Domains[0].ID)
+	require.Equal(t, &DesiredStateComponent{
+		ID:      "influxdb",
+		Version: "2.7.1",
+		Config:  []*KeyValuePair{{Key: "image", Value: "influxdb:2.7.1"}},
+	}, desiredState.Domains[0].Components[0])
+	require.Equal(t, []string{"containers:influxdb", "containers:telegraf"}, desiredState.Baselines[0].Components)
+
+	tests := []struct {
+		name string
+		data string
+		err  string
+	}{
+		{
+			name: "invalid JSON",
+			data: `{"domains": [`,
+			err:  "invalid desired state JSON: unexpected EOF",
+		},
+		{
+			name: "unknown field",
+			data: `{"domains": [{"id": "containers", "component": []}]}`,
+			err:  `invalid desired state JSON: json: unknown field "component"`,
+		},
+		{
+			name: "data after the document",
+			data: `{"domains": []} {}`,
+			err:  "invalid desired state JSON: unexpected data after the document",
+		},
+		{
+			name: "invalid desired state",
+			data: `{"domains": [{"id": "containers", "components": [{"id": "influxdb"}]}]}`,
+			err:  "invalid desired state: component containers:influxdb: missing version",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			desiredState, err := ParseDesiredState([]byte(test.data))
+			require.EqualError(t, err, test.err)
+			require.Nil(t, desiredState)
+		})
+	}
+}
+
+func TestDesiredStateValidate(t *testing.T) {
+	component := func(id string, config ...*KeyValuePair) *DesiredStateComponent {
+		return &DesiredStateComponent{ID: id, Version: "1.0", Config: config}
+	}
+	pair := func(key string) *KeyValuePair {
+		return &KeyValuePair{Key: key, Value: "value"}
+	}
+
+	tests := []struct {
+		name         string
+		desiredState *DesiredState
+		err          string
+	}{
+		{
+			name:         "empty",
+			desiredState: &DesiredState{},
+		},
+		{
+			name: "valid",
+			desiredState: &DesiredState{
+				Baselines: []*DesiredStateBaseline{{Title: "all", Components: []string{"a:x", "b:x"}}},
+				Domains: []*DesiredStateDomain{
+					{ID: "a", Config: []*KeyValuePair{pair("k")}, Components: []*DesiredStateComponent{component("x", pair("k"))}},
+					{ID: "b", Components: []*DesiredStateComponent{component("x")}},
+				},
+			},
+		},
+		{
+			name: "duplicate domain",
+			desiredState: &DesiredState{Domains: []*DesiredStateDomain{
+				{ID: "a"}, {ID: "a"},
+			}},
+			err: "invalid desired state: domain a: duplicate id",
+		},
+		{
+			name: "missing domain id",
+			desiredState: &DesiredState{Domains: []*DesiredStateDomain{
+				{ID: "a"}, nil, {},
+			}},
+			err: "invalid desired state: domains[1]: missing id; domains[2]: missing id",
+		},
+		{
+			name: "duplicate component",
+			desiredState: &DesiredState{Domains: []*DesiredStateDomain{
+				{ID: "a", Components: []*DesiredStateComponent{component("x"), component("y"), component("x")}},
+			}},
+			err: "invalid desired state: component a:x: duplicate id",
+		},
+		{
+			name: "missing component id",
+			desiredState: &DesiredState{Domains: []*DesiredStateDomain{
+				{ID: "a", Components: []*DesiredStateComponent{{Version: "1.0"}}},
+			}},
+			err: "invalid desired state: domain a: components[0]: missing id",
+		},
+		{
+			name: "missing version",
+			desiredState: &DesiredState{Domains: []*DesiredStateDomain{
+				{ID: "a", Components: []*DesiredStateComponent{{ID: "x"}}},
+			}},
+			err: "invalid desired state: component a:x: missing version",
+		},
+		{
+			name: "duplicate domain config key",
+			desiredState: &DesiredState{Domains: []*DesiredStateDomain{
+				{ID: "a", Config: []*KeyValuePair{pair("k"), pair("k")}},
+			}},
+			err: "invalid desired state: domain a: config k: duplicate key",
+		},
+		{
+			name: "duplicate component config key",
+			desiredState: &DesiredState{Domains: []*DesiredStateDomain{
+				{ID: "a", Components: []*DesiredStateComponent{component("x", pair("k"), pair(""), pair("k"))}},
+			}},
+			err: "invalid desired state: component a:x: config[1]: missing key; component a:x: config k: duplicate key",
+		},
+		{
+			name: "baseline with unknown component",
+			desiredState: &DesiredState{
+				Baselines: []*DesiredStateBaseline{{Title: "all", Components: []string{"a:x", "a:y", "x"}}},
+				Domains:   []*DesiredStateDomain{{ID: "a", Components: []*DesiredStateComponent{component("x")}}},
+			},
+			err: "invalid desired state: baseline all: unknown component a:y; baseline all: unknown component x",
+		},
+		{
+			name: "duplicate baseline",
+			desiredState: &DesiredState{
+				Baselines: []*DesiredStateBaseline{{Title: "all"}, {Title: "all"}, {}},
+			},
+			err: "invalid desired state: baseline all: duplicate title; baselines[2]: missing title",
+		},
+		{
+			name: "all violations",
+			desiredState: &DesiredState{
+				Baselines: []*DesiredStateBaseline{{Title: "all", Components: []string{"b:x"}}},
+				Domains: []*DesiredStateDomain{
+					{ID: "a", Components: []*DesiredStateComponent{{ID: "x"}}},
+					{ID: "a"},
+				},
+			},
+			err: "invalid desired state: component a:x: missing version; domain a: duplicate id; " +
+				"baseline all: unknown component b:x",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := test.desiredState.Validate()
+			if test.err == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.EqualError(t, err, test.err)
+		})
+	}
+}
+
+func TestNewUpdateOutcome(t *testing.T) {
+	action := func(componentID string, status UpdateActionStatus) *UpdateAction {
+		return &UpdateAction{Component: &UpdateComponent{ID: componentID, Version: "1.0"}, Status: status}
+	}
+
+	tests := []struct {
+		name      string
+		operation *UpdateOperation
+		status    UpdateStatus
+		domains   map[string]map[string]UpdateActionStatus
+		failures  int
+	}{
+		{
+			name:      "no feedback",
+			operation: &UpdateOperation{ActivityID: "activity"},
+			domains:   map[string]map[string]UpdateActionStatus{},
+		},
+		{
+			name: "actions per domain and component",
+			operation: &UpdateOperation{ActivityID: "activity", DesiredStateFeedback: &DesiredStateFeedback{
+				Status: UpdateStatusRunning,
+				Actions: []*UpdateAction{
+					action("containers:influxdb", UpdateActionUpdateSuccess),
+					action("containers:telegraf", UpdateActionUpdating),
+					action("self-update:os", UpdateActionDownloading),
+				},
+			}},
+			status: UpdateStatusRunning,
+			domains: map[string]map[string]UpdateActionStatus{
+				"containers":  {"influxdb": UpdateActionUpdateSuccess, "telegraf": UpdateActionUpdating},
+				"self-update": {"os": UpdateActionDownloading},
+			},
+		},
+		{
+			name: "last action of a component",
+			operation: &UpdateOperation{ActivityID: "activity", DesiredStateFeedback: &DesiredStateFeedback{
+				Status: UpdateStatusIncomplete,
+				Actions: []*UpdateAction{
+					action("containers:influxdb", UpdateActionUpdating),
+					action("containers:influxdb", UpdateActionUpdateFailure),
+				},
+			}},
+			status: UpdateStatusIncomplete,
+			domains: map[string]map[string]UpdateActionStatus{
+				"containers": {"influxdb": UpdateActionUpdateFailure},
+			},
+			failures: 1,
+		},
+		{
+			name: "component without domain",
+			operation: &UpdateOperation{ActivityID: "activity", DesiredStateFeedback: &DesiredStateFeedback{
+				Status: UpdateStatusCompleted,
+				Actions: []*UpdateAction{
+					action("influxdb", UpdateActionRemovalFailure),
+					action("containers:registry:5000", UpdateActionActivationSuccess),
+					{Status: UpdateActionUpdateFailure},
+				},
+			}},
+			status: UpdateStatusCompleted,
+			domains: map[string]map[string]UpdateActionStatus{
+				"":           {"influxdb": UpdateActionRemovalFailure},
+				"containers": {"registry:5000": UpdateActionActivationSuccess},
+			},
+			failures: 1,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			outcome := newUpdateOutcome(test.operation)
+			require.Equal(t, test.operation.ActivityID, outcome.ActivityID)
+			require.Equal(t, test.status, outcome.Status)
+
+			domains := map[string]map[string]UpdateActionStatus{}
+			for domain, components := range outcome.Domains {
+				domains[domain] = map[string]UpdateActionStatus{}
+				for component, action := range components {
+					domains[domain][component] = action.Status
+				}
+			}
+			require.Equal(t, test.domains, domains)
+			require.Len(t, outcome.Failures(), test.failures)
+		})
+	}
+}
+
+func TestUpdateOutcomeString(t *testing.T) {
+	outcome := newUpdateOutcome(&UpdateOperation{ActivityID: "activity", DesiredStateFeedback: &DesiredStateFeedback{
+		Status:  UpdateStatusIncomplete,
+		Message: "rolled back",
+		Actions: []*UpdateAction{
+			{Component: &UpdateComponent{ID: "containers:telegraf"}, Status: UpdateActionUpdateSuccess},
+			{Component: &UpdateComponent{ID: "containers:influxdb"}, Status: UpdateActionUpdateFailure, Message: "no image"},
+		},
+	}})
+	require.Equal(t, "activity activity: INCOMPLETE (rolled back)"+
+		"\n  containers/influxdb: UPDATE_FAILURE no image"+
+		"\n  containers/telegraf: UPDATE_SUCCESS", outcome.String())
+}
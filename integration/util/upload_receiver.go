@@ -0,0 +1,199 @@
+// Copyright (c) 2026 Contributors to the Eclipse Foundation
+//
+// See the NOTICE file(s) distributed with this work for additional
+// information regarding copyright ownership.
+//
+// This program and the accompanying materials are made available under the
+// terms of the Eclipse Public License 2.0 which is available at
+// https://www.eclipse.org/legal/epl-2.0, or the Apache License, Version 2.0
+// which is available at https://www.apache.org/licenses/LICENSE-2.0.
+//
+// SPDX-License-Identifier: EPL-2.0 OR Apache-2.0
+
+package util
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+)
+
+const uploadReceiverPathPrefix = "/uploads/"
+
+// ReceivedUpload is a file received by the upload receiver
+type ReceivedUpload struct {
+	// Name is the last element of the upload URL path, e.g. the correlation ID of the upload request
+	Name    string
+	Method  string
+	Header  http.Header
+	Content []byte
+	// SHA256 is the hex encoded SHA-256 checksum of the content
+	SHA256 string
+}
+
+// UploadReceiver is a local HTTP(S) server, which receives the files uploaded with the generic storage provider,
+// so the uploaded content can be verified
+type UploadReceiver struct {
+	server *httptest.Server
+	secure bool
+
+	mutex   sync.Mutex
+	uploads map[string]*ReceivedUpload
+	changed chan struct{}
+	status  int
+}
+
+// StartUploadReceiver starts an upload receiver listening on the address, e.g. "127.0.0.1:0" for a random local port.
+// If secure is true, HTTPS is served with a self-signed certificate, which is returned by CACert.
+func StartUploadReceiver(address string, secure bool) (*UploadReceiver, error) {
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return nil, fmt.Errorf("unable to listen on %s: %v", address, err)
+	}
+
+	receiver := &UploadReceiver{
+		secure:  secure,
+		uploads: map[string]*ReceivedUpload{},
+		changed: make(chan struct{}),
+		status:  http.StatusOK,
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc(uploadReceiverPathPrefix, receiver.handleUpload)
+
+	receiver.server = httptest.NewUnstartedServer(mux)
+	receiver.server.Listener.Close()
+	receiver.server.Listener = listener
+	if secure {
+		receiver.server.StartTLS()
+	} else {
+		receiver.server.Start()
+	}
+	return receiver, nil
+}
+
+// URL returns the base URL of the receiver
+func (receiver *UploadReceiver) URL() string {
+	return receiver.server.URL
+}
+
+// UploadURL returns the URL, which the file with the name is to be uploaded to
+func (receiver *UploadReceiver) UploadURL(name string) string {
+	return receiver.server.URL + uploadReceiverPathPrefix + url.PathEscape(name)
+}
+
+// UploadOptions returns the generic storage provider options, which upload a file with the name to the receiver
+func (receiver *UploadReceiver) UploadOptions(name string) *UploadOptions {
+	return &UploadOptions{
+		StorageProvider: UploadProviderGeneric,
+		URL:             receiver.UploadURL(name),
+		Method:          http.MethodPut,
+	}
+}
+
+// CACert returns the PEM encoded certificate of the receiver, which is to be trusted by the device.
+// Nil is returned if the receiver does not serve HTTPS.
+func (receiver *UploadReceiver) CACert() []byte {
+	if !receiver.secure {
+		return nil
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: receiver.server.Certificate().Raw})
+}
+
+// SetResponseStatus sets the status code returned for the uploads, e.g. to make the uploads fail
+func (receiver *UploadReceiver) SetResponseStatus(status int) {
+	receiver.mutex.Lock()
+	defer receiver.mutex.Unlock()
+	receiver.status = status
+}
+
+// Upload returns the last file received with the name
+func (receiver *UploadReceiver) Upload(name string) (*ReceivedUpload, bool) {
+	receiver.mutex.Lock()
+	defer receiver.mutex.Unlock()
+	upload, ok := receiver.uploads[name]
+	return upload, ok
+}
+
+// WaitForUpload waits until a file with the name is received or the context is done
+func (receiver *UploadReceiver) WaitForUpload(ctx context.Context, name string) (*ReceivedUpload, error) {
+	for {
+		receiver.mutex.Lock()
+		upload, ok := receiver.uploads[name]
+		changed := receiver.changed
+		receiver.mutex.Unlock()
+		if ok {
+			return upload, nil
+		}
+
+		select {
+		case <-changed:
+		case <-ctx.Done():
+			return nil, fmt.Errorf("upload %s not received: %v", name, ctx.Err())
+		}
+	}
+}
+
+// Close stops the receiver
+func (receiver *UploadReceiver) Close() {
+	receiver.server.Close()
+}
+
+func (receiver *UploadReceiver) handleUpload(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPut && r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	name := strings.TrimPrefix(r.URL.Path, uploadReceiverPathPrefix)
+	if name == "" {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	content, err := io.ReadAll(r.Body)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	receiver.mutex.Lock()
+	status := receiver.status
+	if status < http.StatusBadRequest {
+		checksum := sha256.Sum256(content)
+		receiver.uploads[name] = &ReceivedUpload{
+			Name:    name,
+			Method:  r.Method,
+			Header:  r.Header.Clone(),
+			Content: content,
+			SHA256:  hex.EncodeToString(checksum[:]),
+		}
+		close(receiver.changed)
+		receiver.changed = make(chan struct{})
+	}
+	receiver.mutex.Unlock()
+
+	w.WriteHeader(status)
+}
+
+// FileSHA256 returns the hex encoded SHA-256 checksum of a file, e.g. to verify the uploaded content
+func FileSHA256(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
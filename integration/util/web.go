@@ -14,6 +14,7 @@ package util
 
 import (
 	"bytes"
+	"context"
 	"encoding/base64"
 	"encoding/json"
 	"errors"
@@ -21,6 +22,7 @@ import (
 	"io"
 	"net/http"
 	"net/url"
+	"strconv"
 	"strings"
 	"time"
 
@@ -47,6 +49,21 @@ const (
 	featureMessageOutboxPathTemplate = "/features/%s/outbox/messages/%s"
 	featureMessageInboxPathTemplate  = "/features/%s/inbox/messages/%s"
 
+	headerRequestedAcks = "requested-acks"
+	headerDeclaredAcks  = "declared-acks"
+	headerWeakAck       = "ditto-weak-ack"
+
+	criterionAcks protocol.TopicCriterion = "acks"
+
+	// AckTwinPersisted is the built-in acknowledgement label issued when a twin modification is persisted.
+	AckTwinPersisted = "twin-persisted"
+
+	// AckLiveResponse is the built-in acknowledgement label issued when a live message or command is responded.
+	AckLiveResponse = "live-response"
+
+	// AckSearchPersisted is the built-in acknowledgement label issued when a twin modification is applied to the search index.
+	AckSearchPersisted = "search-persisted"
+
 	// StartSendEvents specifies that events should be received.
 	StartSendEvents SubscribeEventType = "START-SEND-EVENTS"
 
@@ -60,8 +77,71 @@ const (
 	StopSendMessages UnsubscribeEventType = "STOP-SEND-MESSAGES"
 )
 
-// SendDigitalTwinRequest sends a new HTTP request to the Ditto REST API
-func SendDigitalTwinRequest(cfg *TestConfiguration, method string, url string, body interface{}) ([]byte, error) {
+// RequestOption configures additional headers of a Ditto REST API request
+type RequestOption func(header http.Header)
+
+// WithRequestedAcks requests the given acknowledgement labels to be fulfilled before the response is sent
+func WithRequestedAcks(labels ...string) RequestOption {
+	return func(header http.Header) {
+		header.Set(headerRequestedAcks, strings.Join(labels, ","))
+	}
+}
+
+// WithTimeout sets how long Ditto waits for the response and the requested acknowledgements
+func WithTimeout(timeout time.Duration) RequestOption {
+	return func(header http.Header) {
+		header.Set(protocol.HeaderTimeout, formatTimeout(timeout))
+	}
+}
+
+// WithResponseRequired sets whether a response is required for the request
+func WithResponseRequired(required bool) RequestOption {
+	return func(header http.Header) {
+		header.Set(protocol.HeaderResponseRequired, strconv.FormatBool(required))
+	}
+}
+
+// WithCorrelationID sets the correlation ID of the request instead of a randomly generated one
+func WithCorrelationID(correlationID string) RequestOption {
+	return func(header http.Header) {
+		header.Set(protocol.HeaderCorrelationID, correlationID)
+	}
+}
+
+func formatTimeout(timeout time.Duration) string {
+	if timeout%time.Second == 0 {
+		return fmt.Sprintf("%ds", timeout/time.Second)
+	}
+	return fmt.Sprintf("%dms", timeout/time.Millisecond)
+}
+
+// Acknowledgement is a single acknowledgement as returned by the Ditto REST API
+type Acknowledgement struct {
+	Status  int                    `json:"status"`
+	Payload interface{}            `json:"payload,omitempty"`
+	Headers map[string]interface{} `json:"headers,omitempty"`
+}
+
+// IsWeak returns true if the acknowledgement was issued by Ditto on behalf of a subscriber, which did not receive the signal
+func (ack *Acknowledgement) IsWeak() bool {
+	weak, ok := ack.Headers[headerWeakAck].(bool)
+	return ok && weak
+}
+
+// ParseAcknowledgements parses the aggregated response of a request with more than one requested acknowledgement.
+// The result maps the acknowledgement labels to their acknowledgements.
+func ParseAcknowledgements(payload []byte) (map[string]*Acknowledgement, error) {
+	acks := map[string]*Acknowledgement{}
+	if err := json.Unmarshal(payload, &acks); err != nil {
+		return nil, err
+	}
+	return acks, nil
+}
+
+// SendDigitalTwinRequest sends a new HTTP request to the Ditto REST API.
+// In case of a failed request, the response body is returned together with the error.
+func SendDigitalTwinRequest(ctx context.Context, cfg *TestConfiguration, method string, url string,
+	body interface{}, opts ...RequestOption) ([]byte, error) {
 	var (
 		payload []byte
 		err     error
@@ -73,30 +153,35 @@ func SendDigitalTwinRequest(cfg *TestConfiguration, method string, url string, b
 		}
 	}
 
-	req, err := createRequest(payload, true, method, url, cfg.DigitalTwinAPIUsername, cfg.DigitalTwinAPIPassword)
+	req, err := createRequest(ctx, payload, true, method, url, cfg.DigitalTwinAPIUsername, cfg.DigitalTwinAPIPassword)
 	if err != nil {
 		return nil, err
 	}
+	for _, opt := range opts {
+		opt(req.Header)
+	}
 	return sendRequest(req, method, url)
 }
 
 // SendDeviceRegistryRequest sends a new HTTP request to the Ditto API
-func SendDeviceRegistryRequest(payload []byte, method string, url string, username string, password string) ([]byte, error) {
-	req, err := createRequest(payload, false, method, url, username, password)
+func SendDeviceRegistryRequest(ctx context.Context, payload []byte, method string, url string,
+	username string, password string) ([]byte, error) {
+	req, err := createRequest(ctx, payload, false, method, url, username, password)
 	if err != nil {
 		return nil, err
 	}
 	return sendRequest(req, method, url)
 }
 
-func createRequest(payload []byte, rspRequired bool, method, url, username, password string) (*http.Request, error) {
+func createRequest(ctx context.Context, payload []byte, rspRequired bool,
+	method, url, username, password string) (*http.Request, error) {
 	var reqBody io.Reader
 
 	if payload != nil {
 		reqBody = bytes.NewBuffer(payload)
 	}
 
-	req, err := http.NewRequest(method, url, reqBody)
+	req, err := http.NewRequestWithContext(ctx, method, url, reqBody)
 	if err != nil {
 		return nil, err
 	}
@@ -114,22 +199,49 @@ func createRequest(payload []byte, rspRequired bool, method, url, username, pass
 }
 
 func sendRequest(req *http.Request, method string, url string) ([]byte, error) {
+	recorder := TrafficRecorderFromContext(req.Context())
+	recorder.recordHTTPRequest(req)
+
 	resp, err := http.DefaultClient.Do(req)
 	if err != nil {
+		recorder.recordHTTPResponse(req, nil, nil, err)
 		return nil, err
 	}
 
 	defer resp.Body.Close()
 
+	body, err := io.ReadAll(resp.Body)
+	recorder.recordHTTPResponse(req, resp, body, err)
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return nil, fmt.Errorf("%s %s request failed: %s", method, url, resp.Status)
+		return body, &HTTPStatusError{Method: method, URL: url, Status: resp.Status, StatusCode: resp.StatusCode}
 	}
+	return body, err
+}
+
+// HTTPStatusError is returned for HTTP requests, which are responded with a status other than 2xx
+type HTTPStatusError struct {
+	Method     string
+	URL        string
+	Status     string
+	StatusCode int
+}
+
+func (err *HTTPStatusError) Error() string {
+	return fmt.Sprintf("%s %s request failed: %s", err.Method, err.URL, err.Status)
+}
 
-	return io.ReadAll(resp.Body)
+// IsHTTPStatus checks whether the error is caused by a response with the given HTTP status code
+func IsHTTPStatus(err error, statusCode int) bool {
+	var statusErr *HTTPStatusError
+	return errors.As(err, &statusErr) && statusErr.StatusCode == statusCode
 }
 
-// NewDigitalTwinWSConnection creates a new WebSocket connection
-func NewDigitalTwinWSConnection(cfg *TestConfiguration) (*websocket.Conn, error) {
+// NewDigitalTwinWSConnection creates a new WebSocket connection.
+// The given acknowledgement labels are declared for the session, i.e. the session is expected to send them
+// as strong acknowledgements. Ditto issues weak acknowledgements for the declared labels on signals, which are
+// not delivered to the session, e.g. because of a filter.
+func NewDigitalTwinWSConnection(ctx context.Context, cfg *TestConfiguration,
+	declaredAcks ...string) (*websocket.Conn, error) {
 	wsAddress, err := asWSAddress(cfg.DigitalTwinAPIAddress)
 	if err != nil {
 		return nil, err
@@ -146,8 +258,11 @@ func NewDigitalTwinWSConnection(cfg *TestConfiguration) (*websocket.Conn, error)
 	wsCfg.Header = http.Header{
 		"Authorization": {"Basic " + enc},
 	}
+	if len(declaredAcks) > 0 {
+		wsCfg.Header.Set(headerDeclaredAcks, strings.Join(declaredAcks, ","))
+	}
 
-	return websocket.DialConfig(wsCfg)
+	return wsCfg.DialContext(ctx)
 }
 
 func getPortOrDefault(url *url.URL, defaultPort string) string {
@@ -171,41 +286,83 @@ func asWSAddress(address string) (string, error) {
 	return fmt.Sprintf("ws://%s:%s", url.Hostname(), getPortOrDefault(url, "80")), nil
 }
 
-func sendMessageAndAwaitAck(cfg *TestConfiguration, conn *websocket.Conn, msg string, eventType string) error {
+func sendMessageAndAwaitAck(ctx context.Context, cfg *TestConfiguration, conn *websocket.Conn,
+	msg string, eventType string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
 	err := websocket.Message.Send(conn, msg)
+	TrafficRecorderFromContext(ctx).recordWS(TrafficOut, []byte(msg), err)
 	if err != nil {
 		return err
 	}
-	return WaitForWSMessage(cfg, conn, eventType+":ACK")
+	return WaitForWSMessage(ctx, cfg, conn, eventType+":ACK")
+}
+
+// setWSDeadline sets the deadline of the WebSocket connection to the earlier of the given timeout and
+// the context deadline. A zero timeout leaves only the context deadline, if any, and a zero deadline
+// is returned without one. The connection is also unblocked if the context is canceled, until the returned
+// function is called.
+func setWSDeadline(ctx context.Context, ws *websocket.Conn, timeout time.Duration) (time.Time, func(), error) {
+	var deadline time.Time
+	if timeout > 0 {
+		deadline = time.Now().Add(timeout)
+	}
+	if ctxDeadline, ok := ctx.Deadline(); ok && (deadline.IsZero() || ctxDeadline.Before(deadline)) {
+		deadline = ctxDeadline
+	}
+	if err := ws.SetDeadline(deadline); err != nil {
+		return deadline, nil, fmt.Errorf("unable to set deadline to websocket: %v", err)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		select {
+		case <-ctx.Done():
+			ws.SetDeadline(time.Now())
+		case <-done:
+		}
+	}()
+	return deadline, func() { close(done) }, nil
 }
 
 // SubscribeForWSMessages subscribes for the messages that are sent from a WebSocket session and awaits confirmation response.
-func SubscribeForWSMessages(cfg *TestConfiguration, conn *websocket.Conn, eventType SubscribeEventType, filter string) error {
+func SubscribeForWSMessages(ctx context.Context, cfg *TestConfiguration, conn *websocket.Conn,
+	eventType SubscribeEventType, filter string) error {
 	var msg string
 	if len(filter) > 0 {
 		msg = fmt.Sprintf("%s?filter=%s", eventType, filter)
 	} else {
 		msg = string(eventType)
 	}
-	return sendMessageAndAwaitAck(cfg, conn, msg, string(eventType))
+	return sendMessageAndAwaitAck(ctx, cfg, conn, msg, string(eventType))
 }
 
 // UnsubscribeFromWSMessages unsubscribes from the messages that are sent from a WebSocket session
 // and awaits confirmation response.
-func UnsubscribeFromWSMessages(cfg *TestConfiguration, ws *websocket.Conn, eventType UnsubscribeEventType) error {
-	return sendMessageAndAwaitAck(cfg, ws, string(eventType), string(eventType))
+func UnsubscribeFromWSMessages(ctx context.Context, cfg *TestConfiguration, ws *websocket.Conn,
+	eventType UnsubscribeEventType) error {
+	return sendMessageAndAwaitAck(ctx, cfg, ws, string(eventType), string(eventType))
 }
 
 // WaitForWSMessage waits for received a specific message from a WebSocket session or timeout expires
-func WaitForWSMessage(cfg *TestConfiguration, ws *websocket.Conn, expectedMessage string) error {
-	deadline := time.Now().Add(MillisToDuration(cfg.WSEventTimeoutMS))
-	if err := ws.SetDeadline(deadline); err != nil {
-		return fmt.Errorf("unable to set deadline to websocket: %v", err)
+// or the context is done
+func WaitForWSMessage(ctx context.Context, cfg *TestConfiguration, ws *websocket.Conn, expectedMessage string) error {
+	deadline, stop, err := setWSDeadline(ctx, ws, MillisToDuration(cfg.WSEventTimeoutMS))
+	if err != nil {
+		return err
 	}
+	defer stop()
+
+	recorder := TrafficRecorderFromContext(ctx)
 	var payload []byte
 	for time.Now().Before(deadline) {
 		err := websocket.Message.Receive(ws, &payload)
+		recorder.recordWS(TrafficIn, payload, err)
 		if err != nil {
+			if ctx.Err() != nil {
+				return fmt.Errorf("error reading from websocket: %v", ctx.Err())
+			}
 			return fmt.Errorf("error reading from websocket: %v", err)
 		}
 		message := strings.TrimSpace(string(payload))
@@ -218,20 +375,38 @@ func WaitForWSMessage(cfg *TestConfiguration, ws *websocket.Conn, expectedMessag
 }
 
 // ProcessWSMessages processes messages for the satisfied condition from the WebSocket session or timeout expires
-func ProcessWSMessages(cfg *TestConfiguration, ws *websocket.Conn, process func(*protocol.Envelope) (bool, error)) error {
-	timeout := MillisToDuration(cfg.WSEventTimeoutMS)
-	deadline := time.Now().Add(timeout)
-	if err := ws.SetDeadline(deadline); err != nil {
-		return fmt.Errorf("unable to set deadline to websocket: %v", err)
+// or the context is done
+func ProcessWSMessages(ctx context.Context, cfg *TestConfiguration, ws *websocket.Conn,
+	process func(*protocol.Envelope) (bool, error)) error {
+	return processWSMessages(ctx, ws, MillisToDuration(cfg.WSEventTimeoutMS), process)
+}
+
+// WatchWSMessages processes messages for the satisfied condition from the WebSocket session until the context
+// is done. Unlike ProcessWSMessages, the WebSocket event timeout does not apply, so long running operations,
+// e.g. software updates, are only bounded by the context.
+func WatchWSMessages(ctx context.Context, ws *websocket.Conn, process func(*protocol.Envelope) (bool, error)) error {
+	return processWSMessages(ctx, ws, 0, process)
+}
+
+func processWSMessages(ctx context.Context, ws *websocket.Conn, timeout time.Duration,
+	process func(*protocol.Envelope) (bool, error)) error {
+	deadline, stop, err := setWSDeadline(ctx, ws, timeout)
+	if err != nil {
+		return err
 	}
+	defer stop()
 
-	var err error
+	recorder := TrafficRecorderFromContext(ctx)
 	finished := false
 
-	for !finished && time.Now().Before(deadline) {
+	for !finished && (deadline.IsZero() || time.Now().Before(deadline)) {
 		var payload []byte
 		wsErr := websocket.Message.Receive(ws, &payload)
+		recorder.recordWS(TrafficIn, payload, wsErr)
 		if wsErr != nil {
+			if ctx.Err() != nil {
+				return fmt.Errorf("error reading from websocket: %v, last error: %v", ctx.Err(), err)
+			}
 			return fmt.Errorf("error reading from websocket: %v", wsErr)
 		}
 
@@ -251,16 +426,63 @@ func ProcessWSMessages(cfg *TestConfiguration, ws *websocket.Conn, process func(
 	return err
 }
 
+// SendAcknowledgement sends an acknowledgement with the given label for a signal received from a WebSocket session.
+// The acknowledgement is correlated to the signal and sent on the signal's channel.
+func SendAcknowledgement(ctx context.Context, ws *websocket.Conn, signal *protocol.Envelope, label string,
+	status int, payload interface{}) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if signal.Topic == nil {
+		return errors.New("cannot acknowledge a signal without a topic")
+	}
+	correlationID := ""
+	if signal.Headers != nil {
+		correlationID = signal.Headers.CorrelationID()
+	}
+	topic := (&protocol.Topic{}).
+		WithNamespace(signal.Topic.Namespace).
+		WithEntityName(signal.Topic.EntityName).
+		WithGroup(protocol.GroupThings).
+		WithChannel(signal.Topic.Channel).
+		WithCriterion(criterionAcks).
+		WithAction(protocol.TopicAction(label))
+	ack := (&protocol.Envelope{}).
+		WithTopic(topic).
+		WithHeaders(protocol.NewHeaders(protocol.WithCorrelationID(correlationID))).
+		WithPath("/").
+		WithValue(payload).
+		WithStatus(status)
+	data, err := json.Marshal(ack)
+	if err != nil {
+		return err
+	}
+	err = websocket.Message.Send(ws, string(data))
+	TrafficRecorderFromContext(ctx).recordWS(TrafficOut, data, err)
+	return err
+}
+
+// IsWeakAcknowledgement returns true if the envelope is a weak acknowledgement issued by Ditto
+func IsWeakAcknowledgement(envelope *protocol.Envelope) bool {
+	if envelope.Topic == nil || envelope.Topic.Criterion != criterionAcks || envelope.Headers == nil {
+		return false
+	}
+	weak, ok := envelope.Headers.Generic(headerWeakAck).(bool)
+	return ok && weak
+}
+
 // ExecuteOperation executes an operation of a feature
-func ExecuteOperation(cfg *TestConfiguration, featureURL string, operation string, params interface{}) ([]byte, error) {
+func ExecuteOperation(ctx context.Context, cfg *TestConfiguration, featureURL string, operation string,
+	params interface{}, opts ...RequestOption) ([]byte, error) {
 	url := fmt.Sprintf(featureOperationURLTemplate, featureURL, operation)
-	return SendDigitalTwinRequest(cfg, http.MethodPost, url, params)
+	return SendDigitalTwinRequest(ctx, cfg, http.MethodPost, url, params, opts...)
 }
 
 // GetFeaturePropertyValue gets the value of a feature's property
-func GetFeaturePropertyValue(cfg *TestConfiguration, featureURL string, property string) ([]byte, error) {
+func GetFeaturePropertyValue(ctx context.Context, cfg *TestConfiguration, featureURL string,
+	property string) ([]byte, error) {
 	url := fmt.Sprintf(featurePropertyURLTemplate, featureURL, property)
-	return SendDigitalTwinRequest(cfg, http.MethodGet, url, nil)
+	return SendDigitalTwinRequest(ctx, cfg, http.MethodGet, url, nil)
 }
 
 // GetThingURL returns the url of a thing
@@ -24,8 +24,10 @@ export DEVICE_ID=
 export AUTH_ID=
 # A password for the device to authenticate with
 export PWD=
+# The northbound messaging type of the tenant, either amqp or kafka
+export MESSAGING_TYPE=amqp
 
-curl -i -X POST $HONO_EP/v1/tenants/$TENANT -H 'accept: application/json' -H  'Content-Type: application/json'  --data-binary '{"ext": {"messaging-type": "amqp"}}'
+curl -i -X POST $HONO_EP/v1/tenants/$TENANT -H 'accept: application/json' -H  'Content-Type: application/json'  --data-binary '{"ext": {"messaging-type": "'$MESSAGING_TYPE'"}}'
 curl -i -X POST $HONO_EP/v1/devices/$TENANT/$DEVICE_ID -H  'Content-Type: application/json' --data-binary '{"authorities":["auto-provisioning-enabled"]}'
 curl -i -X PUT -H 'Content-Type: application/json' --data-binary '[{
   "type": "hashed-password",